@@ -7,6 +7,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// AchievementTypes jenis prestasi yang valid, dipakai validasi request dan validator koleksi Mongo
+var AchievementTypes = []string{"academic", "competition", "organization", "publication", "certification", "other"}
+
 type Achievement struct {
 	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	StudentID       string        `bson:"studentId" json:"student_id"`
@@ -22,6 +25,8 @@ type Achievement struct {
 
 	CreatedAt time.Time `bson:"createdAt" json:"created_at"`
 	UpdatedAt time.Time `bson:"updatedAt" json:"updated_at"`
+	// DeletedAt diisi saat reference di-soft-delete, dokumen tetap disimpan untuk riwayat
+	DeletedAt *time.Time `bson:"deletedAt,omitempty" json:"deleted_at,omitempty"`
 }
 
 type CreateAchievementRequest struct {
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Urutan daftar prestasi. Tiga pertama kolom achievement_references (Postgres),
+// sisanya field dokumen achievements (Mongo).
+const (
+	AchievementSortCreatedAt   = "created_at"
+	AchievementSortSubmittedAt = "submitted_at"
+	AchievementSortVerifiedAt  = "verified_at"
+	AchievementSortPoints      = "points"
+	AchievementSortEventDate   = "event_date"
+	AchievementSortTitle       = "title"
+	// AchievementSortRelevance skor text search, hanya berlaku jika Query diisi
+	AchievementSortRelevance = "relevance"
+)
+
+var ErrInvalidAchievementSort = errors.New("invalid sort field")
+
+// MaxAchievementFilterIDs jumlah ID maksimum yang dikirim dari satu store ke store lain dalam
+// satu query ($in di Mongo, ANY di Postgres); hasil yang lebih besar diproses per batch
+const MaxAchievementFilterIDs = 5000
+
+var referenceSorts = map[string]bool{
+	AchievementSortCreatedAt:   true,
+	AchievementSortSubmittedAt: true,
+	AchievementSortVerifiedAt:  true,
+}
+
+var documentSorts = map[string]bool{
+	AchievementSortPoints:    true,
+	AchievementSortEventDate: true,
+	AchievementSortTitle:     true,
+	AchievementSortRelevance: true,
+}
+
+// AchievementListFilter filter GET /achievements. Sebagian field ada di Postgres
+// (status, program studi, angkatan) dan sebagian di Mongo (sisanya); service memilih
+// store mana yang memimpin query berdasarkan HasDocumentFilter dan IsDocumentSort.
+type AchievementListFilter struct {
+	// Postgres
+	Status       string
+	ProgramStudy string
+	AcademicYear string
+
+	// Mongo
+	Types            []string
+	Tags             []string // semua tag harus ada
+	CompetitionLevel string
+	EventFrom        *time.Time
+	EventTo          *time.Time // eksklusif
+	MinPoints        *int
+	MaxPoints        *int
+	Query            string // full-text atas title, description dan details
+
+	Sort      string
+	Ascending bool
+}
+
+// Validate mengisi sort default dan menolak sort yang tidak dikenal
+func (f *AchievementListFilter) Validate() error {
+	if f.Sort == "" {
+		f.Sort = AchievementSortCreatedAt
+		if f.Query != "" {
+			f.Sort = AchievementSortRelevance
+		}
+	}
+	if !referenceSorts[f.Sort] && !documentSorts[f.Sort] {
+		return ErrInvalidAchievementSort
+	}
+	if f.Sort == AchievementSortRelevance && f.Query == "" {
+		return errors.New("sort=relevance requires q")
+	}
+	return nil
+}
+
+// HasDocumentFilter true jika ada filter yang hanya bisa dievaluasi di Mongo
+func (f AchievementListFilter) HasDocumentFilter() bool {
+	return len(f.Types) > 0 || len(f.Tags) > 0 || f.CompetitionLevel != "" ||
+		f.EventFrom != nil || f.EventTo != nil || f.MinPoints != nil || f.MaxPoints != nil ||
+		f.Query != ""
+}
+
+// IsDocumentSort true jika urutan ditentukan field dokumen Mongo
+func (f AchievementListFilter) IsDocumentSort() bool {
+	return documentSorts[f.Sort]
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AchievementStatusHistory struct {
+	ID               uuid.UUID  `json:"id"`
+	AchievementRefID uuid.UUID  `json:"achievement_ref_id"`
+	FromStatus       *string    `json:"from_status"`
+	ToStatus         string     `json:"to_status"`
+	ActorID          *uuid.UUID `json:"actor_id"`
+	Note             *string    `json:"note"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
@@ -24,6 +24,7 @@ type AchievementReference struct {
 	VerifiedAt         *time.Time `json:"verified_at"`
 	VerifiedBy         *uuid.UUID `json:"verified_by"`
 	RejectionNote      *string    `json:"rejection_note"`
+	ResubmissionCount  int        `json:"resubmission_count"`
 	CreatedAt          time.Time  `json:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at"`
 }
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+type AchievementAction string
+
+const (
+	ActionSubmit AchievementAction = "submit"
+	ActionVerify AchievementAction = "verify"
+	ActionReject AchievementAction = "reject"
+	ActionRevise AchievementAction = "revise"
+	ActionDelete AchievementAction = "delete"
+	ActionEdit   AchievementAction = "edit"
+	ActionAttach AchievementAction = "attach"
+)
+
+var (
+	// ErrInvalidTransition: status saat ini tidak mengizinkan action
+	ErrInvalidTransition = errors.New("invalid achievement status transition")
+	// ErrTransitionForbidden: actor tidak punya relasi yang diizinkan untuk action
+	ErrTransitionForbidden = errors.New("not allowed to perform this transition")
+	// ErrStatusConflict: status berubah di antara pengecekan dan UPDATE (race)
+	ErrStatusConflict = errors.New("achievement status changed concurrently")
+)
+
+// AchievementTransition adalah satu baris tabel transisi status prestasi.
+// Action edit/attach tidak mengubah status (From == To) tapi tetap dijaga di sini.
+type AchievementTransition struct {
+	Action AchievementAction
+	From   AchievementStatus
+	To     AchievementStatus
+	// Relations relasi actor terhadap mahasiswa pemilik yang boleh menjalankan transisi
+	Relations []Relation
+}
+
+var achievementTransitions = map[AchievementAction]AchievementTransition{
+	ActionSubmit: {ActionSubmit, StatusDraft, StatusSubmitted, []Relation{RelationOwner, RelationGlobal}},
+	ActionVerify: {ActionVerify, StatusSubmitted, StatusVerified, []Relation{RelationAdvisor, RelationGlobal}},
+	ActionReject: {ActionReject, StatusSubmitted, StatusRejected, []Relation{RelationAdvisor, RelationGlobal}},
+	ActionRevise: {ActionRevise, StatusRejected, StatusDraft, []Relation{RelationOwner, RelationGlobal}},
+	ActionDelete: {ActionDelete, StatusDraft, StatusDeleted, []Relation{RelationOwner, RelationGlobal}},
+	ActionEdit:   {ActionEdit, StatusDraft, StatusDraft, []Relation{RelationOwner, RelationGlobal}},
+	ActionAttach: {ActionAttach, StatusDraft, StatusDraft, []Relation{RelationOwner, RelationGlobal}},
+}
+
+// GetAchievementTransition mengambil definisi transisi untuk action tertentu
+func GetAchievementTransition(action AchievementAction) (AchievementTransition, error) {
+	t, ok := achievementTransitions[action]
+	if !ok {
+		return AchievementTransition{}, fmt.Errorf("%w: unknown action %q", ErrInvalidTransition, action)
+	}
+	return t, nil
+}
+
+// Guard memastikan actor (dengan relasi yang dimilikinya) boleh menjalankan transisi dari status saat ini
+func (t AchievementTransition) Guard(relations []Relation, current string) error {
+	if !HasAnyRelation(t.Relations, relations) {
+		return fmt.Errorf("%w: you cannot %s this achievement", ErrTransitionForbidden, t.Action)
+	}
+
+	if AchievementStatus(current) != t.From {
+		return fmt.Errorf("%w: cannot %s an achievement in %s status (expected %s)", ErrInvalidTransition, t.Action, current, t.From)
+	}
+
+	return nil
+}
+
+// GuardAchievementTransition adalah shortcut lookup + Guard yang dipakai handler
+func GuardAchievementTransition(relations []Relation, current string, action AchievementAction) (AchievementTransition, error) {
+	t, err := GetAchievementTransition(action)
+	if err != nil {
+		return t, err
+	}
+	return t, t.Guard(relations, current)
+}
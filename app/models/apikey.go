@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix penanda key: "ak_<prefix>_<secret>"
+const APIKeyPrefix = "ak_"
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+type APIKey struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"userId" db:"user_id"`
+	Name        string     `json:"name" db:"name"`
+	Prefix      string     `json:"prefix" db:"prefix"`
+	KeyHash     string     `json:"-" db:"key_hash"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty" db:"expires_at"`
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty" db:"last_used_at"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+}
+
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+type CreateAPIKeyRequest struct {
+	Name        string     `json:"name"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+// CreateAPIKeyResponse Key plaintext hanya dikembalikan sekali saat dibuat
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
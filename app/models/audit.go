@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionImpersonationStart  = "impersonation.start"
+	AuditActionImpersonationStop   = "impersonation.stop"
+	AuditActionImpersonatedRequest = "impersonation.request"
+
+	AuditActionUserCreate         = "user.create"
+	AuditActionUserUpdate         = "user.update"
+	AuditActionUserDelete         = "user.delete"
+	AuditActionUserPasswordSet    = "user.password_set"
+	AuditActionUserRoleUpdate     = "user.role_update"
+	AuditActionUserSessionsRevoke = "user.sessions_revoke"
+	AuditActionUserUnlock         = "user.unlock"
+
+	AuditActionRoleCreate           = "role.create"
+	AuditActionRoleDelete           = "role.delete"
+	AuditActionRoleTwoFactorUpdate  = "role.two_factor_update"
+	AuditActionRolePermissionAttach = "role.permission_attach"
+	AuditActionRolePermissionDetach = "role.permission_detach"
+	AuditActionPermissionCreate     = "permission.create"
+	AuditActionPermissionDelete     = "permission.delete"
+
+	AuditActionStudentAdvisorUpdate = "student.advisor_update"
+
+	AuditActionAchievementVerify = "achievement.verify"
+	AuditActionAchievementReject = "achievement.reject"
+)
+
+// Jenis target audit (kolom target_type)
+const (
+	AuditTargetUser        = "user"
+	AuditTargetStudent     = "student"
+	AuditTargetAchievement = "achievement"
+	AuditTargetRole        = "role"
+	AuditTargetPermission  = "permission"
+)
+
+// AuditLog satu entri jejak audit. ActorID user yang tercatat melakukan aksi;
+// ImpersonatorID terisi jika aksi dilakukan admin yang sedang impersonate ActorID.
+type AuditLog struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	ActorID        *uuid.UUID      `json:"actorId,omitempty" db:"actor_id"`
+	ImpersonatorID *uuid.UUID      `json:"impersonatorId,omitempty" db:"impersonator_id"`
+	Action         string          `json:"action" db:"action"`
+	TargetType     string          `json:"targetType,omitempty" db:"target_type"`
+	TargetID       string          `json:"targetId,omitempty" db:"target_id"`
+	Reason         string          `json:"reason,omitempty" db:"reason"`
+	Before         json.RawMessage `json:"before,omitempty" db:"before_data"`
+	After          json.RawMessage `json:"after,omitempty" db:"after_data"`
+	SessionID      *uuid.UUID      `json:"sessionId,omitempty" db:"session_id"`
+	Method         string          `json:"method,omitempty" db:"method"`
+	Path           string          `json:"path,omitempty" db:"path"`
+	StatusCode     int             `json:"statusCode,omitempty" db:"status_code"`
+	IPAddress      string          `json:"ipAddress,omitempty" db:"ip_address"`
+	UserAgent      string          `json:"userAgent,omitempty" db:"user_agent"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
+}
+
+// AuditLogFilter filter GET /audit-logs; field kosong / nil berarti tidak difilter
+type AuditLogFilter struct {
+	ActorID        *uuid.UUID
+	ImpersonatorID *uuid.UUID
+	Action         string
+	TargetType     string
+	TargetID       string
+	From           *time.Time
+	To             *time.Time
+	Page           int
+	Limit          int
+}
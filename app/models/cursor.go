@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor posisi pagination keyset: nilai kolom urutan (Key) dan id baris batas halaman.
+// Client hanya melihatnya sebagai string opaque (next_cursor / prev_cursor).
+type Cursor struct {
+	// Sort daftar dan urutan asal cursor, supaya cursor tidak dipakai di urutan lain
+	Sort string    `json:"s"`
+	Key  string    `json:"k"`
+	ID   uuid.UUID `json:"id"`
+	// Backward true untuk prev_cursor: baris sebelum posisi ini
+	Backward bool `json:"b,omitempty"`
+}
+
+// CursorPage posisi halaman hasil query cursor; Next/Prev nil jika tidak ada halaman lagi
+type CursorPage struct {
+	Next *Cursor
+	Prev *Cursor
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrImpersonateSelf  = errors.New("cannot impersonate yourself")
+	ErrImpersonateAdmin = errors.New("cannot impersonate a user who can manage users")
+	ErrNotImpersonating = errors.New("this session is not an impersonation session")
+)
+
+// ActorClaim klaim "act" (RFC 8693): identitas admin asli di balik token impersonation
+type ActorClaim struct {
+	Subject  string `json:"sub"`
+	Username string `json:"username,omitempty"`
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ImpersonateResponse struct {
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	SessionID    uuid.UUID `json:"sessionId"`
+	User         User      `json:"user"`
+	Impersonator User      `json:"impersonator"`
+}
+
+// ImpersonationInfo penanda di /auth/profile selama sesi impersonation
+type ImpersonationInfo struct {
+	Active       bool      `json:"active"`
+	Impersonator User      `json:"impersonator"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	LockoutEventLocked   = "locked"
+	LockoutEventUnlocked = "unlocked"
+)
+
+type LoginAttempt struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Identifier string     `json:"identifier" db:"identifier"`
+	UserID     *uuid.UUID `json:"userId,omitempty" db:"user_id"`
+	IPAddress  string     `json:"ipAddress" db:"ip_address"`
+	Succeeded  bool       `json:"succeeded" db:"succeeded"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+}
+
+type AccountLockout struct {
+	UserID       uuid.UUID  `json:"userId" db:"user_id"`
+	FailedCount  int        `json:"failedCount" db:"failed_count"`
+	LastFailedAt *time.Time `json:"lastFailedAt,omitempty" db:"last_failed_at"`
+	LockedUntil  *time.Time `json:"lockedUntil,omitempty" db:"locked_until"`
+	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// IsLocked true jika akun masih dalam masa kunci
+func (l *AccountLockout) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && now.Before(*l.LockedUntil)
+}
+
+type LockoutEvent struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"userId" db:"user_id"`
+	Event     string     `json:"event" db:"event"`
+	IPAddress string     `json:"ipAddress,omitempty" db:"ip_address"`
+	ActorID   *uuid.UUID `json:"actorId,omitempty" db:"actor_id"`
+	Reason    string     `json:"reason,omitempty" db:"reason"`
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+}
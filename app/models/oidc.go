@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrOIDCNotConfigured    = errors.New("single sign-on is not configured")
+	ErrOIDCInvalidState     = errors.New("login request is invalid or has expired")
+	ErrOIDCAccountNotFound  = errors.New("no account is linked to this identity")
+	ErrOIDCEmailNotVerified = errors.New("identity provider has not verified this email address")
+)
+
+// OIDCLoginState login yang sedang berjalan di IdP; hanya hash state yang disimpan
+type OIDCLoginState struct {
+	StateHash    string    `db:"state_hash"`
+	CodeVerifier string    `db:"code_verifier"`
+	Nonce        string    `db:"nonce"`
+	ExpiresAt    time.Time `db:"expires_at"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// IdentityIssuerLDAP issuer user_identities untuk akun LDAP; subject berisi DN (huruf kecil)
+const IdentityIssuerLDAP = "ldap"
+
+// UserIdentity akun IdP (issuer + subject) yang terhubung ke user lokal
+type UserIdentity struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"userId" db:"user_id"`
+	Issuer      string     `json:"issuer" db:"issuer"`
+	Subject     string     `json:"subject" db:"subject"`
+	Email       string     `json:"email" db:"email"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	LastLoginAt *time.Time `json:"lastLoginAt,omitempty" db:"last_login_at"`
+}
+
+// OIDCProfile identitas dari ID token yang sudah diverifikasi (dilengkapi userinfo jika perlu)
+type OIDCProfile struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Claims            map[string]interface{}
+}
+
+// StringClaim nilai claim sebagai string; kosong jika tidak ada
+func (p *OIDCProfile) StringClaim(name string) string {
+	switch v := p.Claims[name].(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	}
+	return ""
+}
+
+type OIDCLoginURLResponse struct {
+	AuthorizationURL string    `json:"authorizationUrl"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+}
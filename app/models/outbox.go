@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operasi Mongo yang dicatat di achievement_outbox
+const (
+	OutboxOperationCreate = "create"
+	OutboxOperationUpdate = "update"
+	OutboxOperationDelete = "delete"
+)
+
+// Status baris outbox: pending/compensating masih diproses worker, sisanya final
+const (
+	OutboxStatusPending      = "pending"
+	OutboxStatusCompensating = "compensating"
+	OutboxStatusDone         = "done"
+	OutboxStatusCompensated  = "compensated"
+	OutboxStatusFailed       = "failed"
+)
+
+// AchievementOutbox satu operasi Mongo yang harus menyusul perubahan di Postgres.
+// Payload dokumen achievement dalam Extended JSON supaya tipe BSON tidak berubah.
+type AchievementOutbox struct {
+	ID                 uuid.UUID       `json:"id"`
+	Operation          string          `json:"operation"`
+	ReferenceID        uuid.UUID       `json:"reference_id"`
+	MongoAchievementID string          `json:"mongo_achievement_id"`
+	Payload            json.RawMessage `json:"payload,omitempty"`
+	Status             string          `json:"status"`
+	Attempts           int             `json:"attempts"`
+	LastError          *string         `json:"last_error,omitempty"`
+	NextAttemptAt      time.Time       `json:"next_attempt_at"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+	CompletedAt        *time.Time      `json:"completed_at,omitempty"`
+}
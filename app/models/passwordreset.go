@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PasswordResetToken struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"userId" db:"user_id"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	RequestedIP string     `json:"requestedIp,omitempty" db:"requested_ip"`
+	ExpiresAt   time.Time  `json:"expiresAt" db:"expires_at"`
+	UsedAt      *time.Time `json:"usedAt,omitempty" db:"used_at"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token           string `json:"token"`
+	NewPassword     string `json:"newPassword"`
+	ConfirmPassword string `json:"confirmPassword"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+	ConfirmPassword string `json:"confirmPassword"`
+}
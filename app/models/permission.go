@@ -10,4 +10,21 @@ type Permission struct {
 	Resource    string    `json:"resource" db:"resource"`
 	Action      string    `json:"action" db:"action"`
 	Description string    `json:"description" db:"description"`
-}
\ No newline at end of file
+}
+const (
+	// PermissionUserManage permission untuk mengelola user, role dan permission
+	PermissionUserManage = "user:manage"
+	// PermissionAchievementManage akses ke prestasi semua mahasiswa (tanpa relasi pemilik/dosen wali)
+	PermissionAchievementManage = "achievement:manage"
+	// Permission baca; relasi pemilik / dosen wali tetap dicek di service
+	PermissionStudentRead  = "student:read"
+	PermissionLecturerRead = "lecturer:read"
+	PermissionReportRead   = "report:read"
+)
+
+// CreatePermissionRequest: nama permission dibentuk dari resource:action
+type CreatePermissionRequest struct {
+	Resource    string `json:"resource"`
+	Action      string `json:"action"`
+	Description string `json:"description"`
+}
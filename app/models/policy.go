@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// Relation hubungan actor dengan mahasiswa pemilik data.
+// Policy ditulis dalam relasi, bukan nama role, supaya role baru (mis. Kaprodi)
+// cukup diberi permission / profil dosen tanpa mengubah kode.
+type Relation string
+
+const (
+	// RelationOwner: actor adalah mahasiswa itu sendiri
+	RelationOwner Relation = "owner"
+	// RelationAdvisor: actor adalah dosen wali mahasiswa tersebut
+	RelationAdvisor Relation = "advisor"
+	// RelationGlobal: actor punya permission untuk semua mahasiswa
+	RelationGlobal Relation = "global"
+)
+
+const (
+	ActionCreate AchievementAction = "create"
+	ActionRead   AchievementAction = "read"
+)
+
+var ErrNoAchievementScope = errors.New("actor has no access to any achievements")
+
+// achievementAccess relasi yang boleh menjalankan action non-transisi
+var achievementAccess = map[AchievementAction][]Relation{
+	ActionCreate: {RelationOwner, RelationGlobal},
+	ActionRead:   {RelationOwner, RelationAdvisor, RelationGlobal},
+}
+
+// AchievementActionRelations relasi yang diizinkan untuk sebuah action (termasuk transisi status)
+func AchievementActionRelations(action AchievementAction) []Relation {
+	if t, ok := achievementTransitions[action]; ok {
+		return t.Relations
+	}
+	return achievementAccess[action]
+}
+
+// HasAnyRelation true jika salah satu relasi actor termasuk yang diizinkan
+func HasAnyRelation(allowed, have []Relation) bool {
+	for _, a := range allowed {
+		for _, h := range have {
+			if a == h {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AchievementScope cakupan daftar prestasi / statistik yang boleh dilihat actor.
+// ID berisi lecturer ID untuk RelationAdvisor dan student ID untuk RelationOwner.
+type AchievementScope struct {
+	Relation Relation
+	ID       uuid.UUID
+}
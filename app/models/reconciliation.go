@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrReconciliationRunning rekonsiliasi lain (instance / command lain) masih berjalan
+var ErrReconciliationRunning = errors.New("another reconciliation is already running")
+
+// Jenis ketidaksesuaian antara achievement_references dan koleksi achievements
+const (
+	ReconcileIssueMissingDocument = "missing_document"
+	ReconcileIssueInvalidMongoID  = "invalid_mongo_id"
+	ReconcileIssueOrphanDocument  = "orphan_document"
+	ReconcileIssueStudentMismatch = "student_mismatch"
+)
+
+// Tindakan yang diambil untuk satu issue; kosong berarti hanya dilaporkan
+const (
+	ReconcileActionRepaired    = "repaired"
+	ReconcileActionQuarantined = "quarantined"
+	ReconcileActionFailed      = "failed"
+)
+
+// Sumber rekonsiliasi
+const (
+	ReconcileTriggerCommand   = "command"
+	ReconcileTriggerScheduled = "scheduled"
+)
+
+// ReconcileOptions tanpa Repair/Quarantine rekonsiliasi hanya membuat laporan (dry run).
+//   - Repair: studentId dokumen Mongo disamakan dengan reference (Postgres sumber kebenaran)
+//   - Quarantine: reference tanpa dokumen di-soft-delete, dokumen tanpa reference
+//     dipindah ke koleksi achievements_quarantine
+type ReconcileOptions struct {
+	Repair     bool
+	Quarantine bool
+	Trigger    string
+}
+
+type ReconciliationIssue struct {
+	Type               string     `json:"type"`
+	ReferenceID        *uuid.UUID `json:"reference_id,omitempty"`
+	ReferenceStatus    string     `json:"reference_status,omitempty"`
+	ReferenceStudentID *uuid.UUID `json:"reference_student_id,omitempty"`
+	MongoAchievementID string     `json:"mongo_achievement_id"`
+	DocumentStudentID  string     `json:"document_student_id,omitempty"`
+	Action             string     `json:"action,omitempty"`
+	Error              string     `json:"error,omitempty"`
+}
+
+type ReconciliationReport struct {
+	ID                uuid.UUID             `json:"id"`
+	Trigger           string                `json:"trigger"`
+	Repair            bool                  `json:"repair"`
+	Quarantine        bool                  `json:"quarantine"`
+	ReferencesScanned int                   `json:"references_scanned"`
+	DocumentsScanned  int                   `json:"documents_scanned"`
+	IssueCount        int                   `json:"issue_count"`
+	Counts            map[string]int        `json:"counts"` // per jenis issue dan per tindakan (repaired, quarantined, failed)
+	Issues            []ReconciliationIssue `json:"issues,omitempty"`
+	Error             *string               `json:"error,omitempty"`
+	StartedAt         time.Time             `json:"started_at"`
+	FinishedAt        *time.Time            `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshToken struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"userId" db:"user_id"`
+	FamilyID   uuid.UUID  `json:"familyId" db:"family_id"`
+	ParentID   *uuid.UUID `json:"parentId,omitempty" db:"parent_id"`
+	DeviceInfo string     `json:"deviceInfo" db:"device_info"`
+	IPAddress  string     `json:"ipAddress" db:"ip_address"`
+	ExpiresAt  time.Time  `json:"expiresAt" db:"expires_at"`
+	UsedAt     *time.Time `json:"usedAt,omitempty" db:"used_at"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// IsActive: belum dirotasi, belum di-revoke dan belum kedaluwarsa
+func (t *RefreshToken) IsActive() bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && time.Now().Before(t.ExpiresAt)
+}
@@ -1,19 +1,60 @@
 package models
 
 import (
+	"errors"
 	"time"
 	"github.com/google/uuid"
 )
 
 
 type Role struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"` 
-	Description string    `json:"description" db:"description"`
-	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+	ID               uuid.UUID `json:"id" db:"id"`
+	Name             string    `json:"name" db:"name"` 
+	Description      string    `json:"description" db:"description"`
+	RequireTwoFactor bool      `json:"requireTwoFactor" db:"require_two_factor"`
+	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
 }
 
 type RolePermission struct {
 	RoleID       uuid.UUID `json:"roleId" db:"role_id"`           
 	PermissionID uuid.UUID `json:"permissionId" db:"permission_id"` 
 }
+
+type CreateRoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UpdateRoleTwoFactorRequest struct {
+	Required bool `json:"required"`
+}
+
+type RoleWithPermissions struct {
+	Role
+	Permissions []Permission `json:"permissions"`
+}
+
+// Role bawaan yang namanya dipakai langsung di kode, tidak boleh dihapus
+var SystemRoles = []string{"Admin", "Mahasiswa", "Dosen Wali"}
+
+func IsSystemRole(name string) bool {
+	for _, r := range SystemRoles {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrRoleNotFound              = errors.New("role not found")
+	ErrRoleInUse                 = errors.New("role is still assigned to users")
+	ErrSystemRole                = errors.New("system role cannot be deleted")
+	ErrPermissionNotFound        = errors.New("permission not found")
+	ErrPermissionInUse           = errors.New("permission is still assigned to roles")
+	ErrPermissionAlreadyAssigned = errors.New("permission already assigned to role")
+	ErrPermissionNotAssigned     = errors.New("permission not found for this role")
+	// ErrLastUserManager: tidak boleh ada kondisi tanpa satu pun role pemegang user:manage
+	// yang punya user aktif
+	ErrLastUserManager = errors.New("at least one role with an active user must keep the user:manage permission")
+)
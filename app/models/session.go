@@ -0,0 +1,30 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session satu login aktif (satu refresh token family)
+type Session struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"userId" db:"user_id"`
+	UserAgent  string     `json:"userAgent" db:"user_agent"`
+	IPAddress  string     `json:"ipAddress" db:"ip_address"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	LastSeenAt time.Time  `json:"lastSeenAt" db:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expiresAt" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
+	// ImpersonatorID admin yang membuat sesi ini lewat impersonation
+	ImpersonatorID *uuid.UUID `json:"impersonatorId,omitempty" db:"impersonator_id"`
+	// Current true untuk sesi milik request yang sedang berjalan
+	Current bool `json:"current"`
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
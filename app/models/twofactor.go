@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Tujuan challenge token yang dikembalikan Login sebelum token akses diterbitkan
+const (
+	// ChallengeTwoFactorVerify: user sudah enrol, tinggal kirim kode TOTP / recovery code
+	ChallengeTwoFactorVerify = "2fa_verify"
+	// ChallengeTwoFactorEnroll: role mewajibkan 2FA tapi user belum enrol
+	ChallengeTwoFactorEnroll = "2fa_enroll"
+)
+
+var (
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required for this role")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+)
+
+type UserTOTP struct {
+	UserID       uuid.UUID  `json:"userId" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	LastUsedStep *int64     `json:"-" db:"last_used_step"`
+	ConfirmedAt  *time.Time `json:"confirmedAt,omitempty" db:"confirmed_at"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+type TwoFactorChallengeClaims struct {
+	TokenType string `json:"token_type"`
+	Purpose   string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool      `json:"twoFactorRequired"`
+	SetupRequired     bool      `json:"setupRequired"`
+	ChallengeToken    string    `json:"challengeToken"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	RequiredByRole         bool       `json:"requiredByRole"`
+	ConfirmedAt            *time.Time `json:"confirmedAt,omitempty"`
+	RecoveryCodesRemaining int        `json:"recoveryCodesRemaining"`
+}
+
+// TwoFactorCodeRequest kode TOTP; beberapa endpoint juga menerima recovery code
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode,omitempty"`
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recoveryCode,omitempty"`
+}
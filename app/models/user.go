@@ -55,12 +55,33 @@ type LoginResponse struct {
 	User         User     `json:"user"`
 	Permissions  []string `json:"permissions"`
 	RoleName     string   `json:"roleName"`
+	// RecoveryCodes hanya terisi saat login menyelesaikan enrol 2FA yang diwajibkan role
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
 }
 
+// TokenIssuer klaim iss semua token yang diterbitkan sistem ini
+const TokenIssuer = "achievement-system"
+
+// Jenis token (klaim token_type). Semua jenis ditandatangani kunci yang sama dan dipublikasikan
+// di JWKS, jadi verifier harus mengecek token_type == "access" selain tanda tangan dan iss.
+const (
+	TokenTypeAccess             = "access"
+	TokenTypeRefresh            = "refresh"
+	TokenTypeTwoFactorChallenge = "2fa_challenge"
+)
+
 type JWTClaims struct {
-	UserID      string   `json:"user_id"`
-	Email       string   `json:"email"`
-	RoleID      string   `json:"role_id"`
-	Permissions []string `json:"permissions"`
+	TokenType string `json:"token_type"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	RoleID    string `json:"role_id"`
+	SessionID string `json:"sid"`
+	// Act hanya ada di token impersonation
+	Act *ActorClaim `json:"act,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type RefreshTokenClaims struct {
+	TokenType string `json:"token_type"`
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"achievement-backend/app/models"
+
+	"github.com/google/uuid"
+)
+
+// AchievementOutboxRepository antrian operasi Mongo yang menyusul perubahan achievement_references.
+// Semua baris ditulis lewat AchievementReferenceRepository dalam transaksi yang sama dengan
+// perubahan / penguncian reference-nya.
+type AchievementOutboxRepository interface {
+	// ClaimDue mengambil baris pending/compensating yang jatuh tempo dan menggeser
+	// next_attempt_at sejauh lease supaya tidak diproses ganda oleh instance lain
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.AchievementOutbox, error)
+	MarkDone(ctx context.Context, id uuid.UUID) error
+	MarkRetry(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error
+	MarkCompensating(ctx context.Context, id uuid.UUID, lastError string) error
+	MarkFailed(ctx context.Context, id uuid.UUID, lastError string) error
+	// CompensateCreate menghapus reference yang dokumen Mongo-nya tidak pernah tersimpan
+	CompensateCreate(ctx context.Context, entry *models.AchievementOutbox) error
+}
+
+type achievementOutboxRepo struct {
+	DB *sql.DB
+}
+
+func NewAchievementOutboxRepository(db *sql.DB) AchievementOutboxRepository {
+	return &achievementOutboxRepo{DB: db}
+}
+
+func (r *achievementOutboxRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*models.AchievementOutbox, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		UPDATE achievement_outbox
+		SET next_attempt_at = $1, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM achievement_outbox
+			WHERE status IN ($2, $3) AND next_attempt_at <= NOW()
+			ORDER BY created_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, operation, reference_id, mongo_achievement_id, payload, status,
+		          attempts, last_error, next_attempt_at, created_at, updated_at, completed_at
+	`, time.Now().Add(lease), models.OutboxStatusPending, models.OutboxStatusCompensating, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.AchievementOutbox
+	for rows.Next() {
+		var entry models.AchievementOutbox
+		var payload []byte
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.Operation,
+			&entry.ReferenceID,
+			&entry.MongoAchievementID,
+			&payload,
+			&entry.Status,
+			&entry.Attempts,
+			&entry.LastError,
+			&entry.NextAttemptAt,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+			&entry.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		entry.Payload = payload
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING tidak menjamin urutan; operasi untuk dokumen yang sama harus diproses berurutan
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+func (r *achievementOutboxRepo) MarkDone(ctx context.Context, id uuid.UUID) error {
+	return r.finish(ctx, id, models.OutboxStatusDone, nil)
+}
+
+func (r *achievementOutboxRepo) MarkFailed(ctx context.Context, id uuid.UUID, lastError string) error {
+	return r.finish(ctx, id, models.OutboxStatusFailed, &lastError)
+}
+
+func (r *achievementOutboxRepo) MarkRetry(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE achievement_outbox
+		SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2, updated_at = NOW()
+		WHERE id = $3
+	`, lastError, nextAttemptAt, id)
+	return err
+}
+
+func (r *achievementOutboxRepo) MarkCompensating(ctx context.Context, id uuid.UUID, lastError string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE achievement_outbox
+		SET status = $1, attempts = attempts + 1, last_error = $2, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE id = $3
+	`, models.OutboxStatusCompensating, lastError, id)
+	return err
+}
+
+func (r *achievementOutboxRepo) CompensateCreate(ctx context.Context, entry *models.AchievementOutbox) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Riwayat status ikut terhapus lewat ON DELETE CASCADE
+	if _, err := tx.ExecContext(ctx, `DELETE FROM achievement_references WHERE id = $1`, entry.ReferenceID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE achievement_outbox
+		SET status = $1, completed_at = NOW(), updated_at = NOW()
+		WHERE id = $2
+	`, models.OutboxStatusCompensated, entry.ID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *achievementOutboxRepo) finish(ctx context.Context, id uuid.UUID, status string, lastError *string) error {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE achievement_outbox
+		SET status = $1, last_error = COALESCE($2, last_error), completed_at = NOW(), updated_at = NOW()
+		WHERE id = $3
+	`, status, lastError, id)
+	return err
+}
+
+// insertOutbox dipanggil di dalam transaksi perubahan reference supaya keduanya commit bersama
+func insertOutbox(ctx context.Context, tx *sql.Tx, entry *models.AchievementOutbox) error {
+	if entry.Status == "" {
+		entry.Status = models.OutboxStatusPending
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO achievement_outbox
+		(id, operation, reference_id, mongo_achievement_id, payload, status,
+		 attempts, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		entry.ID,
+		entry.Operation,
+		entry.ReferenceID,
+		entry.MongoAchievementID,
+		nullJSON(entry.Payload),
+		entry.Status,
+		entry.Attempts,
+		entry.NextAttemptAt,
+		entry.CreatedAt,
+		entry.CreatedAt,
+	)
+	return err
+}
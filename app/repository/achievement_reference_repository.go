@@ -14,26 +14,48 @@ import (
 
 type AchievementReferenceRepository interface {
 	// Basic CRUD
-	Create(ctx context.Context, ref *models.AchievementReference) error
+	Create(ctx context.Context, ref *models.AchievementReference, actorID uuid.UUID) error
+	// CreateWithOutbox menyimpan reference dan operasi Mongo pendampingnya dalam satu transaksi
+	CreateWithOutbox(ctx context.Context, ref *models.AchievementReference, actorID uuid.UUID, entry *models.AchievementOutbox) error
 	FindByID(ctx context.Context, id uuid.UUID) (*models.AchievementReference, error)
 	FindByStudentID(ctx context.Context, studentID uuid.UUID, status string, page, limit int) ([]*models.AchievementReference, int, error)
 	FindByMongoID(ctx context.Context, mongoID string) (*models.AchievementReference, error)
-	UpdateStatus(ctx context.Context, id uuid.UUID, status string, verifiedBy *uuid.UUID, rejectionNote *string) error
+	UpdateStatus(ctx context.Context, id uuid.UUID, expectedStatus, status string, actorID uuid.UUID, verifiedBy *uuid.UUID, rejectionNote *string, note string) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	SoftDelete(ctx context.Context, id uuid.UUID) error
+	SoftDelete(ctx context.Context, id uuid.UUID, actorID uuid.UUID) error
+	SoftDeleteWithOutbox(ctx context.Context, id uuid.UUID, actorID uuid.UUID, entry *models.AchievementOutbox) error
+	// EnqueueForAction mencatat operasi outbox dan riwayat untuk action tanpa perubahan status
+	// (edit, attach) setelah mengunci reference dan memastikan statusnya masih sesuai transisi
+	EnqueueForAction(ctx context.Context, id uuid.UUID, action models.AchievementAction, actorID uuid.UUID, entry *models.AchievementOutbox) error
 	// For Dosen Wali
 	FindByAdvisorID(ctx context.Context, advisorID uuid.UUID, status string, page, limit int) ([]*models.AchievementReference, int, error)
 	// For Admin
 	FindAll(ctx context.Context, status string, page, limit int) ([]*models.AchievementReference, int, error)
+	// Daftar dengan filter: scope actor + filter Postgres di AchievementListFilter (filter Mongo diabaikan)
+	FindFiltered(ctx context.Context, scope models.AchievementScope, filter models.AchievementListFilter, page, limit int) ([]*models.AchievementReference, int, error)
+	// FindFilteredAfter versi cursor FindFiltered, hanya untuk sort kolom Postgres; tanpa COUNT(*)
+	FindFilteredAfter(ctx context.Context, scope models.AchievementScope, filter models.AchievementListFilter, cursor *models.Cursor, limit int) ([]*models.AchievementReference, models.CursorPage, error)
+	// FindFilteredMongoIDs satu batch mongo_achievement_id yang lolos filter Postgres, urut sesuai filter.Sort
+	FindFilteredMongoIDs(ctx context.Context, scope models.AchievementScope, filter models.AchievementListFilter, offset, limit int) ([]string, error)
+	// FindFilteredByMongoIDs reference di antara mongoIDs yang lolos scope dan filter Postgres
+	FindFilteredByMongoIDs(ctx context.Context, scope models.AchievementScope, filter models.AchievementListFilter, mongoIDs []string) (map[string]*models.AchievementReference, error)
+	FindByMongoIDs(ctx context.Context, mongoIDs []string) (map[string]*models.AchievementReference, error)
 	// Status transitions
-	SubmitForVerification(ctx context.Context, id uuid.UUID) error
+	SubmitForVerification(ctx context.Context, id uuid.UUID, actorID uuid.UUID) error
 	VerifyAchievement(ctx context.Context, id uuid.UUID, verifiedBy uuid.UUID) error
 	RejectAchievement(ctx context.Context, id uuid.UUID, verifiedBy uuid.UUID, rejectionNote string) error
+	ReviseAchievement(ctx context.Context, id uuid.UUID, actorID uuid.UUID) error
+	// Status history
+	FindStatusHistory(ctx context.Context, id uuid.UUID) ([]*models.AchievementStatusHistory, error)
 	// Statistics
 	CountByStatus(ctx context.Context, studentID uuid.UUID) (map[string]int, error)
 	CountByStudentAndStatus(ctx context.Context, studentID uuid.UUID, status string) (int, error)
 	// Get student IDs for advisor (helper)
 	GetStudentIDsByAdvisor(ctx context.Context, advisorID uuid.UUID) ([]uuid.UUID, error)
+	// Reconciliation
+	FindBatchAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]*models.AchievementReference, error)
+	ExistingMongoIDs(ctx context.Context, mongoIDs []string) (map[string]bool, error)
+	Quarantine(ctx context.Context, id uuid.UUID, note string) error
 }
 
 type achievementReferenceRepo struct {
@@ -44,35 +66,26 @@ func NewAchievementReferenceRepository(db *sql.DB) AchievementReferenceRepositor
 	return &achievementReferenceRepo{DB: db}
 }
 
-func (r *achievementReferenceRepo) Create(ctx context.Context, ref *models.AchievementReference) error {
-	query := `
-		INSERT INTO achievement_references 
-		(id, student_id, mongo_achievement_id, status, submitted_at, 
-		 verified_at, verified_by, rejection_note, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-	`
-	
-	_, err := r.DB.ExecContext(ctx, query,
-		ref.ID,
-		ref.StudentID,
-		ref.MongoAchievementID,
-		ref.Status,
-		ref.SubmittedAt,
-		ref.VerifiedAt,
-		ref.VerifiedBy,
-		ref.RejectionNote,
-		ref.CreatedAt,
-		ref.UpdatedAt,
-	)
-	
-	return err
+func (r *achievementReferenceRepo) Create(ctx context.Context, ref *models.AchievementReference, actorID uuid.UUID) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return insertReference(ctx, tx, ref, actorID)
+	})
+}
+
+func (r *achievementReferenceRepo) CreateWithOutbox(ctx context.Context, ref *models.AchievementReference, actorID uuid.UUID, entry *models.AchievementOutbox) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := insertReference(ctx, tx, ref, actorID); err != nil {
+			return err
+		}
+		return insertOutbox(ctx, tx, entry)
+	})
 }
 
 func (r *achievementReferenceRepo) FindByID(ctx context.Context, id uuid.UUID) (*models.AchievementReference, error) {
 	query := `
 		SELECT id, student_id, mongo_achievement_id, status, 
 		       submitted_at, verified_at, verified_by, rejection_note,
-		       created_at, updated_at
+		       resubmission_count, created_at, updated_at
 		FROM achievement_references
 		WHERE id = $1
 	`
@@ -87,6 +100,7 @@ func (r *achievementReferenceRepo) FindByID(ctx context.Context, id uuid.UUID) (
 		&ref.VerifiedAt,
 		&ref.VerifiedBy,
 		&ref.RejectionNote,
+		&ref.ResubmissionCount,
 		&ref.CreatedAt,
 		&ref.UpdatedAt,
 	)
@@ -114,7 +128,7 @@ func (r *achievementReferenceRepo) FindByStudentID(ctx context.Context, studentI
 	baseQuery := `
 		SELECT id, student_id, mongo_achievement_id, status, 
 		       submitted_at, verified_at, verified_by, rejection_note,
-		       created_at, updated_at
+		       resubmission_count, created_at, updated_at
 		FROM achievement_references
 		WHERE student_id = $1
 	`
@@ -169,6 +183,7 @@ func (r *achievementReferenceRepo) FindByStudentID(ctx context.Context, studentI
 			&ref.VerifiedAt,
 			&ref.VerifiedBy,
 			&ref.RejectionNote,
+			&ref.ResubmissionCount,
 			&ref.CreatedAt,
 			&ref.UpdatedAt,
 		)
@@ -185,7 +200,7 @@ func (r *achievementReferenceRepo) FindByMongoID(ctx context.Context, mongoID st
 	query := `
 		SELECT id, student_id, mongo_achievement_id, status, 
 		       submitted_at, verified_at, verified_by, rejection_note,
-		       created_at, updated_at
+		       resubmission_count, created_at, updated_at
 		FROM achievement_references
 		WHERE mongo_achievement_id = $1
 	`
@@ -200,6 +215,7 @@ func (r *achievementReferenceRepo) FindByMongoID(ctx context.Context, mongoID st
 		&ref.VerifiedAt,
 		&ref.VerifiedBy,
 		&ref.RejectionNote,
+		&ref.ResubmissionCount,
 		&ref.CreatedAt,
 		&ref.UpdatedAt,
 	)
@@ -214,33 +230,44 @@ func (r *achievementReferenceRepo) FindByMongoID(ctx context.Context, mongoID st
 	return &ref, nil
 }
 
-func (r *achievementReferenceRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string, verifiedBy *uuid.UUID, rejectionNote *string) error {
+func (r *achievementReferenceRepo) UpdateStatus(ctx context.Context, id uuid.UUID, expectedStatus, status string, actorID uuid.UUID, verifiedBy *uuid.UUID, rejectionNote *string, note string) error {
+	now := time.Now()
 	query := `
-		UPDATE achievement_references 
-		SET status = $1, 
+		UPDATE achievement_references
+		SET status = $1,
 		    verified_by = $2,
 		    rejection_note = $3,
 		    updated_at = $4
 	`
-	params := []interface{}{status, verifiedBy, rejectionNote, time.Now()}
+	params := []interface{}{status, verifiedBy, rejectionNote, now}
 	paramCount := 5
-	
+
 	switch status {
 	case "submitted":
 		query += `, submitted_at = $` + fmt.Sprintf("%d", paramCount)
-		params = append(params, time.Now())
+		params = append(params, now)
 		paramCount++
 	case "verified":
 		query += `, verified_at = $` + fmt.Sprintf("%d", paramCount)
-		params = append(params, time.Now())
+		params = append(params, now)
 		paramCount++
 	}
-	
-	query += ` WHERE id = $` + fmt.Sprintf("%d", paramCount)
-	params = append(params, id)
-	
-	_, err := r.DB.ExecContext(ctx, query, params...)
-	return err
+
+	query += fmt.Sprintf(` WHERE id = $%d AND status = $%d`, paramCount, paramCount+1)
+	params = append(params, id, expectedStatus)
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, params...)
+		if err != nil {
+			return err
+		}
+
+		if err := checkTransitionApplied(ctx, tx, result, id, expectedStatus); err != nil {
+			return err
+		}
+
+		return insertStatusHistory(ctx, tx, id, &expectedStatus, status, &actorID, note, now)
+	})
 }
 
 func (r *achievementReferenceRepo) Update(ctx context.Context, ref *models.AchievementReference) error {
@@ -273,28 +300,58 @@ func (r *achievementReferenceRepo) Delete(ctx context.Context, id uuid.UUID) err
 	return err
 }
 
-func (r *achievementReferenceRepo) SoftDelete(ctx context.Context, id uuid.UUID) error {
-	query := `
-		UPDATE achievement_references 
-		SET status = 'deleted', 
-		    updated_at = $1
-		WHERE id = $2 AND status = 'draft'  -- Hanya draft yang bisa di-delete
-	`
-	result, err := r.DB.ExecContext(ctx, query, time.Now(), id)
+func (r *achievementReferenceRepo) SoftDelete(ctx context.Context, id uuid.UUID, actorID uuid.UUID) error {
+	return r.applyTransition(ctx, id, models.ActionDelete, actorID, "", nil, "Achievement deleted")
+}
+
+func (r *achievementReferenceRepo) SoftDeleteWithOutbox(ctx context.Context, id uuid.UUID, actorID uuid.UUID, entry *models.AchievementOutbox) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := applyTransitionTx(ctx, tx, id, models.ActionDelete, actorID, "", nil, "Achievement deleted"); err != nil {
+			return err
+		}
+		return insertOutbox(ctx, tx, entry)
+	})
+}
+
+// EnqueueForAction memakai SELECT ... FOR UPDATE sehingga submit / verify yang berjalan bersamaan
+// menunggu sampai baris outbox tercatat, atau edit ditolak dengan ErrStatusConflict jika status
+// sudah berubah sejak dicek handler. Setiap edit / upload tercatat di status history beserta actor.
+func (r *achievementReferenceRepo) EnqueueForAction(ctx context.Context, id uuid.UUID, action models.AchievementAction, actorID uuid.UUID, entry *models.AchievementOutbox) error {
+	t, err := models.GetAchievementTransition(action)
 	if err != nil {
 		return err
 	}
-	
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
+	if t.From != t.To {
+		return fmt.Errorf("%w: %s changes status, use a transition", models.ErrInvalidTransition, action)
 	}
-	
-	if rowsAffected == 0 {
-		return fmt.Errorf("achievement not found or not in draft status")
+	expected := string(t.From)
+	note := "Achievement updated"
+	if action == models.ActionAttach {
+		note = "Attachments uploaded"
 	}
-	
-	return nil
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		var current string
+		err := tx.QueryRowContext(ctx, `SELECT status FROM achievement_references WHERE id = $1 FOR UPDATE`, id).Scan(&current)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("achievement not found")
+			}
+			return err
+		}
+		if current != expected {
+			return fmt.Errorf("%w: expected %s, current %s", models.ErrStatusConflict, expected, current)
+		}
+
+		now := time.Now()
+		if _, err := tx.ExecContext(ctx, `UPDATE achievement_references SET updated_at = $1 WHERE id = $2`, now, id); err != nil {
+			return err
+		}
+		if err := insertStatusHistory(ctx, tx, id, &expected, expected, &actorID, note, now); err != nil {
+			return err
+		}
+		return insertOutbox(ctx, tx, entry)
+	})
 }
 
 func (r *achievementReferenceRepo) FindByAdvisorID(ctx context.Context, advisorID uuid.UUID, status string, page, limit int) ([]*models.AchievementReference, int, error) {
@@ -321,7 +378,7 @@ func (r *achievementReferenceRepo) FindByAdvisorID(ctx context.Context, advisorI
 	baseQuery := `
 		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, 
 		       ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
-		       ar.created_at, ar.updated_at
+		       ar.resubmission_count, ar.created_at, ar.updated_at
 		FROM achievement_references ar
 		WHERE ar.student_id = ANY($1)
 	`
@@ -377,6 +434,7 @@ func (r *achievementReferenceRepo) FindByAdvisorID(ctx context.Context, advisorI
 			&ref.VerifiedAt,
 			&ref.VerifiedBy,
 			&ref.RejectionNote,
+			&ref.ResubmissionCount,
 			&ref.CreatedAt,
 			&ref.UpdatedAt,
 		)
@@ -402,7 +460,7 @@ func (r *achievementReferenceRepo) FindAll(ctx context.Context, status string, p
 	baseQuery := `
 		SELECT id, student_id, mongo_achievement_id, status, 
 		       submitted_at, verified_at, verified_by, rejection_note,
-		       created_at, updated_at
+		       resubmission_count, created_at, updated_at
 		FROM achievement_references
 	`
 	countQuery := `SELECT COUNT(*) FROM achievement_references`
@@ -454,6 +512,7 @@ func (r *achievementReferenceRepo) FindAll(ctx context.Context, status string, p
 			&ref.VerifiedAt,
 			&ref.VerifiedBy,
 			&ref.RejectionNote,
+			&ref.ResubmissionCount,
 			&ref.CreatedAt,
 			&ref.UpdatedAt,
 		)
@@ -466,84 +525,303 @@ func (r *achievementReferenceRepo) FindAll(ctx context.Context, status string, p
 	return references, total, nil
 }
 
-func (r *achievementReferenceRepo) SubmitForVerification(ctx context.Context, id uuid.UUID) error {
+func (r *achievementReferenceRepo) FindFiltered(ctx context.Context, scope models.AchievementScope, filter models.AchievementListFilter, page, limit int) ([]*models.AchievementReference, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	from, params := referenceFilterSQL(scope, filter)
+
+	var total int
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*)`+from, params...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
 	query := `
-		UPDATE achievement_references 
-		SET status = 'submitted', 
-		    submitted_at = $1,
-		    updated_at = $2
-		WHERE id = $3 AND status = 'draft'
-	`
-	
-	result, err := r.DB.ExecContext(ctx, query, time.Now(), time.Now(), id)
+		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+		       ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+		       ar.resubmission_count, ar.created_at, ar.updated_at` + from + referenceOrderSQL(filter) +
+		fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(params)+1, len(params)+2)
+	params = append(params, limit, (page-1)*limit)
+
+	rows, err := r.DB.QueryContext(ctx, query, params...)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
-	
-	rowsAffected, err := result.RowsAffected()
+	defer rows.Close()
+
+	references, err := scanReferenceRows(rows)
 	if err != nil {
-		return err
+		return nil, 0, err
 	}
-	
-	if rowsAffected == 0 {
-		return fmt.Errorf("achievement not found or not in draft status")
+	return references, total, nil
+}
+
+func (r *achievementReferenceRepo) FindFilteredAfter(ctx context.Context, scope models.AchievementScope, filter models.AchievementListFilter, cursor *models.Cursor, limit int) ([]*models.AchievementReference, models.CursorPage, error) {
+	if limit < 1 || limit > 100 {
+		limit = 10
 	}
-	
-	return nil
+
+	from, params := referenceFilterSQL(scope, filter)
+	keys, rowKey := referenceKeyset(filter)
+	where, orderBy, params, err := keys.sql(cursor, params)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+
+	query := `
+		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+		       ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+		       ar.resubmission_count, ar.created_at, ar.updated_at` + from + where + orderBy +
+		fmt.Sprintf(` LIMIT $%d`, len(params)+1)
+	params = append(params, limit+1)
+
+	rows, err := r.DB.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	defer rows.Close()
+
+	references, err := scanReferenceRows(rows)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	references, page := keysetPage(keys, references, limit, cursor, func(ref *models.AchievementReference) (string, uuid.UUID) {
+		return rowKey(ref), ref.ID
+	})
+	return references, page, nil
 }
 
-func (r *achievementReferenceRepo) VerifyAchievement(ctx context.Context, id uuid.UUID, verifiedBy uuid.UUID) error {
+func (r *achievementReferenceRepo) FindFilteredMongoIDs(ctx context.Context, scope models.AchievementScope, filter models.AchievementListFilter, offset, limit int) ([]string, error) {
+	from, params := referenceFilterSQL(scope, filter)
+	query := `SELECT ar.mongo_achievement_id` + from + referenceOrderSQL(filter) +
+		fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(params)+1, len(params)+2)
+	params = append(params, limit, offset)
+
+	rows, err := r.DB.QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mongoIDs []string
+	for rows.Next() {
+		var mongoID string
+		if err := rows.Scan(&mongoID); err != nil {
+			return nil, err
+		}
+		mongoIDs = append(mongoIDs, mongoID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return mongoIDs, nil
+}
+
+func (r *achievementReferenceRepo) FindFilteredByMongoIDs(ctx context.Context, scope models.AchievementScope, filter models.AchievementListFilter, mongoIDs []string) (map[string]*models.AchievementReference, error) {
+	references := make(map[string]*models.AchievementReference, len(mongoIDs))
+	if len(mongoIDs) == 0 {
+		return references, nil
+	}
+
+	from, params := referenceFilterSQL(scope, filter)
+	params = append(params, pq.Array(mongoIDs))
 	query := `
-		UPDATE achievement_references 
-		SET status = 'verified', 
-		    verified_at = $1,
-		    verified_by = $2,
-		    updated_at = $3
-		WHERE id = $4 AND status = 'submitted'
-	`
-	
-	result, err := r.DB.ExecContext(ctx, query, time.Now(), verifiedBy, time.Now(), id)
+		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+		       ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+		       ar.resubmission_count, ar.created_at, ar.updated_at` + from +
+		fmt.Sprintf(` AND ar.mongo_achievement_id = ANY($%d)`, len(params))
+
+	rows, err := r.DB.QueryContext(ctx, query, params...)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	
-	rowsAffected, err := result.RowsAffected()
+	defer rows.Close()
+
+	list, err := scanReferenceRows(rows)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	
-	if rowsAffected == 0 {
-		return fmt.Errorf("achievement not found or not in submitted status")
+	for _, ref := range list {
+		references[ref.MongoAchievementID] = ref
 	}
-	
-	return nil
+	return references, nil
+}
+
+func (r *achievementReferenceRepo) FindByMongoIDs(ctx context.Context, mongoIDs []string) (map[string]*models.AchievementReference, error) {
+	references := make(map[string]*models.AchievementReference, len(mongoIDs))
+	if len(mongoIDs) == 0 {
+		return references, nil
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, student_id, mongo_achievement_id, status,
+		       submitted_at, verified_at, verified_by, rejection_note,
+		       resubmission_count, created_at, updated_at
+		FROM achievement_references
+		WHERE mongo_achievement_id = ANY($1)
+	`, pq.Array(mongoIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := scanReferenceRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range list {
+		references[ref.MongoAchievementID] = ref
+	}
+	return references, nil
+}
+
+// referenceFilterSQL FROM ... WHERE untuk scope actor dan filter Postgres (alias ar dan s)
+func referenceFilterSQL(scope models.AchievementScope, filter models.AchievementListFilter) (string, []interface{}) {
+	query := `
+		FROM achievement_references ar
+		LEFT JOIN students s ON s.id = ar.student_id
+		WHERE 1=1`
+	var params []interface{}
+	add := func(condition string, value interface{}) {
+		params = append(params, value)
+		query += fmt.Sprintf(` AND `+condition, len(params))
+	}
+
+	switch scope.Relation {
+	case models.RelationOwner:
+		add(`ar.student_id = $%d`, scope.ID)
+	case models.RelationAdvisor:
+		add(`s.advisor_id = $%d`, scope.ID)
+	}
+
+	// deleted hanya tampil jika diminta eksplisit
+	switch filter.Status {
+	case "":
+		query += ` AND ar.status != 'deleted'`
+	default:
+		add(`ar.status = $%d`, filter.Status)
+	}
+
+	if filter.ProgramStudy != "" {
+		add(`s.program_study = $%d`, filter.ProgramStudy)
+	}
+	if filter.AcademicYear != "" {
+		add(`s.academic_year = $%d`, filter.AcademicYear)
+	}
+
+	return query, params
+}
+
+// referenceOrderSQL ORDER BY sesuai filter.Sort; sort dokumen Mongo memakai created_at
+func referenceOrderSQL(filter models.AchievementListFilter) string {
+	column := "ar.created_at"
+	switch filter.Sort {
+	case models.AchievementSortSubmittedAt:
+		column = "ar.submitted_at"
+	case models.AchievementSortVerifiedAt:
+		column = "ar.verified_at"
+	}
+
+	direction := " DESC"
+	if filter.Ascending {
+		direction = " ASC"
+	}
+	return ` ORDER BY ` + column + direction + ` NULLS LAST, ar.id` + direction
+}
+
+// referenceKeyset keyset sesuai filter.Sort dan arahnya. NULL (belum submit / verifikasi)
+// diganti -infinity atau infinity supaya tetap di akhir seperti NULLS LAST.
+func referenceKeyset(filter models.AchievementListFilter) (keyset, func(*models.AchievementReference) string) {
+	direction := "desc"
+	nullKey := "-infinity"
+	if filter.Ascending {
+		direction = "asc"
+		nullKey = "infinity"
+	}
+	keys := keyset{
+		Sort:       "achievements:" + filter.Sort + ":" + direction,
+		Key:        "ar.created_at",
+		ID:         "ar.id",
+		Descending: !filter.Ascending,
+		Time:       true,
+	}
+	nullable := func(t *time.Time) string {
+		if t == nil {
+			return nullKey
+		}
+		return timeKey(*t)
+	}
+
+	switch filter.Sort {
+	case models.AchievementSortSubmittedAt:
+		keys.Key = `COALESCE(ar.submitted_at, '` + nullKey + `')`
+		return keys, func(ref *models.AchievementReference) string { return nullable(ref.SubmittedAt) }
+	case models.AchievementSortVerifiedAt:
+		keys.Key = `COALESCE(ar.verified_at, '` + nullKey + `')`
+		return keys, func(ref *models.AchievementReference) string { return nullable(ref.VerifiedAt) }
+	}
+	return keys, func(ref *models.AchievementReference) string { return timeKey(ref.CreatedAt) }
+}
+
+func (r *achievementReferenceRepo) SubmitForVerification(ctx context.Context, id uuid.UUID, actorID uuid.UUID) error {
+	// Submit ulang setelah pernah ditolak dihitung sebagai resubmission
+	set := `, submitted_at = $2,
+		resubmission_count = resubmission_count + CASE WHEN rejection_note IS NOT NULL THEN 1 ELSE 0 END`
+	return r.applyTransition(ctx, id, models.ActionSubmit, actorID, set, nil, "Submitted for verification")
+}
+
+func (r *achievementReferenceRepo) VerifyAchievement(ctx context.Context, id uuid.UUID, verifiedBy uuid.UUID) error {
+	set := `, verified_at = $2, verified_by = $3`
+	return r.applyTransition(ctx, id, models.ActionVerify, verifiedBy, set, []interface{}{verifiedBy}, "Achievement verified")
 }
 
 func (r *achievementReferenceRepo) RejectAchievement(ctx context.Context, id uuid.UUID, verifiedBy uuid.UUID, rejectionNote string) error {
+	set := `, verified_by = $3, rejection_note = $4`
+	return r.applyTransition(ctx, id, models.ActionReject, verifiedBy, set, []interface{}{verifiedBy, rejectionNote}, rejectionNote)
+}
+
+func (r *achievementReferenceRepo) ReviseAchievement(ctx context.Context, id uuid.UUID, actorID uuid.UUID) error {
+	// rejection_note dan verified_by sengaja tidak dihapus agar catatan penolakan tetap terlihat
+	return r.applyTransition(ctx, id, models.ActionRevise, actorID, "", nil, "Revised for resubmission")
+}
+
+func (r *achievementReferenceRepo) FindStatusHistory(ctx context.Context, id uuid.UUID) ([]*models.AchievementStatusHistory, error) {
 	query := `
-		UPDATE achievement_references 
-		SET status = 'rejected', 
-		    verified_by = $1,
-		    rejection_note = $2,
-		    updated_at = $3
-		WHERE id = $4 AND status = 'submitted'
+		SELECT id, achievement_ref_id, from_status, to_status,
+		       actor_id, note, created_at
+		FROM achievement_status_history
+		WHERE achievement_ref_id = $1
+		ORDER BY created_at ASC, id ASC
 	`
-	
-	result, err := r.DB.ExecContext(ctx, query, verifiedBy, rejectionNote, time.Now(), id)
-	if err != nil {
-		return err
-	}
-	
-	rowsAffected, err := result.RowsAffected()
+
+	rows, err := r.DB.QueryContext(ctx, query, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	
-	if rowsAffected == 0 {
-		return fmt.Errorf("achievement not found or not in submitted status")
+	defer rows.Close()
+
+	var history []*models.AchievementStatusHistory
+	for rows.Next() {
+		var h models.AchievementStatusHistory
+		if err := rows.Scan(
+			&h.ID,
+			&h.AchievementRefID,
+			&h.FromStatus,
+			&h.ToStatus,
+			&h.ActorID,
+			&h.Note,
+			&h.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, &h)
 	}
-	
-	return nil
+
+	return history, rows.Err()
 }
 
 func (r *achievementReferenceRepo) CountByStatus(ctx context.Context, studentID uuid.UUID) (map[string]int, error) {
@@ -616,4 +894,219 @@ func (r *achievementReferenceRepo) GetStudentIDsByAdvisor(ctx context.Context, a
 	}
 	
 	return studentIDs, nil
-}
\ No newline at end of file
+}
+
+// FindBatchAfter semua reference (termasuk deleted) urut id, untuk dipindai per batch
+func (r *achievementReferenceRepo) FindBatchAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]*models.AchievementReference, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, student_id, mongo_achievement_id, status,
+		       submitted_at, verified_at, verified_by, rejection_note,
+		       resubmission_count, created_at, updated_at
+		FROM achievement_references
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReferenceRows(rows)
+}
+
+// ExistingMongoIDs mengembalikan mongo_achievement_id yang punya reference
+func (r *achievementReferenceRepo) ExistingMongoIDs(ctx context.Context, mongoIDs []string) (map[string]bool, error) {
+	existing := make(map[string]bool, len(mongoIDs))
+	if len(mongoIDs) == 0 {
+		return existing, nil
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT mongo_achievement_id FROM achievement_references
+		WHERE mongo_achievement_id = ANY($1)
+	`, pq.Array(mongoIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mongoID string
+		if err := rows.Scan(&mongoID); err != nil {
+			return nil, err
+		}
+		existing[mongoID] = true
+	}
+
+	return existing, rows.Err()
+}
+
+// Quarantine menandai reference deleted tanpa melewati tabel transisi (status apa pun),
+// dipakai rekonsiliasi untuk reference yang dokumen Mongo-nya tidak ada
+func (r *achievementReferenceRepo) Quarantine(ctx context.Context, id uuid.UUID, note string) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		var current string
+		err := tx.QueryRowContext(ctx, `SELECT status FROM achievement_references WHERE id = $1 FOR UPDATE`, id).Scan(&current)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("achievement not found")
+			}
+			return err
+		}
+		if current == string(models.StatusDeleted) {
+			return nil
+		}
+
+		now := time.Now()
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE achievement_references SET status = $1, updated_at = $2 WHERE id = $3
+		`, models.StatusDeleted, now, id); err != nil {
+			return err
+		}
+
+		return insertStatusHistory(ctx, tx, id, &current, string(models.StatusDeleted), nil, note, now)
+	})
+}
+
+// withTx menjalankan fn dalam satu transaksi, commit jika sukses dan rollback jika error
+func (r *achievementReferenceRepo) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// applyTransition menjalankan UPDATE status sesuai tabel transisi di models dalam transaksi sendiri
+func (r *achievementReferenceRepo) applyTransition(ctx context.Context, id uuid.UUID, action models.AchievementAction, actorID uuid.UUID, extraSet string, extraArgs []interface{}, note string) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return applyTransitionTx(ctx, tx, id, action, actorID, extraSet, extraArgs, note)
+	})
+}
+
+// applyTransitionTx menjalankan UPDATE status di dalam tx yang sudah ada.
+// Placeholder $1 = status tujuan, $2 = timestamp, extraArgs mulai dari $3.
+// Kondisi WHERE status = <From> memastikan dua request paralel tidak sama-sama berhasil.
+func applyTransitionTx(ctx context.Context, tx *sql.Tx, id uuid.UUID, action models.AchievementAction, actorID uuid.UUID, extraSet string, extraArgs []interface{}, note string) error {
+	t, err := models.GetAchievementTransition(action)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	from := string(t.From)
+	to := string(t.To)
+
+	params := append([]interface{}{to, now}, extraArgs...)
+	query := `UPDATE achievement_references SET status = $1, updated_at = $2` + extraSet +
+		fmt.Sprintf(` WHERE id = $%d AND status = $%d`, len(params)+1, len(params)+2)
+	params = append(params, id, from)
+
+	result, err := tx.ExecContext(ctx, query, params...)
+	if err != nil {
+		return err
+	}
+
+	if err := checkTransitionApplied(ctx, tx, result, id, from); err != nil {
+		return err
+	}
+
+	return insertStatusHistory(ctx, tx, id, &from, to, &actorID, note, now)
+}
+
+// checkTransitionApplied membedakan "tidak ditemukan" dan "status sudah berubah" saat UPDATE tidak mengenai baris
+func checkTransitionApplied(ctx context.Context, tx *sql.Tx, result sql.Result, id uuid.UUID, expected string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var current string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM achievement_references WHERE id = $1`, id).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("achievement not found")
+		}
+		return err
+	}
+
+	return fmt.Errorf("%w: expected %s, current %s", models.ErrStatusConflict, expected, current)
+}
+
+// scanReferenceRows membaca baris dengan urutan kolom standar achievement_references
+func scanReferenceRows(rows *sql.Rows) ([]*models.AchievementReference, error) {
+	var references []*models.AchievementReference
+	for rows.Next() {
+		var ref models.AchievementReference
+		err := rows.Scan(
+			&ref.ID,
+			&ref.StudentID,
+			&ref.MongoAchievementID,
+			&ref.Status,
+			&ref.SubmittedAt,
+			&ref.VerifiedAt,
+			&ref.VerifiedBy,
+			&ref.RejectionNote,
+			&ref.ResubmissionCount,
+			&ref.CreatedAt,
+			&ref.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		references = append(references, &ref)
+	}
+	return references, rows.Err()
+}
+
+// insertReference menyimpan reference baru beserta riwayat status awalnya
+func insertReference(ctx context.Context, tx *sql.Tx, ref *models.AchievementReference, actorID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO achievement_references
+		(id, student_id, mongo_achievement_id, status, submitted_at,
+		 verified_at, verified_by, rejection_note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`,
+		ref.ID,
+		ref.StudentID,
+		ref.MongoAchievementID,
+		ref.Status,
+		ref.SubmittedAt,
+		ref.VerifiedAt,
+		ref.VerifiedBy,
+		ref.RejectionNote,
+		ref.CreatedAt,
+		ref.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return insertStatusHistory(ctx, tx, ref.ID, nil, ref.Status, &actorID, "Achievement created", ref.CreatedAt)
+}
+
+// insertStatusHistory mencatat satu perubahan status ke achievement_status_history
+func insertStatusHistory(ctx context.Context, tx *sql.Tx, refID uuid.UUID, fromStatus *string, toStatus string, actorID *uuid.UUID, note string, at time.Time) error {
+	var noteValue *string
+	if note != "" {
+		noteValue = &note
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO achievement_status_history
+		(id, achievement_ref_id, from_status, to_status, actor_id, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, uuid.New(), refID, fromStatus, toStatus, actorID, noteValue, at)
+
+	return err
+}
@@ -3,6 +3,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"achievement-backend/app/models"
@@ -17,11 +18,31 @@ type AchievementRepository interface {
 	// Basic CRUD
 	Create(ctx context.Context, achievement *models.Achievement) (primitive.ObjectID, error)
 	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Achievement, error)
+	// FindByIDs satu query untuk satu halaman list; ID yang tidak ditemukan tidak ada di map
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.Achievement, error)
 	FindByStudentID(ctx context.Context, studentID uuid.UUID, page, limit int) ([]*models.Achievement, int, error)
 	Update(ctx context.Context, id primitive.ObjectID, achievement *models.Achievement) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// Idempotent, dipakai outbox sehingga aman diulang setelah crash
+	CreateIfNotExists(ctx context.Context, achievement *models.Achievement) error
+	UpdateIfNotNewer(ctx context.Context, achievement *models.Achievement) error
+	MarkDeleted(ctx context.Context, id primitive.ObjectID, deletedAt time.Time) error
+
+	// Reconciliation: hanya _id, studentId dan createdAt yang diisi
+	FindKeysByIDs(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.Achievement, error)
+	FindKeysAfter(ctx context.Context, afterID primitive.ObjectID, limit int) ([]*models.Achievement, error)
+	SetStudentID(ctx context.Context, id primitive.ObjectID, studentID string) error
+	Quarantine(ctx context.Context, id primitive.ObjectID, reason string) error
 	
 	// Advanced Queries
+	// Search dokumen di antara ids (hasil filter Postgres) yang cocok dengan filter Mongo,
+	// diurutkan dan dipaginasi di Mongo. ids nil: seluruh koleksi kecuali dokumen deleted
+	Search(ctx context.Context, ids []primitive.ObjectID, filter models.AchievementListFilter, page, limit int) ([]*models.Achievement, int, error)
+	// SearchIDs _id seluruh dokumen yang cocok dengan filter Mongo, urut seperti Search
+	SearchIDs(ctx context.Context, filter models.AchievementListFilter) ([]primitive.ObjectID, error)
+	// FindMatchingIDs _id di antara ids yang cocok dengan filter Mongo, tanpa urutan
+	FindMatchingIDs(ctx context.Context, ids []primitive.ObjectID, filter models.AchievementListFilter) (map[primitive.ObjectID]bool, error)
 	FindWithFilter(ctx context.Context, filter bson.M, page, limit int) ([]*models.Achievement, int, error)
 	FindByStudentIDs(ctx context.Context, studentIDs []uuid.UUID, page, limit int) ([]*models.Achievement, int, error)
 	
@@ -78,6 +99,29 @@ func (r *achievementRepo) FindByID(ctx context.Context, id primitive.ObjectID) (
 	return &achievement, nil
 }
 
+func (r *achievementRepo) FindByIDs(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.Achievement, error) {
+	achievements := make(map[primitive.ObjectID]*models.Achievement, len(ids))
+	if len(ids) == 0 {
+		return achievements, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var achievement models.Achievement
+		if err := cursor.Decode(&achievement); err != nil {
+			return nil, err
+		}
+		achievements[achievement.ID] = &achievement
+	}
+
+	return achievements, cursor.Err()
+}
+
 func (r *achievementRepo) FindByStudentID(ctx context.Context, studentID uuid.UUID, page, limit int) ([]*models.Achievement, int, error) {
 	if page < 1 {
 		page = 1
@@ -147,6 +191,302 @@ func (r *achievementRepo) Delete(ctx context.Context, id primitive.ObjectID) err
 	return nil
 }
 
+// CreateIfNotExists insert dengan ID yang sudah ditentukan; dokumen yang sudah ada tidak ditimpa
+func (r *achievementRepo) CreateIfNotExists(ctx context.Context, achievement *models.Achievement) error {
+	filter := bson.M{"_id": achievement.ID}
+	update := bson.M{"$setOnInsert": achievement}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// UpdateIfNotNewer menimpa dokumen kecuali dokumen di Mongo sudah lebih baru dari payload.
+// Dokumen yang sudah lebih baru dianggap selesai; dokumen yang belum ada (create di outbox
+// belum diterapkan) dikembalikan sebagai mongo.ErrNoDocuments supaya baris outbox diulang.
+func (r *achievementRepo) UpdateIfNotNewer(ctx context.Context, achievement *models.Achievement) error {
+	filter := bson.M{
+		"_id":       achievement.ID,
+		"updatedAt": bson.M{"$lte": achievement.UpdatedAt},
+	}
+
+	// studentId milik reference di Postgres (bisa diperbaiki rekonsiliasi), tidak ikut ditimpa payload
+	var fields bson.M
+	data, err := bson.Marshal(achievement)
+	if err != nil {
+		return err
+	}
+	if err := bson.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	delete(fields, "_id")
+	delete(fields, "studentId")
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return r.requireExists(ctx, achievement.ID)
+	}
+	return nil
+}
+
+// MarkDeleted idempotent untuk dokumen yang sudah ditandai; dokumen yang belum ada
+// dikembalikan sebagai mongo.ErrNoDocuments supaya delete tidak mendahului create
+func (r *achievementRepo) MarkDeleted(ctx context.Context, id primitive.ObjectID, deletedAt time.Time) error {
+	filter := bson.M{"_id": id, "deletedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"deletedAt": deletedAt}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return r.requireExists(ctx, id)
+	}
+	return nil
+}
+
+func (r *achievementRepo) requireExists(ctx context.Context, id primitive.ObjectID) error {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"_id": id}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("achievement %s not created yet: %w", id.Hex(), mongo.ErrNoDocuments)
+	}
+	return nil
+}
+
+// achievementKeyProjection field minimal untuk rekonsiliasi
+var achievementKeyProjection = bson.M{"_id": 1, "studentId": 1, "createdAt": 1}
+
+func (r *achievementRepo) FindKeysByIDs(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.Achievement, error) {
+	keys := make(map[primitive.ObjectID]*models.Achievement, len(ids))
+	if len(ids) == 0 {
+		return keys, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetProjection(achievementKeyProjection))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var achievements []*models.Achievement
+	if err := cursor.All(ctx, &achievements); err != nil {
+		return nil, err
+	}
+	for _, achievement := range achievements {
+		keys[achievement.ID] = achievement
+	}
+	return keys, nil
+}
+
+func (r *achievementRepo) FindKeysAfter(ctx context.Context, afterID primitive.ObjectID, limit int) ([]*models.Achievement, error) {
+	opts := options.Find().
+		SetProjection(achievementKeyProjection).
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$gt": afterID}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var achievements []*models.Achievement
+	if err := cursor.All(ctx, &achievements); err != nil {
+		return nil, err
+	}
+	return achievements, nil
+}
+
+// SetStudentID sengaja tidak menyentuh updatedAt: update outbox yang masih tertunda untuk
+// dokumen ini harus tetap lolos pengecekan UpdateIfNotNewer
+func (r *achievementRepo) SetStudentID(ctx context.Context, id primitive.ObjectID, studentID string) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"studentId": studentID}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Quarantine memindahkan dokumen ke koleksi achievements_quarantine beserta alasannya.
+// Insert dulu baru delete: kalau proses mati di tengah, dokumen ada di dua tempat, tidak hilang.
+func (r *achievementRepo) Quarantine(ctx context.Context, id primitive.ObjectID, reason string) error {
+	var doc bson.M
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+		return err
+	}
+
+	doc["quarantinedAt"] = time.Now()
+	doc["quarantineReason"] = reason
+	quarantine := r.collection.Database().Collection("achievements_quarantine")
+	if _, err := quarantine.ReplaceOne(ctx, bson.M{"_id": id}, doc, options.Replace().SetUpsert(true)); err != nil {
+		return err
+	}
+
+	return r.Delete(ctx, id)
+}
+
+func (r *achievementRepo) Search(ctx context.Context, ids []primitive.ObjectID, filter models.AchievementListFilter, page, limit int) ([]*models.Achievement, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	query := achievementSearchFilter(ids, filter)
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(achievementSearchSort(filter))
+	if filter.Query != "" {
+		opts.SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}})
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var achievements []*models.Achievement
+	if err := cursor.All(ctx, &achievements); err != nil {
+		return nil, 0, err
+	}
+	return achievements, int(total), nil
+}
+
+func (r *achievementRepo) SearchIDs(ctx context.Context, filter models.AchievementListFilter) ([]primitive.ObjectID, error) {
+	projection := bson.M{"_id": 1}
+	if filter.Query != "" {
+		projection["score"] = bson.M{"$meta": "textScore"}
+	}
+	opts := options.Find().SetSort(achievementSearchSort(filter)).SetProjection(projection)
+
+	cursor, err := r.collection.Find(ctx, achievementSearchFilter(nil, filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID)
+	}
+	return ids, cursor.Err()
+}
+
+func (r *achievementRepo) FindMatchingIDs(ctx context.Context, ids []primitive.ObjectID, filter models.AchievementListFilter) (map[primitive.ObjectID]bool, error) {
+	cursor, err := r.collection.Find(ctx, achievementSearchFilter(ids, filter), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	matched := make(map[primitive.ObjectID]bool)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		matched[doc.ID] = true
+	}
+	return matched, cursor.Err()
+}
+
+// achievementSearchFilter filter Mongo dari AchievementListFilter, dibatasi ke ids.
+// Tanpa ids (Mongo memimpin query) dokumen yang sudah dihapus tidak ikut, kecuali yang
+// diminta memang status deleted.
+func achievementSearchFilter(ids []primitive.ObjectID, filter models.AchievementListFilter) bson.M {
+	query := bson.M{}
+	switch {
+	case ids != nil:
+		query["_id"] = bson.M{"$in": ids}
+	case filter.Status != string(models.StatusDeleted):
+		query["deletedAt"] = bson.M{"$exists": false}
+	}
+
+	if len(filter.Types) > 0 {
+		query["achievementType"] = bson.M{"$in": filter.Types}
+	}
+	if len(filter.Tags) > 0 {
+		query["tags"] = bson.M{"$all": filter.Tags}
+	}
+	if filter.CompetitionLevel != "" {
+		query["details.competitionLevel"] = filter.CompetitionLevel
+	}
+	if filter.EventFrom != nil || filter.EventTo != nil {
+		eventDate := bson.M{}
+		if filter.EventFrom != nil {
+			eventDate["$gte"] = *filter.EventFrom
+		}
+		if filter.EventTo != nil {
+			eventDate["$lt"] = *filter.EventTo
+		}
+		query["details.eventDate"] = eventDate
+	}
+	if filter.MinPoints != nil || filter.MaxPoints != nil {
+		points := bson.M{}
+		if filter.MinPoints != nil {
+			points["$gte"] = *filter.MinPoints
+		}
+		if filter.MaxPoints != nil {
+			points["$lte"] = *filter.MaxPoints
+		}
+		query["points"] = points
+	}
+	if filter.Query != "" {
+		query["$text"] = bson.M{"$search": filter.Query}
+	}
+
+	return query
+}
+
+// achievementSearchSort urutan Mongo untuk sort dokumen; _id sebagai tie-breaker supaya halaman stabil
+func achievementSearchSort(filter models.AchievementListFilter) bson.D {
+	if filter.Sort == models.AchievementSortRelevance {
+		return bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "_id", Value: -1}}
+	}
+
+	direction := -1
+	if filter.Ascending {
+		direction = 1
+	}
+	field := "createdAt"
+	switch filter.Sort {
+	case models.AchievementSortPoints:
+		field = "points"
+	case models.AchievementSortEventDate:
+		field = "details.eventDate"
+	case models.AchievementSortTitle:
+		field = "title"
+	}
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}
+}
+
 func (r *achievementRepo) FindWithFilter(ctx context.Context, filter bson.M, page, limit int) ([]*models.Achievement, int, error) {
 	if page < 1 {
 		page = 1
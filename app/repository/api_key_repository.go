@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"achievement-backend/app/models"
+	"github.com/google/uuid"
+)
+
+type APIKeyRepository interface {
+	// Create menyimpan key beserta permission-nya (berdasarkan nama) dalam satu transaksi
+	Create(key *models.APIKey) error
+	GetByPrefix(prefix string) (*models.APIKey, error)
+	GetByUser(userID uuid.UUID) ([]models.APIKey, error)
+	Revoke(id, userID uuid.UUID) error
+	// TouchLastUsed memperbarui last_used_at paling sering sekali per menit
+	TouchLastUsed(id uuid.UUID, at time.Time) error
+}
+
+type apiKeyRepo struct {
+	DB *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepo{DB: db}
+}
+
+func (r *apiKeyRepo) Create(key *models.APIKey) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		key.ID,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.ExpiresAt,
+		key.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	for _, name := range key.Permissions {
+		result, err := tx.Exec(`
+			INSERT INTO api_key_permissions (api_key_id, permission_id)
+			SELECT $1, id FROM permissions WHERE name=$2
+		`, key.ID, name)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return models.ErrPermissionNotFound
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *apiKeyRepo) GetByPrefix(prefix string) (*models.APIKey, error) {
+	var k models.APIKey
+	err := r.DB.QueryRow(`
+		SELECT id, user_id, name, prefix, key_hash, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE prefix=$1
+	`, prefix).Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	permissions, err := r.getPermissionNames(k.ID)
+	if err != nil {
+		return nil, err
+	}
+	k.Permissions = permissions
+	return &k, nil
+}
+
+func (r *apiKeyRepo) GetByUser(userID uuid.UUID) ([]models.APIKey, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, user_id, name, prefix, key_hash, expires_at, last_used_at, revoked_at, created_at
+		FROM api_keys
+		WHERE user_id=$1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		var k models.APIKey
+		if err := rows.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range keys {
+		permissions, err := r.getPermissionNames(keys[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		keys[i].Permissions = permissions
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepo) Revoke(id, userID uuid.UUID) error {
+	result, err := r.DB.Exec(`
+		UPDATE api_keys
+		SET revoked_at=$1
+		WHERE id=$2 AND user_id=$3 AND revoked_at IS NULL
+	`, time.Now(), id, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *apiKeyRepo) TouchLastUsed(id uuid.UUID, at time.Time) error {
+	_, err := r.DB.Exec(`
+		UPDATE api_keys
+		SET last_used_at=$1
+		WHERE id=$2 AND (last_used_at IS NULL OR last_used_at < $3)
+	`, at, id, at.Add(-time.Minute))
+	return err
+}
+
+func (r *apiKeyRepo) getPermissionNames(keyID uuid.UUID) ([]string, error) {
+	rows, err := r.DB.Query(`
+		SELECT p.name
+		FROM api_key_permissions akp
+		JOIN permissions p ON p.id = akp.permission_id
+		WHERE akp.api_key_id=$1
+		ORDER BY p.name
+	`, keyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, name)
+	}
+	return permissions, rows.Err()
+}
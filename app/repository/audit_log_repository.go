@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"achievement-backend/app/models"
+
+	"github.com/google/uuid"
+)
+
+// AuditLogRepository append-only: entri audit tidak pernah diubah atau dihapus
+type AuditLogRepository interface {
+	Create(entry *models.AuditLog) error
+	// List entri terbaru lebih dulu beserta total sesuai filter
+	List(filter models.AuditLogFilter) ([]models.AuditLog, int, error)
+	// ListAfter versi cursor List: filter.Page diabaikan dan total tidak dihitung
+	ListAfter(filter models.AuditLogFilter, cursor *models.Cursor) ([]models.AuditLog, models.CursorPage, error)
+}
+
+type auditLogRepo struct {
+	DB *sql.DB
+}
+
+func NewAuditLogRepository(db *sql.DB) AuditLogRepository {
+	return &auditLogRepo{DB: db}
+}
+
+func (r *auditLogRepo) Create(entry *models.AuditLog) error {
+	_, err := r.DB.Exec(insertAuditLogQuery, auditLogArgs(entry)...)
+	return err
+}
+
+// insertAuditLog dipanggil di dalam transaksi perubahan yang diaudit supaya keduanya commit bersama.
+// Entry nil dilewati.
+func insertAuditLog(tx *sql.Tx, entry *models.AuditLog) error {
+	if entry == nil {
+		return nil
+	}
+	if _, err := tx.Exec(insertAuditLogQuery, auditLogArgs(entry)...); err != nil {
+		return fmt.Errorf("error writing audit log: %w", err)
+	}
+	return nil
+}
+
+const insertAuditLogQuery = `
+	INSERT INTO audit_logs (
+		id, actor_id, impersonator_id, action, target_type, target_id, reason, before_data, after_data,
+		session_id, method, path, status_code, ip_address, user_agent, created_at
+	)
+	VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, $9, $10, NULLIF($11, ''), NULLIF($12, ''), NULLIF($13, 0), NULLIF($14, ''), NULLIF($15, ''), $16)
+`
+
+func auditLogArgs(entry *models.AuditLog) []interface{} {
+	return []interface{}{
+		entry.ID,
+		entry.ActorID,
+		entry.ImpersonatorID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.Reason,
+		nullJSON(entry.Before),
+		nullJSON(entry.After),
+		entry.SessionID,
+		entry.Method,
+		entry.Path,
+		entry.StatusCode,
+		entry.IPAddress,
+		entry.UserAgent,
+		entry.CreatedAt,
+	}
+}
+
+func (r *auditLogRepo) List(filter models.AuditLogFilter) ([]models.AuditLog, int, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	where, params := auditLogWhere(filter)
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM audit_logs`+where, params...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, actor_id, impersonator_id, action, target_type, target_id, reason, before_data, after_data,
+		       session_id, method, path, status_code, ip_address, user_agent, created_at
+		FROM audit_logs` + where +
+		fmt.Sprintf(` ORDER BY created_at DESC, id LIMIT $%d OFFSET $%d`, len(params)+1, len(params)+2)
+	params = append(params, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := r.DB.Query(query, params...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries, err := scanAuditLogs(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+var auditLogsKeyset = keyset{Sort: "audit_logs", Key: "created_at", ID: "id", Descending: true, Time: true}
+
+func (r *auditLogRepo) ListAfter(filter models.AuditLogFilter, cursor *models.Cursor) ([]models.AuditLog, models.CursorPage, error) {
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	where, params := auditLogWhere(filter)
+	keysetWhere, orderBy, params, err := auditLogsKeyset.sql(cursor, params)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+
+	query := `
+		SELECT id, actor_id, impersonator_id, action, target_type, target_id, reason, before_data, after_data,
+		       session_id, method, path, status_code, ip_address, user_agent, created_at
+		FROM audit_logs` + where + keysetWhere + orderBy +
+		fmt.Sprintf(` LIMIT $%d`, len(params)+1)
+	params = append(params, filter.Limit+1)
+
+	rows, err := r.DB.Query(query, params...)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	defer rows.Close()
+
+	entries, err := scanAuditLogs(rows)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	entries, page := keysetPage(auditLogsKeyset, entries, filter.Limit, cursor, func(e models.AuditLog) (string, uuid.UUID) {
+		return timeKey(e.CreatedAt), e.ID
+	})
+	return entries, page, nil
+}
+
+// auditLogWhere WHERE sesuai filter (tanpa pagination)
+func auditLogWhere(filter models.AuditLogFilter) (string, []interface{}) {
+	where := ` WHERE 1=1`
+	var params []interface{}
+	add := func(condition string, value interface{}) {
+		params = append(params, value)
+		where += fmt.Sprintf(` AND `+condition, len(params))
+	}
+	if filter.ActorID != nil {
+		add(`actor_id = $%d`, *filter.ActorID)
+	}
+	if filter.ImpersonatorID != nil {
+		add(`impersonator_id = $%d`, *filter.ImpersonatorID)
+	}
+	if filter.Action != "" {
+		add(`action = $%d`, filter.Action)
+	}
+	if filter.TargetType != "" {
+		add(`target_type = $%d`, filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		add(`target_id = $%d`, filter.TargetID)
+	}
+	if filter.From != nil {
+		add(`created_at >= $%d`, *filter.From)
+	}
+	if filter.To != nil {
+		add(`created_at < $%d`, *filter.To)
+	}
+	return where, params
+}
+
+func scanAuditLogs(rows *sql.Rows) ([]models.AuditLog, error) {
+	var entries []models.AuditLog
+	for rows.Next() {
+		var e models.AuditLog
+		var targetType, targetID, reason, method, path, ipAddress, userAgent sql.NullString
+		var before, after []byte
+		var statusCode sql.NullInt64
+		if err := rows.Scan(
+			&e.ID, &e.ActorID, &e.ImpersonatorID, &e.Action, &targetType, &targetID, &reason, &before, &after,
+			&e.SessionID, &method, &path, &statusCode, &ipAddress, &userAgent, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		e.TargetType = targetType.String
+		e.TargetID = targetID.String
+		e.Reason = reason.String
+		e.Before = before
+		e.After = after
+		e.Method = method.String
+		e.Path = path.String
+		e.StatusCode = int(statusCode.Int64)
+		e.IPAddress = ipAddress.String
+		e.UserAgent = userAgent.String
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// nullJSON snapshot kosong disimpan sebagai NULL, bukan JSON invalid
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
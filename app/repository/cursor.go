@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"achievement-backend/app/models"
+
+	"github.com/google/uuid"
+)
+
+// keyset urutan (Key, ID) untuk pagination cursor. Key harus NOT NULL; kolom nullable
+// dibungkus COALESCE dengan nilai pengganti yang sama seperti key baris di cursor.
+// Urutan harus sama persis dengan index (Key, ID) tabelnya.
+type keyset struct {
+	Sort       string
+	Key        string
+	ID         string
+	Descending bool
+	// Time true untuk key TIMESTAMP: key cursor harus RFC3339 atau (-)infinity
+	Time bool
+}
+
+// sql kondisi WHERE (diawali AND) dan ORDER BY untuk halaman setelah / sebelum cursor.
+// Halaman mundur dibaca dengan urutan terbalik; keysetPage membaliknya lagi.
+func (k keyset) sql(cursor *models.Cursor, params []interface{}) (string, string, []interface{}, error) {
+	descending := k.Descending
+	where := ""
+	if cursor != nil {
+		if cursor.Sort != k.Sort || !k.validKey(cursor.Key) {
+			return "", "", nil, models.ErrInvalidCursor
+		}
+		if cursor.Backward {
+			descending = !descending
+		}
+		operator := ">"
+		if descending {
+			operator = "<"
+		}
+		params = append(params, cursor.Key, cursor.ID)
+		where = fmt.Sprintf(` AND (%s, %s) %s ($%d, $%d)`, k.Key, k.ID, operator, len(params)-1, len(params))
+	}
+
+	direction := " ASC"
+	if descending {
+		direction = " DESC"
+	}
+	return where, ` ORDER BY ` + k.Key + direction + `, ` + k.ID + direction, params, nil
+}
+
+// validKey menolak key cursor yang diubah client sebelum sampai ke Postgres
+func (k keyset) validKey(key string) bool {
+	if k.Time {
+		if key == "infinity" || key == "-infinity" {
+			return true
+		}
+		_, err := time.Parse(time.RFC3339Nano, key)
+		return err == nil
+	}
+	return utf8.ValidString(key) && !strings.ContainsRune(key, 0)
+}
+
+// keysetPage memotong baris ekstra (query mengambil limit+1), mengembalikan urutan halaman
+// mundur dan menyiapkan cursor next/prev dari baris pertama dan terakhir
+func keysetPage[T any](k keyset, rows []T, limit int, cursor *models.Cursor, key func(T) (string, uuid.UUID)) ([]T, models.CursorPage) {
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	var page models.CursorPage
+	if len(rows) == 0 {
+		return rows, page
+	}
+	if more || backward {
+		lastKey, lastID := key(rows[len(rows)-1])
+		page.Next = &models.Cursor{Sort: k.Sort, Key: lastKey, ID: lastID}
+	}
+	if (more && backward) || (cursor != nil && !backward) {
+		firstKey, firstID := key(rows[0])
+		page.Prev = &models.Cursor{Sort: k.Sort, Key: firstKey, ID: firstID, Backward: true}
+	}
+	return rows, page
+}
+
+// timeKey format key cursor untuk kolom TIMESTAMP (presisi mikrodetik tetap utuh)
+func timeKey(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
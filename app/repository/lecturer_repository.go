@@ -19,6 +19,8 @@ type LecturerRepository interface {
 	GetAll(page, limit int) ([]models.Lecturer, int, error)
 	GetTotalCount() (int, error)
 	GetWithUserDetails(page, limit int) ([]models.LecturerResponse, int, error)
+	// GetWithUserDetailsAfter versi cursor GetWithUserDetails (nama, lalu id), tanpa COUNT(*)
+	GetWithUserDetailsAfter(cursor *models.Cursor, limit int) ([]models.LecturerResponse, models.CursorPage, error)
 	
 	GetAdviseesCount(lecturerID uuid.UUID) (int, error)
 	GetAdvisees(lecturerID uuid.UUID, page, limit int) ([]models.Student, int, error)
@@ -83,11 +85,13 @@ func (r *lecturerRepo) GetByLecturerID(lecturerID string) (*models.Lecturer, err
 	return &l, nil
 }
 
+const insertLecturerQuery = `
+	INSERT INTO lecturers (id, user_id, lecturer_id, department, created_at)
+	VALUES ($1, $2, $3, $4, NOW())
+`
+
 func (r *lecturerRepo) Create(lecturer models.Lecturer) (uuid.UUID, error) {
-	_, err := r.DB.Exec(`
-		INSERT INTO lecturers (id, user_id, lecturer_id, department, created_at)
-		VALUES ($1, $2, $3, $4, NOW())
-	`, 
+	_, err := r.DB.Exec(insertLecturerQuery, 
 		lecturer.ID,         
 		lecturer.UserID,     
 		lecturer.LecturerID, 
@@ -230,6 +234,53 @@ func (r *lecturerRepo) GetWithUserDetails(page, limit int) ([]models.LecturerRes
 	return lecturers, total, nil
 }
 
+// lecturersKeyset urut nama lalu id user (1 user 1 dosen) supaya memakai index users (full_name, id)
+var lecturersKeyset = keyset{Sort: "lecturers:name", Key: "u.full_name", ID: "u.id"}
+
+func (r *lecturerRepo) GetWithUserDetailsAfter(cursor *models.Cursor, limit int) ([]models.LecturerResponse, models.CursorPage, error) {
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	where, orderBy, params, err := lecturersKeyset.sql(cursor, nil)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	params = append(params, limit+1)
+
+	rows, err := r.DB.Query(`
+		SELECT l.id, l.user_id, u.full_name, u.email, u.username,
+		       l.lecturer_id, l.department, l.created_at
+		FROM lecturers l
+		JOIN users u ON l.user_id = u.id
+		WHERE u.is_active = true`+where+orderBy+fmt.Sprintf(`
+		LIMIT $%d`, len(params)), params...)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	defer rows.Close()
+
+	var lecturers []models.LecturerResponse
+	for rows.Next() {
+		var l models.LecturerResponse
+		if err := rows.Scan(
+			&l.ID, &l.UserID, &l.FullName, &l.Email, &l.Username,
+			&l.LecturerID, &l.Department, &l.CreatedAt,
+		); err != nil {
+			return nil, models.CursorPage{}, err
+		}
+		lecturers = append(lecturers, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.CursorPage{}, err
+	}
+
+	lecturers, page := keysetPage(lecturersKeyset, lecturers, limit, cursor, func(l models.LecturerResponse) (string, uuid.UUID) {
+		return l.FullName, l.UserID
+	})
+	return lecturers, page, nil
+}
+
 func (r *lecturerRepo) GetAdviseesCount(lecturerID uuid.UUID) (int, error) {
 	var count int
 	err := r.DB.QueryRow(`
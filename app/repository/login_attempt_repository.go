@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"achievement-backend/app/models"
+	"github.com/google/uuid"
+)
+
+type LoginAttemptRepository interface {
+	Record(attempt *models.LoginAttempt) error
+	// CountRecentFailuresByIP jumlah login gagal dari IP sejak waktu tertentu beserta waktu gagal terakhir
+	CountRecentFailuresByIP(ip string, since time.Time) (int, *time.Time, error)
+	GetLockout(userID uuid.UUID) (*models.AccountLockout, error)
+	// RegisterFailure menambah failed_count secara atomik dan mengembalikan state terbaru.
+	// Hitungan mulai lagi dari 1 jika kunci sebelumnya sudah lewat atau gagal terakhir sebelum since.
+	RegisterFailure(userID uuid.UUID, at, since time.Time) (*models.AccountLockout, error)
+	Lock(userID uuid.UUID, until time.Time) error
+	Reset(userID uuid.UUID) error
+	RecordEvent(event *models.LockoutEvent) error
+}
+
+type loginAttemptRepo struct {
+	DB *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) LoginAttemptRepository {
+	return &loginAttemptRepo{DB: db}
+}
+
+func (r *loginAttemptRepo) Record(attempt *models.LoginAttempt) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO login_attempts (id, identifier, user_id, ip_address, succeeded, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		attempt.ID,
+		attempt.Identifier,
+		attempt.UserID,
+		attempt.IPAddress,
+		attempt.Succeeded,
+		attempt.CreatedAt,
+	)
+	return err
+}
+
+func (r *loginAttemptRepo) CountRecentFailuresByIP(ip string, since time.Time) (int, *time.Time, error) {
+	var count int
+	var last sql.NullTime
+	err := r.DB.QueryRow(`
+		SELECT COUNT(*), MAX(created_at)
+		FROM login_attempts
+		WHERE ip_address=$1 AND succeeded=false AND created_at >= $2
+	`, ip, since).Scan(&count, &last)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !last.Valid {
+		return count, nil, nil
+	}
+	return count, &last.Time, nil
+}
+
+func (r *loginAttemptRepo) GetLockout(userID uuid.UUID) (*models.AccountLockout, error) {
+	var l models.AccountLockout
+	err := r.DB.QueryRow(`
+		SELECT user_id, failed_count, last_failed_at, locked_until, updated_at
+		FROM account_lockouts
+		WHERE user_id=$1
+	`, userID).Scan(&l.UserID, &l.FailedCount, &l.LastFailedAt, &l.LockedUntil, &l.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *loginAttemptRepo) RegisterFailure(userID uuid.UUID, at, since time.Time) (*models.AccountLockout, error) {
+	var l models.AccountLockout
+	err := r.DB.QueryRow(`
+		INSERT INTO account_lockouts (user_id, failed_count, last_failed_at, updated_at)
+		VALUES ($1, 1, $2, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET failed_count = CASE
+		        WHEN account_lockouts.locked_until <= EXCLUDED.last_failed_at
+		          OR account_lockouts.last_failed_at IS NULL
+		          OR account_lockouts.last_failed_at < $3 THEN 1
+		        ELSE account_lockouts.failed_count + 1
+		    END,
+		    locked_until = CASE
+		        WHEN account_lockouts.locked_until <= EXCLUDED.last_failed_at THEN NULL
+		        ELSE account_lockouts.locked_until
+		    END,
+		    last_failed_at = EXCLUDED.last_failed_at,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING user_id, failed_count, last_failed_at, locked_until, updated_at
+	`, userID, at, since).Scan(&l.UserID, &l.FailedCount, &l.LastFailedAt, &l.LockedUntil, &l.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *loginAttemptRepo) Lock(userID uuid.UUID, until time.Time) error {
+	_, err := r.DB.Exec(`
+		UPDATE account_lockouts
+		SET locked_until=$1, updated_at=$2
+		WHERE user_id=$3
+	`, until, time.Now(), userID)
+	return err
+}
+
+func (r *loginAttemptRepo) Reset(userID uuid.UUID) error {
+	_, err := r.DB.Exec(`DELETE FROM account_lockouts WHERE user_id=$1`, userID)
+	return err
+}
+
+func (r *loginAttemptRepo) RecordEvent(event *models.LockoutEvent) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO account_lockout_events (id, user_id, event, ip_address, actor_id, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		event.ID,
+		event.UserID,
+		event.Event,
+		event.IPAddress,
+		event.ActorID,
+		event.Reason,
+		event.CreatedAt,
+	)
+	return err
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"achievement-backend/app/models"
+	"github.com/google/uuid"
+)
+
+type OIDCRepository interface {
+	// CreateState menyimpan login baru sekaligus membersihkan state yang sudah kadaluarsa
+	CreateState(state *models.OIDCLoginState) error
+	// ConsumeState menghapus state secara atomik; nil jika tidak ada atau kadaluarsa
+	ConsumeState(stateHash string) (*models.OIDCLoginState, error)
+	GetIdentity(issuer, subject string) (*models.UserIdentity, error)
+	CreateIdentity(identity *models.UserIdentity) error
+	TouchIdentity(id uuid.UUID, email string, at time.Time) error
+}
+
+type oidcRepo struct {
+	DB *sql.DB
+}
+
+func NewOIDCRepository(db *sql.DB) OIDCRepository {
+	return &oidcRepo{DB: db}
+}
+
+func (r *oidcRepo) CreateState(state *models.OIDCLoginState) error {
+	if _, err := r.DB.Exec(`DELETE FROM oidc_login_states WHERE expires_at <= $1`, state.CreatedAt); err != nil {
+		return err
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO oidc_login_states (state_hash, code_verifier, nonce, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`,
+		state.StateHash,
+		state.CodeVerifier,
+		state.Nonce,
+		state.ExpiresAt,
+		state.CreatedAt,
+	)
+	return err
+}
+
+func (r *oidcRepo) ConsumeState(stateHash string) (*models.OIDCLoginState, error) {
+	var s models.OIDCLoginState
+	err := r.DB.QueryRow(`
+		DELETE FROM oidc_login_states
+		WHERE state_hash=$1
+		RETURNING state_hash, code_verifier, nonce, expires_at, created_at
+	`, stateHash).Scan(&s.StateHash, &s.CodeVerifier, &s.Nonce, &s.ExpiresAt, &s.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !time.Now().Before(s.ExpiresAt) {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (r *oidcRepo) GetIdentity(issuer, subject string) (*models.UserIdentity, error) {
+	var i models.UserIdentity
+	var email sql.NullString
+	err := r.DB.QueryRow(`
+		SELECT id, user_id, issuer, subject, email, created_at, last_login_at
+		FROM user_identities
+		WHERE issuer=$1 AND subject=$2
+	`, issuer, subject).Scan(&i.ID, &i.UserID, &i.Issuer, &i.Subject, &email, &i.CreatedAt, &i.LastLoginAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	i.Email = email.String
+	return &i, nil
+}
+
+func (r *oidcRepo) CreateIdentity(identity *models.UserIdentity) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO user_identities (id, user_id, issuer, subject, email, created_at, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		identity.ID,
+		identity.UserID,
+		identity.Issuer,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+		identity.LastLoginAt,
+	)
+	return err
+}
+
+func (r *oidcRepo) TouchIdentity(id uuid.UUID, email string, at time.Time) error {
+	_, err := r.DB.Exec(`
+		UPDATE user_identities SET email=$1, last_login_at=$2 WHERE id=$3
+	`, email, at, id)
+	return err
+}
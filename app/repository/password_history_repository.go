@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+
+	"achievement-backend/app/models"
+	"github.com/google/uuid"
+)
+
+type PasswordHistoryRepository interface {
+	// GetRecentHashes hash password terbaru user, paling baru di depan
+	GetRecentHashes(userID uuid.UUID, limit int) ([]string, error)
+	// Add menyimpan hash baru dan membuang entri selain keep terbaru
+	Add(entry *models.PasswordHistory, keep int) error
+}
+
+type passwordHistoryRepo struct {
+	DB *sql.DB
+}
+
+func NewPasswordHistoryRepository(db *sql.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepo{DB: db}
+}
+
+func (r *passwordHistoryRepo) GetRecentHashes(userID uuid.UUID, limit int) ([]string, error) {
+	rows, err := r.DB.Query(`
+		SELECT password_hash
+		FROM password_history
+		WHERE user_id=$1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, rows.Err()
+}
+
+func (r *passwordHistoryRepo) Add(entry *models.PasswordHistory, keep int) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO password_history (id, user_id, password_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, entry.ID, entry.UserID, entry.PasswordHash, entry.CreatedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM password_history
+		WHERE user_id=$1 AND id NOT IN (
+			SELECT id FROM password_history
+			WHERE user_id=$1
+			ORDER BY created_at DESC
+			LIMIT $2
+		)
+	`, entry.UserID, keep); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
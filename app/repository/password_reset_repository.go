@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"achievement-backend/app/models"
+	"github.com/google/uuid"
+)
+
+type PasswordResetRepository interface {
+	// Create menyimpan token baru dan membatalkan token lama user yang belum dipakai
+	Create(token *models.PasswordResetToken) error
+	// Consume menandai token terpakai secara atomik; nil jika token tidak ada, kadaluarsa, atau sudah dipakai
+	Consume(tokenHash string) (*models.PasswordResetToken, error)
+	// GetValidByHash token yang belum dipakai dan belum kadaluarsa, tanpa menandainya terpakai
+	GetValidByHash(tokenHash string) (*models.PasswordResetToken, error)
+	GetLatestByUser(userID uuid.UUID) (*models.PasswordResetToken, error)
+}
+
+type passwordResetRepo struct {
+	DB *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) PasswordResetRepository {
+	return &passwordResetRepo{DB: db}
+}
+
+func (r *passwordResetRepo) Create(token *models.PasswordResetToken) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE password_reset_tokens
+		SET used_at=$1
+		WHERE user_id=$2 AND used_at IS NULL
+	`, token.CreatedAt, token.UserID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, requested_ip, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.RequestedIP,
+		token.ExpiresAt,
+		token.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *passwordResetRepo) Consume(tokenHash string) (*models.PasswordResetToken, error) {
+	now := time.Now()
+	t, err := scanPasswordResetToken(r.DB.QueryRow(`
+		UPDATE password_reset_tokens
+		SET used_at=$1
+		WHERE token_hash=$2 AND used_at IS NULL AND expires_at > $1
+		RETURNING id, user_id, token_hash, requested_ip, expires_at, used_at, created_at
+	`, now, tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *passwordResetRepo) GetValidByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	t, err := scanPasswordResetToken(r.DB.QueryRow(`
+		SELECT id, user_id, token_hash, requested_ip, expires_at, used_at, created_at
+		FROM password_reset_tokens
+		WHERE token_hash=$1 AND used_at IS NULL AND expires_at > $2
+	`, tokenHash, time.Now()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *passwordResetRepo) GetLatestByUser(userID uuid.UUID) (*models.PasswordResetToken, error) {
+	t, err := scanPasswordResetToken(r.DB.QueryRow(`
+		SELECT id, user_id, token_hash, requested_ip, expires_at, used_at, created_at
+		FROM password_reset_tokens
+		WHERE user_id=$1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func scanPasswordResetToken(row *sql.Row) (*models.PasswordResetToken, error) {
+	var t models.PasswordResetToken
+	var requestedIP sql.NullString
+	err := row.Scan(&t.ID, &t.UserID, &t.TokenHash, &requestedIP, &t.ExpiresAt, &t.UsedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.RequestedIP = requestedIP.String
+	return &t, nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"achievement-backend/app/models"
+
+	"github.com/google/uuid"
+)
+
+type ReconciliationReportRepository interface {
+	Create(report *models.ReconciliationReport) error
+	// List ringkasan terbaru lebih dulu, tanpa daftar issue
+	List(page, limit int) ([]models.ReconciliationReport, int, error)
+	GetByID(id uuid.UUID) (*models.ReconciliationReport, error)
+	// TryLock advisory lock satu run rekonsiliasi; ok false jika instance lain sedang menjalankannya
+	TryLock(ctx context.Context) (unlock func(), ok bool, err error)
+}
+
+// reconcileLockID kunci advisory Postgres supaya rekonsiliasi tidak berjalan di dua instance sekaligus
+const reconcileLockID = 725104332
+
+type reconciliationReportRepo struct {
+	DB *sql.DB
+}
+
+func NewReconciliationReportRepository(db *sql.DB) ReconciliationReportRepository {
+	return &reconciliationReportRepo{DB: db}
+}
+
+func (r *reconciliationReportRepo) Create(report *models.ReconciliationReport) error {
+	counts, err := json.Marshal(report.Counts)
+	if err != nil {
+		return err
+	}
+	issues := report.Issues
+	if issues == nil {
+		issues = []models.ReconciliationIssue{}
+	}
+	issuesJSON, err := json.Marshal(issues)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.Exec(`
+		INSERT INTO achievement_reconciliation_reports (
+			id, trigger, repair, quarantine, references_scanned, documents_scanned,
+			issue_count, counts, issues, error, started_at, finished_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`,
+		report.ID,
+		report.Trigger,
+		report.Repair,
+		report.Quarantine,
+		report.ReferencesScanned,
+		report.DocumentsScanned,
+		report.IssueCount,
+		string(counts),
+		string(issuesJSON),
+		report.Error,
+		report.StartedAt,
+		report.FinishedAt,
+	)
+	return err
+}
+
+func (r *reconciliationReportRepo) List(page, limit int) ([]models.ReconciliationReport, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	var total int
+	if err := r.DB.QueryRow(`SELECT COUNT(*) FROM achievement_reconciliation_reports`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT id, trigger, repair, quarantine, references_scanned, documents_scanned,
+		       issue_count, counts, error, started_at, finished_at
+		FROM achievement_reconciliation_reports
+		ORDER BY started_at DESC
+		LIMIT $1 OFFSET $2
+	`, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reports := []models.ReconciliationReport{}
+	for rows.Next() {
+		var report models.ReconciliationReport
+		var counts []byte
+		if err := rows.Scan(
+			&report.ID,
+			&report.Trigger,
+			&report.Repair,
+			&report.Quarantine,
+			&report.ReferencesScanned,
+			&report.DocumentsScanned,
+			&report.IssueCount,
+			&counts,
+			&report.Error,
+			&report.StartedAt,
+			&report.FinishedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		if err := json.Unmarshal(counts, &report.Counts); err != nil {
+			return nil, 0, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, total, rows.Err()
+}
+
+func (r *reconciliationReportRepo) GetByID(id uuid.UUID) (*models.ReconciliationReport, error) {
+	var report models.ReconciliationReport
+	var counts, issues []byte
+	err := r.DB.QueryRow(`
+		SELECT id, trigger, repair, quarantine, references_scanned, documents_scanned,
+		       issue_count, counts, issues, error, started_at, finished_at
+		FROM achievement_reconciliation_reports
+		WHERE id = $1
+	`, id).Scan(
+		&report.ID,
+		&report.Trigger,
+		&report.Repair,
+		&report.Quarantine,
+		&report.ReferencesScanned,
+		&report.DocumentsScanned,
+		&report.IssueCount,
+		&counts,
+		&issues,
+		&report.Error,
+		&report.StartedAt,
+		&report.FinishedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(counts, &report.Counts); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(issues, &report.Issues); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// TryLock memegang koneksi sendiri karena advisory lock terikat pada session Postgres
+func (r *reconciliationReportRepo) TryLock(ctx context.Context) (func(), bool, error) {
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, reconcileLockID).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, reconcileLockID)
+		conn.Close()
+	}
+	return unlock, true, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"achievement-backend/app/models"
+	"github.com/google/uuid"
+)
+
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	GetByID(id uuid.UUID) (*models.RefreshToken, error)
+	// Rotate menandai token lama terpakai dan menyimpan penggantinya dalam satu transaksi.
+	// Return false jika token lama sudah terpakai/di-revoke (indikasi reuse).
+	Rotate(oldID uuid.UUID, next *models.RefreshToken) (bool, error)
+	Revoke(id uuid.UUID) error
+}
+
+type refreshTokenRepo struct {
+	DB *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepo{DB: db}
+}
+
+func (r *refreshTokenRepo) Create(token *models.RefreshToken) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO refresh_tokens (id, user_id, family_id, parent_id, device_info,
+		                            ip_address, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		token.ID,
+		token.UserID,
+		token.FamilyID,
+		token.ParentID,
+		token.DeviceInfo,
+		token.IPAddress,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	return err
+}
+
+func (r *refreshTokenRepo) GetByID(id uuid.UUID) (*models.RefreshToken, error) {
+	var t models.RefreshToken
+	var deviceInfo, ipAddress sql.NullString
+	err := r.DB.QueryRow(`
+		SELECT id, user_id, family_id, parent_id, device_info, ip_address,
+		       expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE id=$1
+	`, id).Scan(
+		&t.ID, &t.UserID, &t.FamilyID, &t.ParentID, &deviceInfo, &ipAddress,
+		&t.ExpiresAt, &t.UsedAt, &t.RevokedAt, &t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	t.DeviceInfo = deviceInfo.String
+	t.IPAddress = ipAddress.String
+	return &t, nil
+}
+
+func (r *refreshTokenRepo) Rotate(oldID uuid.UUID, next *models.RefreshToken) (bool, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE refresh_tokens
+		SET used_at=$1
+		WHERE id=$2 AND used_at IS NULL AND revoked_at IS NULL
+	`, time.Now(), oldID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO refresh_tokens (id, user_id, family_id, parent_id, device_info,
+		                            ip_address, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		next.ID,
+		next.UserID,
+		next.FamilyID,
+		next.ParentID,
+		next.DeviceInfo,
+		next.IPAddress,
+		next.ExpiresAt,
+		next.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *refreshTokenRepo) Revoke(id uuid.UUID) error {
+	_, err := r.DB.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at=$1
+		WHERE id=$2 AND revoked_at IS NULL
+	`, time.Now(), id)
+	return err
+}
@@ -14,7 +14,7 @@ import (
 )
 
 type ReportRepository interface {
-	GetAchievementStats(ctx context.Context, actorID uuid.UUID, roleName string, startDate, endDate *time.Time) (*models.AchievementStats, error)
+	GetAchievementStats(ctx context.Context, scope models.AchievementScope, startDate, endDate *time.Time) (*models.AchievementStats, error)
 }
 
 type reportRepo struct{}
@@ -23,7 +23,7 @@ func NewReportRepository() ReportRepository {
 	return &reportRepo{}
 }
 
-func (r *reportRepo) GetAchievementStats(ctx context.Context, actorID uuid.UUID, roleName string, startDate, endDate *time.Time) (*models.AchievementStats, error) {
+func (r *reportRepo) GetAchievementStats(ctx context.Context, scope models.AchievementScope, startDate, endDate *time.Time) (*models.AchievementStats, error) {
 	stats := &models.AchievementStats{
 		ByType:             make(map[string]int),
 		ByPeriod:           make(map[string]int),
@@ -34,20 +34,20 @@ func (r *reportRepo) GetAchievementStats(ctx context.Context, actorID uuid.UUID,
 	var whereClause string
 	var queryParams []interface{}
 
-	switch roleName {
-	case "Admin":
+	switch scope.Relation {
+	case models.RelationGlobal:
 		whereClause = "WHERE ar.status != 'deleted'"
-	case "Dosen Wali":
+	case models.RelationAdvisor:
 		whereClause = `
 			WHERE ar.status != 'deleted' 
 			AND ar.student_id IN (
 				SELECT id FROM students WHERE advisor_id = $1
 			)
 		`
-		queryParams = append(queryParams, actorID)
-	case "Mahasiswa":
+		queryParams = append(queryParams, scope.ID)
+	case models.RelationOwner:
 		whereClause = "WHERE ar.status != 'deleted' AND ar.student_id = $1"
-		queryParams = append(queryParams, actorID)
+		queryParams = append(queryParams, scope.ID)
 	}
 
 	// Add date filter
@@ -108,6 +108,7 @@ func (r *reportRepo) GetAchievementStats(ctx context.Context, actorID uuid.UUID,
 	typePipeline := bson.A{
 		bson.D{{Key: "$match", Value: bson.D{
 			{Key: "studentId", Value: bson.D{{Key: "$in", Value: studentIDStrings}}},
+			{Key: "deletedAt", Value: bson.D{{Key: "$exists", Value: false}}},
 		}}},
 		bson.D{{Key: "$group", Value: bson.D{
 			{Key: "_id", Value: "$achievementType"},
@@ -134,6 +135,7 @@ func (r *reportRepo) GetAchievementStats(ctx context.Context, actorID uuid.UUID,
 		bson.D{{Key: "$match", Value: bson.D{
 			{Key: "studentId", Value: bson.D{{Key: "$in", Value: studentIDStrings}}},
 			{Key: "achievementType", Value: "competition"},
+			{Key: "deletedAt", Value: bson.D{{Key: "$exists", Value: false}}},
 		}}},
 		bson.D{{Key: "$group", Value: bson.D{
 			{Key: "_id", Value: "$details.competitionLevel"},
@@ -155,8 +157,8 @@ func (r *reportRepo) GetAchievementStats(ctx context.Context, actorID uuid.UUID,
 		}
 	}
 
-	// 5. Top mahasiswa berprestasi (Admin & Dosen Wali only) - FIXED!
-	if roleName == "Admin" || roleName == "Dosen Wali" {
+	// 5. Top mahasiswa berprestasi (akses global & dosen wali only) - FIXED!
+	if scope.Relation == models.RelationGlobal || scope.Relation == models.RelationAdvisor {
 		// Query sederhana: hitung prestasi verified per student
 		topStudentsQuery := `
 			SELECT 
@@ -171,7 +173,7 @@ func (r *reportRepo) GetAchievementStats(ctx context.Context, actorID uuid.UUID,
 		`
 
 		// Add filter untuk Dosen Wali
-		if roleName == "Dosen Wali" {
+		if scope.Relation == models.RelationAdvisor {
 			topStudentsQuery += ` AND s.advisor_id = $1`
 		}
 
@@ -183,8 +185,8 @@ func (r *reportRepo) GetAchievementStats(ctx context.Context, actorID uuid.UUID,
 		`
 
 		var rows *sql.Rows
-		if roleName == "Dosen Wali" {
-			rows, _ = database.PgDB.QueryContext(ctx, topStudentsQuery, actorID)
+		if scope.Relation == models.RelationAdvisor {
+			rows, _ = database.PgDB.QueryContext(ctx, topStudentsQuery, scope.ID)
 		} else {
 			rows, _ = database.PgDB.QueryContext(ctx, topStudentsQuery)
 		}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"achievement-backend/app/models"
+
+	"github.com/google/uuid"
+)
+
+// PermissionCacheTTL batas umur cache, jaga-jaga kalau role_permissions diubah langsung di database
+const PermissionCacheTTL = 5 * time.Minute
+
+type cachedPermissions struct {
+	names    []string
+	loadedAt time.Time
+}
+
+// cachedRoleRepo membungkus RoleRepository dengan cache nama permission per role.
+// Cache di-invalidate setiap ada perubahan role_permissions lewat repository ini.
+type cachedRoleRepo struct {
+	RoleRepository
+
+	mu          sync.RWMutex
+	permissions map[uuid.UUID]cachedPermissions
+	// generation naik setiap invalidasi, supaya hasil query lama tidak menimpa cache yang baru di-reset
+	generation uint64
+}
+
+func NewCachedRoleRepository(inner RoleRepository) RoleRepository {
+	return &cachedRoleRepo{
+		RoleRepository: inner,
+		permissions:    make(map[uuid.UUID]cachedPermissions),
+	}
+}
+
+func (r *cachedRoleRepo) GetPermissionNamesByRoleID(roleID uuid.UUID) ([]string, error) {
+	r.mu.RLock()
+	cached, ok := r.permissions[roleID]
+	generation := r.generation
+	r.mu.RUnlock()
+
+	if ok && time.Since(cached.loadedAt) < PermissionCacheTTL {
+		return cached.names, nil
+	}
+
+	names, err := r.RoleRepository.GetPermissionNamesByRoleID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.generation == generation {
+		r.permissions[roleID] = cachedPermissions{names: names, loadedAt: time.Now()}
+	}
+	r.mu.Unlock()
+
+	return names, nil
+}
+
+func (r *cachedRoleRepo) AssignPermission(roleID, permissionID uuid.UUID, audit *models.AuditLog) error {
+	defer r.invalidate(roleID)
+	return r.RoleRepository.AssignPermission(roleID, permissionID, audit)
+}
+
+func (r *cachedRoleRepo) RemovePermission(roleID, permissionID uuid.UUID, audit *models.AuditLog) error {
+	defer r.invalidate(roleID)
+	return r.RoleRepository.RemovePermission(roleID, permissionID, audit)
+}
+
+func (r *cachedRoleRepo) Delete(id uuid.UUID, audit *models.AuditLog) error {
+	defer r.invalidate(id)
+	return r.RoleRepository.Delete(id, audit)
+}
+
+func (r *cachedRoleRepo) invalidate(roleID uuid.UUID) {
+	r.mu.Lock()
+	delete(r.permissions, roleID)
+	r.generation++
+	r.mu.Unlock()
+}
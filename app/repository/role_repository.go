@@ -16,8 +16,17 @@ type RoleRepository interface {
 	GetTotalCount() (int, error)
 	GetPermissionsByRoleID(roleID uuid.UUID) ([]models.Permission, error)
 	GetPermissionNamesByRoleID(roleID uuid.UUID) ([]string, error)
-	AssignPermission(roleID, permissionID uuid.UUID) error
-	RemovePermission(roleID, permissionID uuid.UUID) error
+	// Mutasi role/permission menulis audit (boleh nil) dalam transaksi yang sama dengan perubahannya
+	AssignPermission(roleID, permissionID uuid.UUID, audit *models.AuditLog) error
+	RemovePermission(roleID, permissionID uuid.UUID, audit *models.AuditLog) error
+	Create(role *models.Role, audit *models.AuditLog) error
+	Delete(id uuid.UUID, audit *models.AuditLog) error
+	GetAllPermissions() ([]models.Permission, error)
+	GetPermissionByID(id uuid.UUID) (*models.Permission, error)
+	GetPermissionByName(name string) (*models.Permission, error)
+	CreatePermission(permission *models.Permission, audit *models.AuditLog) error
+	DeletePermission(id uuid.UUID, audit *models.AuditLog) error
+	SetTwoFactorRequired(id uuid.UUID, required bool, audit *models.AuditLog) error
 }
 
 type roleRepo struct {
@@ -31,10 +40,10 @@ func NewRoleRepository(db *sql.DB) RoleRepository {
 func (r *roleRepo) GetByID(id uuid.UUID) (*models.Role, error) {
 	var role models.Role
 	err := r.DB.QueryRow(`
-		SELECT id, name, description, created_at
+		SELECT id, name, description, require_two_factor, created_at
 		FROM roles
 		WHERE id=$1
-	`, id).Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt)
+	`, id).Scan(&role.ID, &role.Name, &role.Description, &role.RequireTwoFactor, &role.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -47,10 +56,10 @@ func (r *roleRepo) GetByID(id uuid.UUID) (*models.Role, error) {
 func (r *roleRepo) GetByName(name string) (*models.Role, error) {
 	var role models.Role
 	err := r.DB.QueryRow(`
-		SELECT id, name, description, created_at
+		SELECT id, name, description, require_two_factor, created_at
 		FROM roles
 		WHERE name=$1
-	`, name).Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt)
+	`, name).Scan(&role.ID, &role.Name, &role.Description, &role.RequireTwoFactor, &role.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -70,7 +79,7 @@ func (r *roleRepo) GetAll(page, limit int) ([]models.Role, int, error) {
 	offset := (page - 1) * limit
 
 	rows, err := r.DB.Query(`
-		SELECT id, name, description, created_at
+		SELECT id, name, description, require_two_factor, created_at
 		FROM roles
 		ORDER BY name
 		LIMIT $1 OFFSET $2
@@ -83,7 +92,7 @@ func (r *roleRepo) GetAll(page, limit int) ([]models.Role, int, error) {
 	var roles []models.Role
 	for rows.Next() {
 		var role models.Role
-		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt); err != nil {
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.RequireTwoFactor, &role.CreatedAt); err != nil {
 			return nil, 0, err
 		}
 		roles = append(roles, role)
@@ -154,9 +163,15 @@ func (r *roleRepo) GetPermissionNamesByRoleID(roleID uuid.UUID) ([]string, error
 	return permissions, nil
 }
 
-func (r *roleRepo) AssignPermission(roleID, permissionID uuid.UUID) error {
+func (r *roleRepo) AssignPermission(roleID, permissionID uuid.UUID, audit *models.AuditLog) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	var exists bool
-	err := r.DB.QueryRow(`
+	err = tx.QueryRow(`
 		SELECT EXISTS(
 			SELECT 1 FROM role_permissions 
 			WHERE role_id=$1 AND permission_id=$2
@@ -168,29 +183,29 @@ func (r *roleRepo) AssignPermission(roleID, permissionID uuid.UUID) error {
 	}
 	
 	if exists {
-		return fmt.Errorf("permission already assigned to role")
+		return models.ErrPermissionAlreadyAssigned
 	}
 	
 	var roleExists, permExists bool
-	err = r.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM roles WHERE id=$1)`, roleID).Scan(&roleExists)
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM roles WHERE id=$1)`, roleID).Scan(&roleExists)
 	if err != nil {
 		return fmt.Errorf("error checking role: %w", err)
 	}
 	
 	if !roleExists {
-		return fmt.Errorf("role not found")
+		return models.ErrRoleNotFound
 	}
 	
-	err = r.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM permissions WHERE id=$1)`, permissionID).Scan(&permExists)
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM permissions WHERE id=$1)`, permissionID).Scan(&permExists)
 	if err != nil {
 		return fmt.Errorf("error checking permission: %w", err)
 	}
 	
 	if !permExists {
-		return fmt.Errorf("permission not found")
+		return models.ErrPermissionNotFound
 	}
 	
-	_, err = r.DB.Exec(`
+	_, err = tx.Exec(`
 		INSERT INTO role_permissions (role_id, permission_id)
 		VALUES ($1, $2)
 	`, roleID, permissionID)
@@ -198,12 +213,41 @@ func (r *roleRepo) AssignPermission(roleID, permissionID uuid.UUID) error {
 	if err != nil {
 		return fmt.Errorf("error assigning permission: %w", err)
 	}
+
+	if err := insertAuditLog(tx, audit); err != nil {
+		return err
+	}
 	
-	return nil
+	return tx.Commit()
 }
 
-func (r *roleRepo) RemovePermission(roleID, permissionID uuid.UUID) error {
-	result, err := r.DB.Exec(`
+func (r *roleRepo) RemovePermission(roleID, permissionID uuid.UUID, audit *models.AuditLog) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockRolePermissions(tx); err != nil {
+		return err
+	}
+
+	var permName string
+	err = tx.QueryRow(`SELECT name FROM permissions WHERE id=$1`, permissionID).Scan(&permName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrPermissionNotFound
+		}
+		return fmt.Errorf("error checking permission: %w", err)
+	}
+
+	if permName == models.PermissionUserManage {
+		if err := ensureOtherUserManager(tx, roleID); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec(`
 		DELETE FROM role_permissions 
 		WHERE role_id=$1 AND permission_id=$2
 	`, roleID, permissionID)
@@ -218,9 +262,261 @@ func (r *roleRepo) RemovePermission(roleID, permissionID uuid.UUID) error {
 	}
 	
 	if rowsAffected == 0 {
-		return fmt.Errorf("permission not found for this role")
+		return models.ErrPermissionNotAssigned
+	}
+
+	if err := insertAuditLog(tx, audit); err != nil {
+		return err
 	}
 	
-	return nil
+	return tx.Commit()
+}
+
+func (r *roleRepo) Create(role *models.Role, audit *models.AuditLog) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO roles (id, name, description, require_two_factor, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, role.ID, role.Name, role.Description, role.RequireTwoFactor, role.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertAuditLog(tx, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
+func (r *roleRepo) SetTwoFactorRequired(id uuid.UUID, required bool, audit *models.AuditLog) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE roles SET require_two_factor=$1 WHERE id=$2`, required, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrRoleNotFound
+	}
+
+	if err := insertAuditLog(tx, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *roleRepo) Delete(id uuid.UUID, audit *models.AuditLog) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockRolePermissions(tx); err != nil {
+		return err
+	}
+
+	var roleExists bool
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM roles WHERE id=$1)`, id).Scan(&roleExists)
+	if err != nil {
+		return fmt.Errorf("error checking role: %w", err)
+	}
+	if !roleExists {
+		return models.ErrRoleNotFound
+	}
+
+	var userCount int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM users WHERE role_id=$1`, id).Scan(&userCount)
+	if err != nil {
+		return fmt.Errorf("error checking role users: %w", err)
+	}
+	if userCount > 0 {
+		return models.ErrRoleInUse
+	}
+
+	var holdsUserManage bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM role_permissions rp
+			JOIN permissions p ON p.id = rp.permission_id
+			WHERE rp.role_id=$1 AND p.name=$2
+		)
+	`, id, models.PermissionUserManage).Scan(&holdsUserManage)
+	if err != nil {
+		return fmt.Errorf("error checking role permissions: %w", err)
+	}
+	if holdsUserManage {
+		if err := ensureOtherUserManager(tx, id); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role_id=$1`, id); err != nil {
+		return fmt.Errorf("error removing role permissions: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM roles WHERE id=$1`, id); err != nil {
+		return fmt.Errorf("error deleting role: %w", err)
+	}
+
+	if err := insertAuditLog(tx, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *roleRepo) GetAllPermissions() ([]models.Permission, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, name, resource, action, description
+		FROM permissions
+		ORDER BY resource, action
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []models.Permission
+	for rows.Next() {
+		var p models.Permission
+		var description sql.NullString
+		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &description); err != nil {
+			return nil, err
+		}
+		p.Description = description.String
+		permissions = append(permissions, p)
+	}
+	return permissions, nil
+}
+
+func (r *roleRepo) GetPermissionByID(id uuid.UUID) (*models.Permission, error) {
+	return r.getPermission(`WHERE id=$1`, id)
+}
+
+func (r *roleRepo) GetPermissionByName(name string) (*models.Permission, error) {
+	return r.getPermission(`WHERE name=$1`, name)
+}
+
+func (r *roleRepo) getPermission(where string, arg interface{}) (*models.Permission, error) {
+	var p models.Permission
+	var description sql.NullString
+	err := r.DB.QueryRow(`
+		SELECT id, name, resource, action, description
+		FROM permissions
+		`+where, arg).Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &description)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	p.Description = description.String
+	return &p, nil
+}
+
+func (r *roleRepo) CreatePermission(permission *models.Permission, audit *models.AuditLog) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO permissions (id, name, resource, action, description)
+		VALUES ($1, $2, $3, $4, $5)
+	`, permission.ID, permission.Name, permission.Resource, permission.Action, permission.Description)
+	if err != nil {
+		return err
+	}
+
+	if err := insertAuditLog(tx, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *roleRepo) DeletePermission(id uuid.UUID, audit *models.AuditLog) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockRolePermissions(tx); err != nil {
+		return err
+	}
+
+	var permName string
+	err = tx.QueryRow(`SELECT name FROM permissions WHERE id=$1`, id).Scan(&permName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ErrPermissionNotFound
+		}
+		return fmt.Errorf("error checking permission: %w", err)
+	}
+
+	if permName == models.PermissionUserManage {
+		return models.ErrLastUserManager
+	}
+
+	var roleCount int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM role_permissions WHERE permission_id=$1`, id).Scan(&roleCount)
+	if err != nil {
+		return fmt.Errorf("error checking permission roles: %w", err)
+	}
+	if roleCount > 0 {
+		return models.ErrPermissionInUse
+	}
+
+	if _, err := tx.Exec(`DELETE FROM permissions WHERE id=$1`, id); err != nil {
+		return fmt.Errorf("error deleting permission: %w", err)
+	}
+
+	if err := insertAuditLog(tx, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockRolePermissions mencegah dua admin bersamaan mencabut user:manage dari dua role terakhir
+func lockRolePermissions(tx *sql.Tx) error {
+	_, err := tx.Exec(`LOCK TABLE role_permissions IN SHARE ROW EXCLUSIVE MODE`)
+	return err
+}
+
+// ensureOtherUserManager memastikan masih ada role lain (selain roleID) yang memegang user:manage
+// dan punya minimal satu user aktif; role user:manage tanpa anggota tidak dihitung
+func ensureOtherUserManager(tx *sql.Tx, roleID uuid.UUID) error {
+	var others int
+	err := tx.QueryRow(`
+		SELECT COUNT(*)
+		FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE p.name=$1 AND rp.role_id <> $2
+		  AND EXISTS (SELECT 1 FROM users u WHERE u.role_id = rp.role_id AND u.is_active = true)
+	`, models.PermissionUserManage, roleID).Scan(&others)
+	if err != nil {
+		return fmt.Errorf("error checking user:manage holders: %w", err)
+	}
+	if others == 0 {
+		return models.ErrLastUserManager
+	}
+	return nil
+}
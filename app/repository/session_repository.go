@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"achievement-backend/app/models"
+	"github.com/google/uuid"
+)
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+	GetByID(id uuid.UUID) (*models.Session, error)
+	GetActiveByUser(userID uuid.UUID) ([]models.Session, error)
+	// Touch memperbarui last_seen_at paling sering sekali per menit
+	Touch(id uuid.UUID, at time.Time) error
+	// Extend dipanggil saat refresh: perpanjang masa berlaku dan catat UA/IP terbaru
+	Extend(id uuid.UUID, userAgent, ipAddress string, expiresAt time.Time) error
+	// Revoke mencabut sesi beserta semua refresh token-nya
+	Revoke(id uuid.UUID) error
+	// RevokeAllByUser mencabut semua sesi aktif user kecuali exceptID (boleh nil)
+	RevokeAllByUser(userID uuid.UUID, exceptID *uuid.UUID) (int, error)
+}
+
+type sessionRepo struct {
+	DB *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepo{DB: db}
+}
+
+func (r *sessionRepo) Create(session *models.Session) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO sessions (id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at, impersonator_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		session.ID,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		session.CreatedAt,
+		session.LastSeenAt,
+		session.ExpiresAt,
+		session.ImpersonatorID,
+	)
+	return err
+}
+
+func (r *sessionRepo) GetByID(id uuid.UUID) (*models.Session, error) {
+	var s models.Session
+	var userAgent, ipAddress sql.NullString
+	err := r.DB.QueryRow(`
+		SELECT id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at, impersonator_id
+		FROM sessions
+		WHERE id=$1
+	`, id).Scan(&s.ID, &s.UserID, &userAgent, &ipAddress, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt, &s.RevokedAt, &s.ImpersonatorID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	s.UserAgent = userAgent.String
+	s.IPAddress = ipAddress.String
+	return &s, nil
+}
+
+func (r *sessionRepo) GetActiveByUser(userID uuid.UUID) ([]models.Session, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at, impersonator_id
+		FROM sessions
+		WHERE user_id=$1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_seen_at DESC
+	`, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var s models.Session
+		var userAgent, ipAddress sql.NullString
+		if err := rows.Scan(&s.ID, &s.UserID, &userAgent, &ipAddress, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt, &s.RevokedAt, &s.ImpersonatorID); err != nil {
+			return nil, err
+		}
+		s.UserAgent = userAgent.String
+		s.IPAddress = ipAddress.String
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *sessionRepo) Touch(id uuid.UUID, at time.Time) error {
+	_, err := r.DB.Exec(`
+		UPDATE sessions
+		SET last_seen_at=$1
+		WHERE id=$2 AND last_seen_at < $3
+	`, at, id, at.Add(-time.Minute))
+	return err
+}
+
+func (r *sessionRepo) Extend(id uuid.UUID, userAgent, ipAddress string, expiresAt time.Time) error {
+	_, err := r.DB.Exec(`
+		UPDATE sessions
+		SET user_agent=$1, ip_address=$2, last_seen_at=$3, expires_at=$4
+		WHERE id=$5 AND revoked_at IS NULL
+	`, userAgent, ipAddress, time.Now(), expiresAt, id)
+	return err
+}
+
+func (r *sessionRepo) Revoke(id uuid.UUID) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.Exec(`
+		UPDATE sessions SET revoked_at=$1 WHERE id=$2 AND revoked_at IS NULL
+	`, now, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE refresh_tokens SET revoked_at=$1 WHERE family_id=$2 AND revoked_at IS NULL
+	`, now, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *sessionRepo) RevokeAllByUser(userID uuid.UUID, exceptID *uuid.UUID) (int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// uuid.Nil tidak pernah dipakai sebagai id sesi
+	except := uuid.Nil
+	if exceptID != nil {
+		except = *exceptID
+	}
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE sessions
+		SET revoked_at=$1
+		WHERE user_id=$2 AND id<>$3 AND revoked_at IS NULL AND expires_at > $1
+	`, now, userID, except)
+	if err != nil {
+		return 0, err
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at=$1
+		WHERE user_id=$2 AND family_id<>$3 AND revoked_at IS NULL
+	`, now, userID, except); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(revoked), nil
+}
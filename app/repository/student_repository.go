@@ -7,10 +7,12 @@ import (
 
 	"achievement-backend/app/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type StudentRepository interface {
 	GetByID(id uuid.UUID) (*models.Student, error)
+	GetByIDs(ids []uuid.UUID) (map[uuid.UUID]*models.Student, error)
 	GetByUserID(userID uuid.UUID) (*models.Student, error)
 	GetByStudentID(studentID string) (*models.Student, error)
 	Create(student models.Student) (uuid.UUID, error)
@@ -21,6 +23,8 @@ type StudentRepository interface {
 	GetTotalCount() (int, error)
 	
 	GetWithUserDetails(page, limit int) ([]models.StudentResponse, int, error)
+	// GetWithUserDetailsAfter versi cursor GetWithUserDetails (nama, lalu id), tanpa COUNT(*)
+	GetWithUserDetailsAfter(cursor *models.Cursor, limit int) ([]models.StudentResponse, models.CursorPage, error)
 	GetWithAdvisorDetails(page, limit int) ([]models.StudentResponse, int, error)
 	
 	// Get by advisor with pagination
@@ -63,6 +67,37 @@ func (r *studentRepo) GetByID(id uuid.UUID) (*models.Student, error) {
 	return &s, nil
 }
 
+// GetByIDs satu query untuk banyak mahasiswa; ID yang tidak ditemukan tidak ada di map
+func (r *studentRepo) GetByIDs(ids []uuid.UUID) (map[uuid.UUID]*models.Student, error) {
+	students := make(map[uuid.UUID]*models.Student, len(ids))
+	if len(ids) == 0 {
+		return students, nil
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT id, user_id, student_id, program_study, academic_year,
+		       advisor_id, created_at
+		FROM students
+		WHERE id = ANY($1)
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.Student
+		if err := rows.Scan(
+			&s.ID, &s.UserID, &s.StudentID, &s.ProgramStudy,
+			&s.AcademicYear, &s.AdvisorID, &s.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		students[s.ID] = &s
+	}
+	return students, rows.Err()
+}
+
 func (r *studentRepo) GetByUserID(userID uuid.UUID) (*models.Student, error) {
 	var s models.Student
 	err := r.DB.QueryRow(`
@@ -103,12 +138,14 @@ func (r *studentRepo) GetByStudentID(studentID string) (*models.Student, error)
 	return &s, nil
 }
 
+const insertStudentQuery = `
+	INSERT INTO students (id, user_id, student_id, program_study, academic_year, 
+	                     advisor_id, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, NOW())
+`
+
 func (r *studentRepo) Create(student models.Student) (uuid.UUID, error) {
-	_, err := r.DB.Exec(`
-		INSERT INTO students (id, user_id, student_id, program_study, academic_year, 
-		                     advisor_id, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW())
-	`, 
+	_, err := r.DB.Exec(insertStudentQuery, 
 		student.ID,           // 1
 		student.UserID,       // 2
 		student.StudentID,    // 3
@@ -283,6 +320,55 @@ func (r *studentRepo) GetWithUserDetails(page, limit int) ([]models.StudentRespo
 	return students, total, nil
 }
 
+// studentsKeyset urut nama lalu id user (1 user 1 mahasiswa) supaya memakai index users (full_name, id)
+var studentsKeyset = keyset{Sort: "students:name", Key: "u.full_name", ID: "u.id"}
+
+func (r *studentRepo) GetWithUserDetailsAfter(cursor *models.Cursor, limit int) ([]models.StudentResponse, models.CursorPage, error) {
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	where, orderBy, params, err := studentsKeyset.sql(cursor, nil)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	params = append(params, limit+1)
+
+	rows, err := r.DB.Query(`
+		SELECT s.id, s.user_id, u.full_name, u.email, u.username,
+		       s.student_id, s.program_study, s.academic_year, 
+		       s.advisor_id, s.created_at
+		FROM students s
+		JOIN users u ON s.user_id = u.id
+		WHERE u.is_active = true`+where+orderBy+fmt.Sprintf(`
+		LIMIT $%d`, len(params)), params...)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	defer rows.Close()
+
+	var students []models.StudentResponse
+	for rows.Next() {
+		var s models.StudentResponse
+		if err := rows.Scan(
+			&s.ID, &s.UserID, &s.FullName, &s.Email, &s.Username,
+			&s.StudentID, &s.ProgramStudy, &s.AcademicYear,
+			&s.AdvisorID, &s.CreatedAt,
+		); err != nil {
+			return nil, models.CursorPage{}, err
+		}
+		students = append(students, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.CursorPage{}, err
+	}
+
+	students, page := keysetPage(studentsKeyset, students, limit, cursor, func(s models.StudentResponse) (string, uuid.UUID) {
+		return s.FullName, s.UserID
+	})
+	return students, page, nil
+}
+
 func (r *studentRepo) GetWithAdvisorDetails(page, limit int) ([]models.StudentResponse, int, error) {
 	if page < 1 {
 		page = 1
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"achievement-backend/app/models"
+	"github.com/google/uuid"
+)
+
+type TwoFactorRepository interface {
+	GetByUserID(userID uuid.UUID) (*models.UserTOTP, error)
+	// SavePending menyimpan secret baru selama 2FA belum aktif; false jika sudah aktif
+	SavePending(userID uuid.UUID, secret string) (bool, error)
+	// Enable mengaktifkan 2FA dan mengganti recovery code dalam satu transaksi
+	Enable(userID uuid.UUID, step int64, recoveryCodeHashes []string) error
+	Disable(userID uuid.UUID) error
+	// UseStep mencatat time step yang dipakai; false jika step sudah pernah dipakai (replay)
+	UseStep(userID uuid.UUID, step int64) (bool, error)
+	ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string) error
+	// UseRecoveryCode menandai recovery code terpakai; false jika tidak ada / sudah dipakai
+	UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(userID uuid.UUID) (int, error)
+}
+
+type twoFactorRepo struct {
+	DB *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) TwoFactorRepository {
+	return &twoFactorRepo{DB: db}
+}
+
+func (r *twoFactorRepo) GetByUserID(userID uuid.UUID) (*models.UserTOTP, error) {
+	var t models.UserTOTP
+	err := r.DB.QueryRow(`
+		SELECT user_id, secret, enabled, last_used_step, confirmed_at, created_at, updated_at
+		FROM user_totp
+		WHERE user_id=$1
+	`, userID).Scan(&t.UserID, &t.Secret, &t.Enabled, &t.LastUsedStep, &t.ConfirmedAt, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *twoFactorRepo) SavePending(userID uuid.UUID, secret string) (bool, error) {
+	now := time.Now()
+	result, err := r.DB.Exec(`
+		INSERT INTO user_totp (user_id, secret, enabled, created_at, updated_at)
+		VALUES ($1, $2, false, $3, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret,
+		    last_used_step = NULL,
+		    updated_at = EXCLUDED.updated_at
+		WHERE user_totp.enabled = false
+	`, userID, secret, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *twoFactorRepo) Enable(userID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE user_totp
+		SET enabled=true, last_used_step=$1, confirmed_at=$2, updated_at=$2
+		WHERE user_id=$3 AND enabled=false
+	`, step, now, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return models.ErrTwoFactorAlreadyEnabled
+	}
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *twoFactorRepo) Disable(userID uuid.UUID) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id=$1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM user_totp WHERE user_id=$1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *twoFactorRepo) UseStep(userID uuid.UUID, step int64) (bool, error) {
+	result, err := r.DB.Exec(`
+		UPDATE user_totp
+		SET last_used_step=$1, updated_at=$2
+		WHERE user_id=$3 AND (last_used_step IS NULL OR last_used_step < $1)
+	`, step, time.Now(), userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *twoFactorRepo) ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userID, codeHashes, time.Now()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, userID uuid.UUID, codeHashes []string, now time.Time) error {
+	if _, err := tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id=$1`, userID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(`
+			INSERT INTO user_recovery_codes (id, user_id, code_hash, created_at)
+			VALUES ($1, $2, $3, $4)
+		`, uuid.New(), userID, hash, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *twoFactorRepo) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	result, err := r.DB.Exec(`
+		UPDATE user_recovery_codes
+		SET used_at=$1
+		WHERE user_id=$2 AND code_hash=$3 AND used_at IS NULL
+	`, time.Now(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *twoFactorRepo) CountUnusedRecoveryCodes(userID uuid.UUID) (int, error) {
+	var count int
+	err := r.DB.QueryRow(`
+		SELECT COUNT(*) FROM user_recovery_codes WHERE user_id=$1 AND used_at IS NULL
+	`, userID).Scan(&count)
+	return count, err
+}
@@ -8,11 +8,13 @@ import (
 
 	"achievement-backend/app/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type UserRepository interface {
 	// Basic CRUD
 	GetByID(id uuid.UUID) (*models.User, error)           // Hanya active users
+	GetByIDs(ids []uuid.UUID) (map[uuid.UUID]*models.User, error) // Hanya active users
 	GetByEmail(email string) (*models.User, error)        // Hanya active users
 	GetByUsername(username string) (*models.User, error)  // Hanya active users
 	GetByUsernameOrEmail(identifier string) (*models.User, error) // Hanya active users
@@ -20,9 +22,16 @@ type UserRepository interface {
 	Update(id uuid.UUID, req *models.UpdateUserRequest) error
 	UpdatePassword(id uuid.UUID, hashedPassword string) error
 	SoftDelete(id uuid.UUID) error
+	// Varian admin: perubahan user dan entri audit-nya commit dalam satu transaksi
+	CreateWithProfile(user *models.User, student *models.Student, lecturer *models.Lecturer, audit *models.AuditLog) error
+	UpdateWithAudit(id uuid.UUID, req *models.UpdateUserRequest, audit *models.AuditLog) error
+	UpdatePasswordWithAudit(id uuid.UUID, hashedPassword string, audit *models.AuditLog) error
+	SoftDeleteWithAudit(id uuid.UUID, audit *models.AuditLog) error
 	HardDelete(id uuid.UUID) error
 	
 	GetAll(page, limit int) ([]models.User, int, error) 
+	// GetAllAfter versi cursor GetAll (created_at DESC), tanpa COUNT(*)
+	GetAllAfter(cursor *models.Cursor, limit int) ([]models.User, models.CursorPage, error)
 	
 	GetInactiveUsers(page, limit int) ([]models.User, int, error) 
 	
@@ -64,6 +73,37 @@ func (r *userRepo) GetByID(id uuid.UUID) (*models.User, error) {
 	return &u, nil
 }
 
+// GetByIDs satu query untuk banyak user; ID yang tidak ditemukan / nonaktif tidak ada di map
+func (r *userRepo) GetByIDs(ids []uuid.UUID) (map[uuid.UUID]*models.User, error) {
+	users := make(map[uuid.UUID]*models.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT id, username, email, password_hash, full_name, role_id,
+		       is_active, created_at, updated_at
+		FROM users
+		WHERE id = ANY($1) AND is_active=true
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(
+			&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.FullName, &u.RoleID,
+			&u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		users[u.ID] = &u
+	}
+	return users, rows.Err()
+}
+
 func (r *userRepo) GetByEmail(email string) (*models.User, error) {
 	var u models.User
 	err := r.DB.QueryRow(`
@@ -124,12 +164,14 @@ func (r *userRepo) GetByUsernameOrEmail(identifier string) (*models.User, error)
 	return &u, nil
 }
 
+const insertUserQuery = `
+	INSERT INTO users (id, username, email, password_hash, full_name, role_id, 
+	                  is_active, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+`
+
 func (r *userRepo) Create(user *models.User) (uuid.UUID, error) {
-	_, err := r.DB.Exec(`
-		INSERT INTO users (id, username, email, password_hash, full_name, role_id, 
-		                  is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
-	`, 
+	_, err := r.DB.Exec(insertUserQuery, 
 		user.ID,          
 		user.Username,     
 		user.Email,          
@@ -146,7 +188,51 @@ func (r *userRepo) Create(user *models.User) (uuid.UUID, error) {
 	return user.ID, nil
 }
 
+// CreateWithProfile menyimpan user, profil mahasiswa atau dosen (boleh nil) dan entri audit
+// dalam satu transaksi, sehingga profil yang gagal dibuat tidak meninggalkan user tanpa profil
+func (r *userRepo) CreateWithProfile(user *models.User, student *models.Student, lecturer *models.Lecturer, audit *models.AuditLog) error {
+	return r.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(insertUserQuery,
+			user.ID, user.Username, user.Email, user.PasswordHash, user.FullName, user.RoleID, user.IsActive)
+		if err != nil {
+			return err
+		}
+		if student != nil {
+			_, err := tx.Exec(insertStudentQuery,
+				student.ID, student.UserID, student.StudentID, student.ProgramStudy, student.AcademicYear, student.AdvisorID)
+			if err != nil {
+				return fmt.Errorf("error creating student profile: %w", err)
+			}
+		}
+		if lecturer != nil {
+			_, err := tx.Exec(insertLecturerQuery,
+				lecturer.ID, lecturer.UserID, lecturer.LecturerID, lecturer.Department)
+			if err != nil {
+				return fmt.Errorf("error creating lecturer profile: %w", err)
+			}
+		}
+		return insertAuditLog(tx, audit)
+	})
+}
+
 func (r *userRepo) Update(id uuid.UUID, req *models.UpdateUserRequest) error {
+	query, params := updateUserQuery(id, req)
+	_, err := r.DB.Exec(query, params...)
+	return err
+}
+
+func (r *userRepo) UpdateWithAudit(id uuid.UUID, req *models.UpdateUserRequest, audit *models.AuditLog) error {
+	return r.withTx(func(tx *sql.Tx) error {
+		query, params := updateUserQuery(id, req)
+		if _, err := tx.Exec(query, params...); err != nil {
+			return err
+		}
+		return insertAuditLog(tx, audit)
+	})
+}
+
+// updateUserQuery UPDATE dinamis hanya untuk field yang diisi di request
+func updateUserQuery(id uuid.UUID, req *models.UpdateUserRequest) (string, []interface{}) {
 	query := `UPDATE users SET updated_at=$1`
 	params := []interface{}{time.Now()}
 	paramIndex := 2
@@ -174,29 +260,65 @@ func (r *userRepo) Update(id uuid.UUID, req *models.UpdateUserRequest) error {
 	
 	query += ` WHERE id=$` + fmt.Sprintf("%d", paramIndex)
 	params = append(params, id)
-	
-	_, err := r.DB.Exec(query, params...)
-	return err
+
+	return query, params
 }
 
+const updatePasswordQuery = `
+	UPDATE users 
+	SET password_hash=$1, updated_at=$2
+	WHERE id=$3
+`
+
 func (r *userRepo) UpdatePassword(id uuid.UUID, hashedPassword string) error {
-	_, err := r.DB.Exec(`
-		UPDATE users 
-		SET password_hash=$1, updated_at=$2
-		WHERE id=$3
-	`, hashedPassword, time.Now(), id)
+	_, err := r.DB.Exec(updatePasswordQuery, hashedPassword, time.Now(), id)
 	return err
 }
 
+func (r *userRepo) UpdatePasswordWithAudit(id uuid.UUID, hashedPassword string, audit *models.AuditLog) error {
+	return r.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(updatePasswordQuery, hashedPassword, time.Now(), id); err != nil {
+			return err
+		}
+		return insertAuditLog(tx, audit)
+	})
+}
+
+const softDeleteUserQuery = `
+	UPDATE users 
+	SET is_active=false, updated_at=$1 
+	WHERE id=$2
+`
+
 func (r *userRepo) SoftDelete(id uuid.UUID) error {
-	_, err := r.DB.Exec(`
-		UPDATE users 
-		SET is_active=false, updated_at=$1 
-		WHERE id=$2
-	`, time.Now(), id)
+	_, err := r.DB.Exec(softDeleteUserQuery, time.Now(), id)
 	return err
 }
 
+func (r *userRepo) SoftDeleteWithAudit(id uuid.UUID, audit *models.AuditLog) error {
+	return r.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(softDeleteUserQuery, time.Now(), id); err != nil {
+			return err
+		}
+		return insertAuditLog(tx, audit)
+	})
+}
+
+// withTx menjalankan fn dalam satu transaksi, commit jika sukses dan rollback jika error
+func (r *userRepo) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *userRepo) GetAll(page, limit int) ([]models.User, int, error) {
 	if page < 1 {
 		page = 1
@@ -239,6 +361,52 @@ func (r *userRepo) GetAll(page, limit int) ([]models.User, int, error) {
 	return users, total, nil
 }
 
+// usersKeyset urutan GetAll: terbaru lebih dulu
+var usersKeyset = keyset{Sort: "users", Key: "created_at", ID: "id", Descending: true, Time: true}
+
+func (r *userRepo) GetAllAfter(cursor *models.Cursor, limit int) ([]models.User, models.CursorPage, error) {
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	where, orderBy, params, err := usersKeyset.sql(cursor, nil)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	params = append(params, limit+1)
+
+	rows, err := r.DB.Query(`
+		SELECT id, username, email, full_name, role_id, 
+		       is_active, created_at, updated_at
+		FROM users
+		WHERE is_active=true`+where+orderBy+fmt.Sprintf(`
+		LIMIT $%d`, len(params)), params...)
+	if err != nil {
+		return nil, models.CursorPage{}, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(
+			&u.ID, &u.Username, &u.Email, &u.FullName, &u.RoleID,
+			&u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+		); err != nil {
+			return nil, models.CursorPage{}, err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, models.CursorPage{}, err
+	}
+
+	users, page := keysetPage(usersKeyset, users, limit, cursor, func(u models.User) (string, uuid.UUID) {
+		return timeKey(u.CreatedAt), u.ID
+	})
+	return users, page, nil
+}
+
 func (r *userRepo) GetInactiveUsers(page, limit int) ([]models.User, int, error) {
 	if page < 1 {
 		page = 1
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// achievementPage data pendamping satu halaman reference. Dokumen Mongo, profil mahasiswa
+// dan user (mahasiswa + verifikator) masing-masing dimuat dengan satu query, bukan per baris.
+type achievementPage struct {
+	achievements map[primitive.ObjectID]*models.Achievement
+	students     map[uuid.UUID]*models.Student
+	users        map[uuid.UUID]*models.User
+}
+
+func loadAchievementPage(
+	ctx context.Context,
+	achievementRepo repository.AchievementRepository,
+	studentRepo repository.StudentRepository,
+	userRepo repository.UserRepository,
+	refs []*models.AchievementReference,
+) (*achievementPage, error) {
+	mongoIDs := make([]primitive.ObjectID, 0, len(refs))
+	studentIDs := make([]uuid.UUID, 0, len(refs))
+	userIDs := make([]uuid.UUID, 0, len(refs)*2)
+	seenStudents := make(map[uuid.UUID]bool, len(refs))
+	seenUsers := make(map[uuid.UUID]bool, len(refs)*2)
+	addUser := func(id uuid.UUID) {
+		if !seenUsers[id] {
+			seenUsers[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	for _, ref := range refs {
+		if mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID); err == nil {
+			mongoIDs = append(mongoIDs, mongoID)
+		}
+		if !seenStudents[ref.StudentID] {
+			seenStudents[ref.StudentID] = true
+			studentIDs = append(studentIDs, ref.StudentID)
+		}
+		if ref.VerifiedBy != nil {
+			addUser(*ref.VerifiedBy)
+		}
+	}
+
+	achievements, err := achievementRepo.FindByIDs(ctx, mongoIDs)
+	if err != nil {
+		return nil, err
+	}
+	students, err := studentRepo.GetByIDs(studentIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, student := range students {
+		addUser(student.UserID)
+	}
+	users, err := userRepo.GetByIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &achievementPage{
+		achievements: achievements,
+		students:     students,
+		users:        users,
+	}, nil
+}
+
+// achievement dokumen Mongo untuk ref; nil (dan dicatat ke log) kalau ID rusak atau dokumen hilang
+func (p *achievementPage) achievement(ref *models.AchievementReference) *models.Achievement {
+	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		logMissingDocument(ref, err)
+		return nil
+	}
+	achievement := p.achievements[mongoID]
+	if achievement == nil {
+		logMissingDocument(ref, nil)
+	}
+	return achievement
+}
+
+// student profil mahasiswa pemilik ref, nil kalau tidak ditemukan
+func (p *achievementPage) student(ref *models.AchievementReference) *models.Student {
+	return p.students[ref.StudentID]
+}
+
+// userName nama lengkap user aktif, kosong kalau tidak ditemukan
+func (p *achievementPage) userName(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	if user := p.users[*id]; user != nil {
+		return user.FullName
+	}
+	return ""
+}
+
+// logMissingDocument dipanggil list endpoint yang melewati reference tanpa dokumen Mongo;
+// total pagination jadi tidak cocok dengan jumlah item, telusuri dengan -reconcile
+func logMissingDocument(ref *models.AchievementReference, err error) {
+	reason := "not found"
+	if err != nil {
+		reason = err.Error()
+	}
+	log.Printf("Warning: achievement reference %s skipped, Mongo document %q unavailable: %s",
+		ref.ID, ref.MongoAchievementID, reason)
+}
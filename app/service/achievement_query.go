@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"achievement-backend/app/models"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// parseAchievementListFilter membaca query string GET /achievements
+func parseAchievementListFilter(c *fiber.Ctx) (models.AchievementListFilter, error) {
+	filter := models.AchievementListFilter{
+		Status:           c.Query("status"),
+		ProgramStudy:     c.Query("program_study"),
+		AcademicYear:     c.Query("academic_year"),
+		Types:            queryList(c, "type"),
+		Tags:             queryList(c, "tags"),
+		CompetitionLevel: c.Query("competition_level"),
+		Query:            strings.TrimSpace(c.Query("q")),
+		Sort:             c.Query("sort"),
+	}
+
+	for _, t := range filter.Types {
+		if !contains(models.AchievementTypes, t) {
+			return filter, fmt.Errorf("invalid type %q, valid types: %v", t, models.AchievementTypes)
+		}
+	}
+
+	switch c.Query("order", "desc") {
+	case "asc":
+		filter.Ascending = true
+	case "desc":
+	default:
+		return filter, fmt.Errorf("invalid order, expected asc or desc")
+	}
+
+	var err error
+	if filter.EventFrom, err = queryDate(c, "event_from"); err != nil {
+		return filter, fmt.Errorf("invalid event_from, expected YYYY-MM-DD or RFC3339")
+	}
+	if filter.EventTo, err = queryDate(c, "event_to"); err != nil {
+		return filter, fmt.Errorf("invalid event_to, expected YYYY-MM-DD or RFC3339")
+	}
+	if filter.MinPoints, err = queryIntPtr(c, "min_points"); err != nil {
+		return filter, fmt.Errorf("invalid min_points")
+	}
+	if filter.MaxPoints, err = queryIntPtr(c, "max_points"); err != nil {
+		return filter, fmt.Errorf("invalid max_points")
+	}
+
+	if err := filter.Validate(); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// queryAchievements planner GET /achievements. Filter tersebar di dua store, jadi store
+// yang memimpin query dipilih dari filter, scope dan urutan yang diminta:
+//   - hanya filter & urutan Postgres: paginasi langsung di Postgres
+//   - urutan dokumen / created_at dengan scope global, atau urutan dokumen dengan scope
+//     yang reference-nya lebih dari MaxAchievementFilterIDs: Mongo memimpin (queryMongoLed)
+//   - urutan dokumen dengan scope sempit: Postgres menyaring ID (scope, status, mahasiswa),
+//     Mongo memfilter, mengurutkan dan memaginasi, lalu reference halaman itu dimuat sekaligus
+//   - filter dokumen dengan urutan Postgres: Postgres memimpin (queryPostgresLed)
+//
+// Di setiap jalur total dihitung dari data yang lolos filter kedua store, sama dengan
+// jumlah item yang bisa dipaginasi.
+func (s *AchievementService) queryAchievements(ctx context.Context, scope models.AchievementScope, filter models.AchievementListFilter, page, limit int) ([]*models.AchievementReference, int, error) {
+	if !filter.HasDocumentFilter() && !filter.IsDocumentSort() {
+		return s.achievementRefRepo.FindFiltered(ctx, scope, filter, page, limit)
+	}
+
+	if scope.Relation == models.RelationGlobal &&
+		(filter.IsDocumentSort() || filter.Sort == models.AchievementSortCreatedAt) {
+		return s.queryMongoLed(ctx, scope, filter, page, limit)
+	}
+	if !filter.IsDocumentSort() {
+		return s.queryPostgresLed(ctx, scope, filter, page, limit)
+	}
+
+	hexIDs, err := s.achievementRefRepo.FindFilteredMongoIDs(ctx, scope, filter, 0, models.MaxAchievementFilterIDs+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(hexIDs) > models.MaxAchievementFilterIDs {
+		return s.queryMongoLed(ctx, scope, filter, page, limit)
+	}
+	mongoIDs := objectIDs(hexIDs)
+	if len(mongoIDs) == 0 {
+		return nil, 0, nil
+	}
+
+	docs, total, err := s.achievementRepo.Search(ctx, mongoIDs, filter, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	pageIDs := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		pageIDs = append(pageIDs, doc.ID.Hex())
+	}
+
+	refs, err := s.referencesInOrder(ctx, pageIDs)
+	return refs, total, err
+}
+
+// queryMongoLed Mongo memfilter dan mengurutkan seluruh koleksi; ID hasilnya dicocokkan ke
+// Postgres per batch (scope, status, program studi, angkatan). Dokumen tanpa reference,
+// reference deleted yang belum ditandai outbox dan yang tidak lolos filter Postgres tidak
+// masuk halaman maupun total.
+func (s *AchievementService) queryMongoLed(ctx context.Context, scope models.AchievementScope, filter models.AchievementListFilter, page, limit int) ([]*models.AchievementReference, int, error) {
+	docIDs, err := s.achievementRepo.SearchIDs(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	start := (page - 1) * limit
+	refs := make([]*models.AchievementReference, 0, limit)
+	total := 0
+	for len(docIDs) > 0 {
+		n := min(len(docIDs), models.MaxAchievementFilterIDs)
+		batch := make([]string, 0, n)
+		for _, docID := range docIDs[:n] {
+			batch = append(batch, docID.Hex())
+		}
+		docIDs = docIDs[n:]
+
+		matched, err := s.achievementRefRepo.FindFilteredByMongoIDs(ctx, scope, filter, batch)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, mongoID := range batch {
+			ref := matched[mongoID]
+			if ref == nil {
+				continue
+			}
+			if total >= start && len(refs) < limit {
+				refs = append(refs, ref)
+			}
+			total++
+		}
+	}
+	return refs, total, nil
+}
+
+// queryPostgresLed ID urut dari Postgres dibaca per batch dan disaring filter Mongo sampai
+// habis, sehingga total tepat tanpa membatasi jumlah reference
+func (s *AchievementService) queryPostgresLed(ctx context.Context, scope models.AchievementScope, filter models.AchievementListFilter, page, limit int) ([]*models.AchievementReference, int, error) {
+	start := (page - 1) * limit
+	pageIDs := make([]string, 0, limit)
+	total := 0
+	for offset := 0; ; offset += models.MaxAchievementFilterIDs {
+		hexIDs, err := s.achievementRefRepo.FindFilteredMongoIDs(ctx, scope, filter, offset, models.MaxAchievementFilterIDs)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if mongoIDs := objectIDs(hexIDs); len(mongoIDs) > 0 {
+			matched, err := s.achievementRepo.FindMatchingIDs(ctx, mongoIDs, filter)
+			if err != nil {
+				return nil, 0, err
+			}
+			for _, mongoID := range mongoIDs {
+				if !matched[mongoID] {
+					continue
+				}
+				if total >= start && len(pageIDs) < limit {
+					pageIDs = append(pageIDs, mongoID.Hex())
+				}
+				total++
+			}
+		}
+
+		if len(hexIDs) < models.MaxAchievementFilterIDs {
+			break
+		}
+	}
+
+	refs, err := s.referencesInOrder(ctx, pageIDs)
+	return refs, total, err
+}
+
+// objectIDs mengubah mongo_achievement_id ke ObjectID; ID yang tidak valid dilewati
+func objectIDs(hexIDs []string) []primitive.ObjectID {
+	mongoIDs := make([]primitive.ObjectID, 0, len(hexIDs))
+	for _, hexID := range hexIDs {
+		if mongoID, err := primitive.ObjectIDFromHex(hexID); err == nil {
+			mongoIDs = append(mongoIDs, mongoID)
+		}
+	}
+	return mongoIDs
+}
+
+// referencesInOrder reference untuk satu halaman ID Mongo dengan urutan yang sama;
+// dokumen tanpa reference dilewati
+func (s *AchievementService) referencesInOrder(ctx context.Context, pageIDs []string) ([]*models.AchievementReference, error) {
+	refsByMongoID, err := s.achievementRefRepo.FindByMongoIDs(ctx, pageIDs)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]*models.AchievementReference, 0, len(pageIDs))
+	for _, mongoID := range pageIDs {
+		if ref := refsByMongoID[mongoID]; ref != nil {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// queryList nilai dipisah koma, mis. ?tags=robotics,ai
+func queryList(c *fiber.Ctx, key string) []string {
+	var values []string
+	for _, value := range strings.Split(c.Query(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// queryDate menerima tanggal (YYYY-MM-DD) atau RFC3339
+func queryDate(c *fiber.Ctx, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return &t, nil
+	}
+	return queryTime(c, key)
+}
+
+func queryIntPtr(c *fiber.Ctx, key string) (*int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/config"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AchievementReconciler mencari drift antara achievement_references (Postgres) dan koleksi
+// achievements (Mongo): reference tanpa dokumen, dokumen tanpa reference dan studentId yang
+// berbeda. Postgres dianggap sumber kebenaran karena otorisasi memakai student_id reference.
+type AchievementReconciler struct {
+	achievementRepo    repository.AchievementRepository
+	achievementRefRepo repository.AchievementReferenceRepository
+	reportRepo         repository.ReconciliationReportRepository
+	cfg                config.ReconcileConfig
+}
+
+func NewAchievementReconciler(
+	achievementRepo repository.AchievementRepository,
+	achievementRefRepo repository.AchievementReferenceRepository,
+	reportRepo repository.ReconciliationReportRepository,
+	cfg config.ReconcileConfig,
+) *AchievementReconciler {
+	return &AchievementReconciler{
+		achievementRepo:    achievementRepo,
+		achievementRefRepo: achievementRefRepo,
+		reportRepo:         reportRepo,
+		cfg:                cfg,
+	}
+}
+
+// ParseReconcileArgs argumen -reconcile: kosong/report = dry run, repair dan/atau quarantine
+func ParseReconcileArgs(args []string) (models.ReconcileOptions, error) {
+	opts := models.ReconcileOptions{Trigger: models.ReconcileTriggerCommand}
+	for _, arg := range args {
+		switch arg {
+		case "report":
+		case "repair":
+			opts.Repair = true
+		case "quarantine":
+			opts.Quarantine = true
+		default:
+			return opts, fmt.Errorf("unknown reconcile argument %q (use report, repair, quarantine)", arg)
+		}
+	}
+	return opts, nil
+}
+
+// Reconcile memindai kedua store dan menyimpan laporannya. Laporan tetap disimpan
+// (dengan Error terisi) walaupun pemindaian berhenti di tengah jalan. Hanya satu run
+// yang berjalan di seluruh instance; run lain mendapat ErrReconciliationRunning tanpa laporan.
+func (r *AchievementReconciler) Reconcile(ctx context.Context, opts models.ReconcileOptions) (*models.ReconciliationReport, error) {
+	unlock, locked, err := r.reportRepo.TryLock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire reconciliation lock: %w", err)
+	}
+	if !locked {
+		return nil, models.ErrReconciliationRunning
+	}
+	defer unlock()
+
+	report := &models.ReconciliationReport{
+		ID:         uuid.New(),
+		Trigger:    opts.Trigger,
+		Repair:     opts.Repair,
+		Quarantine: opts.Quarantine,
+		Counts:     make(map[string]int),
+		StartedAt:  time.Now(),
+	}
+
+	// Record yang lebih muda dari grace period mungkin masih menunggu outbox
+	cutoff := report.StartedAt.Add(-r.cfg.GracePeriod)
+
+	err = r.scanReferences(ctx, report, opts, cutoff)
+	if err == nil {
+		err = r.scanDocuments(ctx, report, opts, cutoff)
+	}
+
+	finishedAt := time.Now()
+	report.FinishedAt = &finishedAt
+	if err != nil {
+		message := err.Error()
+		report.Error = &message
+	}
+
+	if saveErr := r.reportRepo.Create(report); saveErr != nil {
+		log.Printf("Warning: failed to save reconciliation report %s: %v", report.ID, saveErr)
+	}
+	return report, err
+}
+
+// Schedule menjalankan Reconcile setiap cfg.Interval sampai ctx dibatalkan
+func (r *AchievementReconciler) Schedule(ctx context.Context) {
+	if r.cfg.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := r.Reconcile(ctx, models.ReconcileOptions{
+			Repair:     r.cfg.Repair,
+			Quarantine: r.cfg.Quarantine,
+			Trigger:    models.ReconcileTriggerScheduled,
+		})
+		if errors.Is(err, models.ErrReconciliationRunning) {
+			log.Printf("Scheduled reconciliation skipped: %v", err)
+			continue
+		}
+		if err != nil {
+			if report != nil {
+				log.Printf("Warning: scheduled reconciliation %s failed: %v", report.ID, err)
+			} else {
+				log.Printf("Warning: scheduled reconciliation failed: %v", err)
+			}
+			continue
+		}
+		if report.IssueCount > 0 {
+			log.Printf("Reconciliation %s found %d issue(s): %v", report.ID, report.IssueCount, report.Counts)
+		}
+	}
+}
+
+// scanReferences mencari reference yang dokumennya hilang / ID-nya rusak dan studentId yang berbeda
+func (r *AchievementReconciler) scanReferences(ctx context.Context, report *models.ReconciliationReport, opts models.ReconcileOptions, cutoff time.Time) error {
+	after := uuid.Nil
+	for {
+		refs, err := r.achievementRefRepo.FindBatchAfter(ctx, after, r.cfg.BatchSize)
+		if err != nil {
+			return fmt.Errorf("scan references: %w", err)
+		}
+		if len(refs) == 0 {
+			return nil
+		}
+		after = refs[len(refs)-1].ID
+		report.ReferencesScanned += len(refs)
+
+		mongoIDs := make([]primitive.ObjectID, 0, len(refs))
+		for _, ref := range refs {
+			if mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID); err == nil {
+				mongoIDs = append(mongoIDs, mongoID)
+			}
+		}
+		docs, err := r.achievementRepo.FindKeysByIDs(ctx, mongoIDs)
+		if err != nil {
+			return fmt.Errorf("load documents: %w", err)
+		}
+
+		for _, ref := range refs {
+			refID, studentID := ref.ID, ref.StudentID
+			issue := models.ReconciliationIssue{
+				ReferenceID:        &refID,
+				ReferenceStatus:    ref.Status,
+				ReferenceStudentID: &studentID,
+				MongoAchievementID: ref.MongoAchievementID,
+			}
+
+			mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+			var doc *models.Achievement
+			if err == nil {
+				doc = docs[mongoID]
+			}
+
+			switch {
+			case doc == nil:
+				// Reference yang sudah deleted tanpa dokumen tidak tampil di mana pun
+				if ref.Status == string(models.StatusDeleted) || ref.CreatedAt.After(cutoff) {
+					continue
+				}
+				issue.Type = models.ReconcileIssueMissingDocument
+				if err != nil {
+					issue.Type = models.ReconcileIssueInvalidMongoID
+				}
+				if opts.Quarantine {
+					r.act(&issue, models.ReconcileActionQuarantined,
+						r.achievementRefRepo.Quarantine(ctx, ref.ID, "Reconciliation: "+issue.Type))
+				}
+
+			case doc.StudentID != ref.StudentID.String():
+				issue.Type = models.ReconcileIssueStudentMismatch
+				issue.DocumentStudentID = doc.StudentID
+				if opts.Repair {
+					r.act(&issue, models.ReconcileActionRepaired,
+						r.achievementRepo.SetStudentID(ctx, mongoID, ref.StudentID.String()))
+				}
+
+			default:
+				continue
+			}
+
+			r.addIssue(report, issue)
+		}
+	}
+}
+
+// scanDocuments mencari dokumen Mongo yang tidak dirujuk reference mana pun
+func (r *AchievementReconciler) scanDocuments(ctx context.Context, report *models.ReconciliationReport, opts models.ReconcileOptions, cutoff time.Time) error {
+	after := primitive.NilObjectID
+	for {
+		docs, err := r.achievementRepo.FindKeysAfter(ctx, after, r.cfg.BatchSize)
+		if err != nil {
+			return fmt.Errorf("scan documents: %w", err)
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		after = docs[len(docs)-1].ID
+		report.DocumentsScanned += len(docs)
+
+		hexIDs := make([]string, 0, len(docs))
+		for _, doc := range docs {
+			hexIDs = append(hexIDs, doc.ID.Hex())
+		}
+		existing, err := r.achievementRefRepo.ExistingMongoIDs(ctx, hexIDs)
+		if err != nil {
+			return fmt.Errorf("load references: %w", err)
+		}
+
+		for _, doc := range docs {
+			if existing[doc.ID.Hex()] || doc.CreatedAt.After(cutoff) {
+				continue
+			}
+
+			issue := models.ReconciliationIssue{
+				Type:               models.ReconcileIssueOrphanDocument,
+				MongoAchievementID: doc.ID.Hex(),
+				DocumentStudentID:  doc.StudentID,
+			}
+			if opts.Quarantine {
+				r.act(&issue, models.ReconcileActionQuarantined,
+					r.achievementRepo.Quarantine(ctx, doc.ID, models.ReconcileIssueOrphanDocument))
+			}
+			r.addIssue(report, issue)
+		}
+	}
+}
+
+// act mencatat hasil perbaikan / karantina pada issue
+func (r *AchievementReconciler) act(issue *models.ReconciliationIssue, action string, err error) {
+	if err != nil {
+		issue.Action = models.ReconcileActionFailed
+		issue.Error = err.Error()
+		return
+	}
+	issue.Action = action
+}
+
+// addIssue hitungan per jenis selalu lengkap, detail issue dibatasi cfg.MaxIssues
+func (r *AchievementReconciler) addIssue(report *models.ReconciliationReport, issue models.ReconciliationIssue) {
+	report.IssueCount++
+	report.Counts[issue.Type]++
+	if issue.Action != "" {
+		report.Counts[issue.Action]++
+	}
+	if len(report.Issues) < r.cfg.MaxIssues {
+		report.Issues = append(report.Issues, issue)
+	}
+}
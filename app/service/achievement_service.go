@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -23,6 +24,9 @@ type AchievementService struct {
 	lecturerRepo       repository.LecturerRepository
 	userRepo           repository.UserRepository
 	roleRepo           repository.RoleRepository
+	authz              *Authorizer
+	auditLogger        *AuditLogger
+	sync               *AchievementSync
 }
 
 func NewAchievementService(
@@ -32,6 +36,8 @@ func NewAchievementService(
 	lecturerRepo repository.LecturerRepository,
 	userRepo repository.UserRepository,
 	roleRepo repository.RoleRepository, 
+	auditLogger *AuditLogger,
+	sync *AchievementSync,
 ) *AchievementService {
 	return &AchievementService{
 		achievementRepo:    achievementRepo,
@@ -40,6 +46,9 @@ func NewAchievementService(
 		lecturerRepo:       lecturerRepo,
 		userRepo:           userRepo,
 		roleRepo:           roleRepo, 
+		authz:              NewAuthorizer(studentRepo, lecturerRepo),
+		auditLogger:        auditLogger,
+		sync:               sync,
 	}
 }
 
@@ -68,12 +77,9 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get user's role
-	role, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || role == nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to get user role",
-		})
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
 	// Parse request body
@@ -86,50 +92,38 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 	}
 
 	// Validate achievement type
-	validTypes := []string{"academic", "competition", "organization", "publication", "certification", "other"}
-	if !contains(validTypes, req.AchievementType) {
+	if !contains(models.AchievementTypes, req.AchievementType) {
 		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid achievement type. Valid types: %v", validTypes),
+			"error": fmt.Sprintf("Invalid achievement type. Valid types: %v", models.AchievementTypes),
 		})
 	}
 
 	var studentID uuid.UUID
 
-	// Determine target student ID based on role
-	switch role.Name {
-	case "Mahasiswa":
-		// Mahasiswa can only create achievements for themselves
-		student, err := s.studentRepo.GetByUserID(user.ID)
-		if err != nil || student == nil {
-			return c.Status(403).JSON(fiber.Map{
-				"error": "User is not a student or student profile not found",
-			})
-		}
-		studentID = student.ID
-
-	case "Admin":
-		if req.StudentID == nil || *req.StudentID == uuid.Nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error": "student_id is required when creating achievement as admin",
-			})
-		}
-
+	// Target mahasiswa: student_id dari request, atau profil mahasiswa actor sendiri
+	if req.StudentID != nil && *req.StudentID != uuid.Nil {
 		student, err := s.studentRepo.GetByID(*req.StudentID)
 		if err != nil || student == nil {
 			return c.Status(404).JSON(fiber.Map{
 				"error": "Student not found",
 			})
 		}
-		studentID = *req.StudentID
-
-	case "Dosen Wali":
-		return c.Status(403).JSON(fiber.Map{
-			"error": "Dosen Wali cannot create achievements",
+		studentID = student.ID
+	} else if actor.Student != nil {
+		studentID = actor.Student.ID
+	} else {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "student_id is required when you have no student profile",
 		})
+	}
 
-	default:
+	allowed, err := s.authz.CanAccessAchievement(actor, studentID, models.ActionCreate)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
+	}
+	if !allowed {
 		return c.Status(403).JSON(fiber.Map{
-			"error": "Unauthorized role",
+			"error": "You cannot create achievements for this student",
 		})
 	}
 
@@ -146,13 +140,9 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 		UpdatedAt:       time.Now(),
 	}
 
-	mongoID, err := s.achievementRepo.Create(ctx, achievement)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error":   "Failed to create achievement",
-			"details": err.Error(),
-		})
-	}
+	// ID Mongo ditentukan di sini supaya reference dan outbox bisa commit lebih dulu
+	achievement.ID = primitive.NewObjectID()
+	mongoID := achievement.ID
 
 	ref := &models.AchievementReference{
 		ID:                 uuid.New(),
@@ -163,14 +153,29 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 		UpdatedAt:          time.Now(),
 	}
 
-	if err := s.achievementRefRepo.Create(ctx, ref); err != nil {
-		s.achievementRepo.Delete(ctx, mongoID)
+	entry, err := s.sync.NewEntry(models.OutboxOperationCreate, ref.ID, achievement)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create achievement",
+			"details": err.Error(),
+		})
+	}
+
+	if err := s.achievementRefRepo.CreateWithOutbox(ctx, ref, user.ID, entry); err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error":   "Failed to create achievement reference",
 			"details": err.Error(),
 		})
 	}
 
+	// Gagal simpan ke Mongo: reference langsung dikompensasi (atau oleh worker kalau kompensasi ikut gagal)
+	if err := s.sync.Dispatch(ctx, entry); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create achievement",
+			"details": err.Error(),
+		})
+	}
+
 	return c.Status(201).JSON(fiber.Map{
 		"success": true,
 		"message": "Achievement created successfully",
@@ -235,38 +240,15 @@ func (s *AchievementService) GetAchievementByID(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement details not found"})
 	}
 
-	// Get user info untuk validasi access
-	userID, _ := c.Locals("user_id").(uuid.UUID)
-	user, _ := c.Locals("user").(*models.User)
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
+	// Validasi akses: pemilik, dosen wali, atau akses global
+	actor, err := s.authz.Actor(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+		return authorizationErrorResponse(c, err)
 	}
 
-	canAccess := false
-	
-	switch userRole.Name {
-	case "Admin":
-		// Admin bisa akses semua
-		canAccess = true
-		
-	case "Mahasiswa":
-		// Mahasiswa hanya bisa akses miliknya sendiri
-		student, _ := s.studentRepo.GetByUserID(userID)
-		if student != nil && student.ID == ref.StudentID {
-			canAccess = true
-		}
-		
-	case "Dosen Wali":
-		// Dosen hanya bisa akses mahasiswa bimbingannya
-		lecturer, _ := s.lecturerRepo.GetByUserID(userID)
-		if lecturer != nil {
-			// Cek apakah student ini adalah bimbingan dosen
-			student, _ := s.studentRepo.GetByID(ref.StudentID)
-			if student != nil && student.AdvisorID != nil && *student.AdvisorID == lecturer.ID {
-				canAccess = true
-			}
-		}
+	canAccess, err := s.authz.CanAccessAchievement(actor, ref.StudentID, models.ActionRead)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 	
 	if !canAccess {
@@ -319,6 +301,7 @@ func (s *AchievementService) GetAchievementByID(c *fiber.Ctx) error {
 			"verified_at":    ref.VerifiedAt,
 			"verified_by":    verifiedByInfo,
 			"rejection_note": ref.RejectionNote,
+			"resubmission_count": ref.ResubmissionCount,
 			
 			// Student info
 			"student":       studentInfo,
@@ -369,16 +352,19 @@ func (s *AchievementService) GetMyAchievements(c *fiber.Ctx) error {
 		})
 	}
 
+	pageData, err := loadAchievementPage(ctx, s.achievementRepo, s.studentRepo, s.userRepo, refs)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get achievement details",
+			"details": err.Error(),
+		})
+	}
+
 	var achievements []fiber.Map
 	for _, ref := range refs {
-		mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-		if err != nil {
-			continue 
-		}
-
-		achievement, err := s.achievementRepo.FindByID(ctx, mongoID)
-		if err != nil {
-			continue 
+		achievement := pageData.achievement(ref)
+		if achievement == nil {
+			continue
 		}
 
 		achievements = append(achievements, fiber.Map{
@@ -459,42 +445,14 @@ func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement details not found"})
 	}
 
-	// Get user info
-	userID, _ := c.Locals("user_id").(uuid.UUID)
-	user, _ := c.Locals("user").(*models.User)
-	
-	// Get user role
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
+	actor, err := s.authz.Actor(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+		return authorizationErrorResponse(c, err)
 	}
 
-	// Ownership check berdasarkan role
-	switch userRole.Name {
-case "Mahasiswa":
-		// Mahasiswa hanya bisa update miliknya sendiri
-		student, _ := s.studentRepo.GetByUserID(userID)
-		if student == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "Student not found"})
-		}
-		
-		if student.ID != ref.StudentID {
-			return c.Status(403).JSON(fiber.Map{"error": "Not your achievement"})
-		}
-	case "Admin":
-		// Admin bisa update semua
-		// Tidak perlu validasi ownership
-	default:
-		// Dosen & lainnya tidak boleh update
-		return c.Status(403).JSON(fiber.Map{"error": "Unauthorized role"})
-	}
-
-	// Status check (hanya draft yang bisa diupdate)
-	if ref.Status != "draft" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Only draft achievements can be updated",
-			"current_status": ref.Status,
-		})
+	// Relasi & status check lewat tabel transisi (hanya draft milik sendiri / akses global)
+	if _, err := s.authz.AuthorizeTransition(actor, ref, models.ActionEdit); err != nil {
+		return transitionErrorResponse(c, err, ref.Status)
 	}
 
 	// Parse request body sebagai map
@@ -575,10 +533,29 @@ case "Mahasiswa":
 
 	achievement.UpdatedAt = time.Now()
 
-	// Update di MongoDB
-	if err := s.achievementRepo.Update(ctx, mongoID, achievement); err != nil {
+	entry, err := s.sync.NewEntry(models.OutboxOperationUpdate, ref.ID, achievement)
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement"})
 	}
+	// Status dicek ulang di dalam transaksi: edit yang kalah balapan dengan submit / verify ditolak
+	if err := s.achievementRefRepo.EnqueueForAction(ctx, ref.ID, models.ActionEdit, actor.User.ID, entry); err != nil {
+		return transitionErrorResponse(c, err, ref.Status)
+	}
+
+	// Update di MongoDB; kalau gagal perubahan tetap tercatat dan diulang oleh worker
+	if err := s.sync.Dispatch(ctx, entry); err != nil {
+		return c.Status(202).JSON(fiber.Map{
+			"success": true,
+			"message": "Achievement update accepted and will be applied shortly",
+			"data": fiber.Map{
+				"id":          ref.ID,
+				"mongo_id":    mongoID.Hex(),
+				"status":      ref.Status,
+				"updated_at":  achievement.UpdatedAt,
+				"sync_status": models.OutboxStatusPending,
+			},
+		})
+	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -623,44 +600,35 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	// Get user info
 	userID, _ := c.Locals("user_id").(uuid.UUID)
-	user, _ := c.Locals("user").(*models.User)
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
+	actor, err := s.authz.Actor(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+		return authorizationErrorResponse(c, err)
 	}
 
-	// Ownership check berdasarkan role
-	switch userRole.Name {
-case "Mahasiswa":
-		// Mahasiswa hanya bisa delete miliknya sendiri
-		student, _ := s.studentRepo.GetByUserID(userID)
-		if student == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "Student not found"})
-		}
-		
-		if student.ID != ref.StudentID {
-			return c.Status(403).JSON(fiber.Map{"error": "Not your achievement"})
-		}
-	case "Admin":
-	default:
-		return c.Status(403).JSON(fiber.Map{"error": "Unauthorized role"})
+	// Relasi & status check lewat tabel transisi (hanya draft yang bisa di-delete)
+	transition, err := s.authz.AuthorizeTransition(actor, ref, models.ActionDelete)
+	if err != nil {
+		return transitionErrorResponse(c, err, ref.Status)
 	}
 
-	// Status check (hanya draft yang bisa di-delete)
-	if ref.Status != "draft" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Only draft achievements can be deleted",
-			"current_status": ref.Status,
-		})
+	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Invalid MongoDB ID in reference"})
+	}
+	entry, err := s.sync.NewEntry(models.OutboxOperationDelete, ref.ID, &models.Achievement{ID: mongoID})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete achievement"})
 	}
 
-	// SOFT DELETE: Update status ke 'deleted' di PostgreSQL
-	if err := s.achievementRefRepo.SoftDelete(ctx, ref.ID); err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to delete achievement",
-		})
+	// SOFT DELETE: status 'deleted' di PostgreSQL, dokumen Mongo ditandai deletedAt lewat outbox
+	if err := s.achievementRefRepo.SoftDeleteWithOutbox(ctx, ref.ID, userID, entry); err != nil {
+		return transitionErrorResponse(c, err, ref.Status)
+	}
+
+	syncStatus := models.OutboxStatusDone
+	if err := s.sync.Dispatch(ctx, entry); err != nil {
+		syncStatus = models.OutboxStatusPending
 	}
 
 	return c.JSON(fiber.Map{
@@ -670,8 +638,9 @@ case "Mahasiswa":
 			"id":             ref.ID,
 			"mongo_id":       ref.MongoAchievementID,
 			"previous_status": ref.Status,
-			"new_status":     "deleted",
-			"deleted_at":     time.Now(),
+			"new_status":     transition.To,
+			"deleted_at":     entry.CreatedAt,
+			"sync_status":    syncStatus,
 		},
 	})
 }
@@ -690,12 +659,9 @@ func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
 	refUUID, _ := uuid.Parse(refID)
 
 	userID, _ := c.Locals("user_id").(uuid.UUID)
-	user, _ := c.Locals("user").(*models.User)
-
-	// Dapatkan role user dari repository
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
 	// Get reference
@@ -704,25 +670,15 @@ func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	// Cek status
-	if ref.Status != "draft" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("Only draft achievements can be submitted. Current: %s", ref.Status),
-		})
-	}
-
-	// Jika Mahasiswa, cek ownership
-	if userRole.Name == "Mahasiswa" {
-		student, _ := s.studentRepo.GetByUserID(userID)
-		if student == nil || student.ID != ref.StudentID {
-			return c.Status(403).JSON(fiber.Map{"error": "Not your achievement"})
-		}
+	// Cek relasi (pemilik / akses global) & status
+	transition, err := s.authz.AuthorizeTransition(actor, ref, models.ActionSubmit)
+	if err != nil {
+		return transitionErrorResponse(c, err, ref.Status)
 	}
-	// Admin tidak perlu validasi ownership
 
 	// Submit
-	if err := s.achievementRefRepo.SubmitForVerification(ctx, refUUID); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to submit achievement"})
+	if err := s.achievementRefRepo.SubmitForVerification(ctx, refUUID, userID); err != nil {
+		return transitionErrorResponse(c, err, ref.Status)
 	}
 
 	return c.JSON(fiber.Map{
@@ -730,7 +686,7 @@ func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
 		"message": "Achievement submitted",
 		"data": fiber.Map{
 			"id":         ref.ID,
-			"new_status": "submitted",
+			"new_status": transition.To,
 			"submitted_at": time.Now(),
 		},
 	})
@@ -775,27 +731,26 @@ func (s *AchievementService) GetAdviseeAchievements(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get achievement details from MongoDB
+	// Dokumen MongoDB, profil mahasiswa dan user dimuat sekali untuk satu halaman
+	pageData, err := loadAchievementPage(ctx, s.achievementRepo, s.studentRepo, s.userRepo, refs)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get achievement details",
+			"details": err.Error(),
+		})
+	}
+
 	var achievements []fiber.Map
 	for _, ref := range refs {
-		mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-		if err != nil {
-			continue
-		}
-
-		achievement, err := s.achievementRepo.FindByID(ctx, mongoID)
-		if err != nil {
+		achievement := pageData.achievement(ref)
+		if achievement == nil {
 			continue
 		}
 
-		// Get student info
-		student, _ := s.studentRepo.GetByID(ref.StudentID)
-		var studentName string
-		if student != nil {
-			studentUser, _ := s.userRepo.GetByID(student.UserID)
-			if studentUser != nil {
-				studentName = studentUser.FullName
-			}
+		var studentName, studentNIM string
+		if student := pageData.student(ref); student != nil {
+			studentName = pageData.userName(&student.UserID)
+			studentNIM = student.StudentID
 		}
 
 		achievements = append(achievements, fiber.Map{
@@ -807,10 +762,11 @@ func (s *AchievementService) GetAdviseeAchievements(c *fiber.Ctx) error {
 			"submitted_at": ref.SubmittedAt,
 			"verified_at":  ref.VerifiedAt,
 			"created_at":   ref.CreatedAt,
+			"resubmission_count": ref.ResubmissionCount,
 			"student": fiber.Map{
-				"id":   student.ID,
+				"id":   ref.StudentID,
 				"name": studentName,
-				"nim":  student.StudentID,
+				"nim":  studentNIM,
 			},
 		})
 	}
@@ -847,12 +803,9 @@ func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
 	refUUID, _ := uuid.Parse(refID)
 
 	userID, _ := c.Locals("user_id").(uuid.UUID)
-	user, _ := c.Locals("user").(*models.User)
-
-	// Dapatkan role user
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
 	// Get reference
@@ -861,36 +814,25 @@ func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	// Cek status
-	if ref.Status != "submitted" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("Only submitted achievements can be verified. Current: %s", ref.Status),
-		})
-	}
-
-	// Jika user adalah Dosen, cek apakah mahasiswa bimbingannya
-	if userRole.Name == "Dosen Wali" {
-		lecturer, _ := s.lecturerRepo.GetByUserID(userID)
-		student, _ := s.studentRepo.GetByID(ref.StudentID)
-		
-		if lecturer == nil || student == nil || student.AdvisorID == nil || *student.AdvisorID != lecturer.ID {
-			return c.Status(403).JSON(fiber.Map{
-				"error": "You can only verify achievements of your advisees",
-			})
-		}
+	// Cek relasi (dosen wali / akses global) & status
+	transition, err := s.authz.AuthorizeTransition(actor, ref, models.ActionVerify)
+	if err != nil {
+		return transitionErrorResponse(c, err, ref.Status)
 	}
 
 	// Verify
 	if err := s.achievementRefRepo.VerifyAchievement(ctx, refUUID, userID); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to verify achievement"})
+		return transitionErrorResponse(c, err, ref.Status)
 	}
 
+	s.recordTransition(c, models.AuditActionAchievementVerify, ref, "")
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Achievement verified",
 		"data": fiber.Map{
 			"id":         ref.ID,
-			"new_status": "verified",
+			"new_status": transition.To,
 			"verified_by": userID,
 			"verified_at": time.Now(),
 		},
@@ -914,12 +856,9 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 	refUUID, _ := uuid.Parse(refID)
 
 	userID, _ := c.Locals("user_id").(uuid.UUID)
-	user, _ := c.Locals("user").(*models.User)
-
-	// Dapatkan role user
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
 	// Parse rejection note
@@ -936,36 +875,25 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	// Cek status
-	if ref.Status != "submitted" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("Only submitted achievements can be rejected. Current: %s", ref.Status),
-		})
-	}
-
-	// Jika Dosen, cek advisor
-	if userRole.Name == "Dosen Wali" {
-		lecturer, _ := s.lecturerRepo.GetByUserID(userID)
-		student, _ := s.studentRepo.GetByID(ref.StudentID)
-		
-		if lecturer == nil || student == nil || student.AdvisorID == nil || *student.AdvisorID != lecturer.ID {
-			return c.Status(403).JSON(fiber.Map{
-				"error": "You can only reject achievements of your advisees",
-			})
-		}
+	// Cek relasi (dosen wali / akses global) & status
+	transition, err := s.authz.AuthorizeTransition(actor, ref, models.ActionReject)
+	if err != nil {
+		return transitionErrorResponse(c, err, ref.Status)
 	}
 
 	// Reject
 	if err := s.achievementRefRepo.RejectAchievement(ctx, refUUID, userID, req.RejectionNote); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to reject achievement"})
+		return transitionErrorResponse(c, err, ref.Status)
 	}
 
+	s.recordTransition(c, models.AuditActionAchievementReject, ref, req.RejectionNote)
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"message": "Achievement rejected",
 		"data": fiber.Map{
 			"id":             ref.ID,
-			"new_status":     "rejected",
+			"new_status":     transition.To,
 			"rejection_note": req.RejectionNote,
 			"rejected_by":    userID,
 			"rejected_at":    time.Now(),
@@ -973,6 +901,61 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 	})
 }
 
+// ReviseAchievement godoc
+// @Summary Revise rejected achievement
+// @Description Kembalikan prestasi yang ditolak ke status draft agar bisa diperbaiki dan diajukan ulang. Catatan penolakan sebelumnya tetap disimpan.
+// @Tags Achievement
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Achievement Reference ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /achievements/{id}/revise [post]
+func (s *AchievementService) ReviseAchievement(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+
+	refUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid achievement ID"})
+	}
+
+	userID, _ := c.Locals("user_id").(uuid.UUID)
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
+	}
+
+	ref, err := s.achievementRefRepo.FindByID(ctx, refUUID)
+	if err != nil || ref == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
+
+	transition, err := s.authz.AuthorizeTransition(actor, ref, models.ActionRevise)
+	if err != nil {
+		return transitionErrorResponse(c, err, ref.Status)
+	}
+
+	if err := s.achievementRefRepo.ReviseAchievement(ctx, refUUID, userID); err != nil {
+		return transitionErrorResponse(c, err, ref.Status)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Achievement returned to draft for revision",
+		"data": fiber.Map{
+			"id":                 ref.ID,
+			"previous_status":    ref.Status,
+			"new_status":         transition.To,
+			"rejection_note":     ref.RejectionNote,
+			"resubmission_count": ref.ResubmissionCount,
+			"revised_at":         time.Now(),
+		},
+	})
+}
+
 // GetAchievementsByRole godoc
 // @Summary Get achievements by user role
 // @Description
@@ -981,7 +964,7 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 // - Dosen Wali: prestasi mahasiswa bimbingannya
 // - Admin: semua prestasi
 //
-// Mendukung pagination dan filter status.
+// Mendukung pagination, filter, urutan dan pencarian teks (q) atas title, description dan details.
 // Secara default prestasi berstatus "deleted" tidak ditampilkan kecuali diminta eksplisit.
 //
 // @Tags Achievement
@@ -989,10 +972,24 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 // @Produce json
 //
 // @Param status query string false "Filter status (draft, submitted, verified, rejected, deleted)"
+// @Param type query string false "Filter jenis, pisahkan dengan koma (academic, competition, ...)"
+// @Param tags query string false "Filter tag, pisahkan dengan koma; semua tag harus ada"
+// @Param competition_level query string false "Filter tingkat kompetisi (mis. national)"
+// @Param event_from query string false "Tanggal kegiatan sejak (YYYY-MM-DD atau RFC3339)"
+// @Param event_to query string false "Tanggal kegiatan sebelum (YYYY-MM-DD atau RFC3339, eksklusif)"
+// @Param min_points query int false "Poin minimum"
+// @Param max_points query int false "Poin maksimum"
+// @Param program_study query string false "Filter program studi mahasiswa"
+// @Param academic_year query string false "Filter angkatan mahasiswa"
+// @Param q query string false "Pencarian teks"
+// @Param sort query string false "Urutan: created_at (default), submitted_at, verified_at, points, event_date, title, relevance (default jika q diisi)"
+// @Param order query string false "asc atau desc (default: desc)"
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Items per page (default: 10, max: 100)"
+// @Param cursor query string false "Mode cursor (sort created_at/submitted_at/verified_at, tanpa filter dokumen): kosong untuk halaman pertama, lalu next_cursor / prev_cursor"
 //
 // @Success 200 {object} map[string]interface{} "List of achievements with pagination"
+// @Failure 400 {object} map[string]string "Invalid filter or cursor"
 // @Failure 401 {object} map[string]string "Unauthorized"
 // @Failure 403 {object} map[string]string "Access denied"
 // @Failure 500 {object} map[string]string "Server error"
@@ -1000,24 +997,11 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 func (s *AchievementService) GetAchievementsByRole(c *fiber.Ctx) error {
 	ctx := c.UserContext()
 
-	userID, ok := c.Locals("user_id").(uuid.UUID)
-	if !ok {
-		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
-	}
-
-	currentUser, err := s.userRepo.GetByID(userID)
-	if err != nil || currentUser == nil {
-		return c.Status(401).JSON(fiber.Map{"error": "User not found"})
-	}
-
-	// Get role
-	role, err := s.roleRepo.GetByID(currentUser.RoleID)
-	if err != nil || role == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
-	// Get query parameters
-	status := c.Query("status", "")
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 10)
 
@@ -1028,75 +1012,65 @@ func (s *AchievementService) GetAchievementsByRole(c *fiber.Ctx) error {
 		limit = 10
 	}
 
-	// Kecuali jika secara eksplisit meminta status "deleted"
-	var filterStatus string
-	if status == "deleted" {
-		// Hanya tampilkan deleted jika diminta khusus
-		filterStatus = "deleted"
-	} else if status != "" {
-		// Status tertentu (draft, submitted, etc) - exclude deleted
-		filterStatus = status
-	} else {
-		// Tanpa filter status - exclude deleted secara default
-		filterStatus = "" // Repository akan handle exclude deleted
+	// Tanpa filter status, prestasi deleted tidak ditampilkan
+	filter, err := parseAchievementListFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	var refs []*models.AchievementReference
-	var total int
-	var errQuery error
-
-	switch role.Name {
-	case "Mahasiswa":
-		student, _ := s.studentRepo.GetByUserID(userID)
-		if student == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "User is not a student"})
-		}
-		refs, total, errQuery = s.achievementRefRepo.FindByStudentID(ctx, student.ID, filterStatus, page, limit)
-
-	case "Dosen Wali":
-		// Dosen hanya bisa lihat prestasi mahasiswa bimbingannya
-		lecturer, _ := s.lecturerRepo.GetByUserID(userID)
-		if lecturer == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "User is not a lecturer"})
-		}
-		refs, total, errQuery = s.achievementRefRepo.FindByAdvisorID(ctx, lecturer.ID, filterStatus, page, limit)
-
-	case "Admin":
-		// Admin bisa lihat semua prestasi (termasuk deleted jika diminta)
-		refs, total, errQuery = s.achievementRefRepo.FindAll(ctx, filterStatus, page, limit)
-
-	default:
+	scope, err := s.authz.AchievementScope(actor)
+	if err != nil {
 		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
 	}
 
-	if errQuery != nil {
+	cursor, cursorMode, err := queryCursor(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid cursor"})
+	}
+	// Keyset hanya untuk urutan kolom Postgres; filter dan sort dokumen tetap memakai page
+	if cursorMode && (filter.HasDocumentFilter() || filter.IsDocumentSort()) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Cursor pagination only supports created_at, submitted_at and verified_at sorts without document filters, use page instead",
+		})
+	}
+
+	var refs []*models.AchievementReference
+	var total int
+	var cursorPage models.CursorPage
+	if cursorMode {
+		refs, cursorPage, err = s.achievementRefRepo.FindFilteredAfter(ctx, scope, filter, cursor, limit)
+	} else {
+		refs, total, err = s.queryAchievements(ctx, scope, filter, page, limit)
+	}
+	if errors.Is(err, models.ErrInvalidCursor) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid cursor"})
+	}
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error":   "Failed to get achievements",
-			"details": errQuery.Error(),
+			"details": err.Error(),
+		})
+	}
+
+	// Dokumen MongoDB, profil mahasiswa dan user dimuat sekali untuk satu halaman
+	pageData, err := loadAchievementPage(ctx, s.achievementRepo, s.studentRepo, s.userRepo, refs)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get achievement details",
+			"details": err.Error(),
 		})
 	}
 
-	// Get achievement details from MongoDB
 	var achievements []fiber.Map
 	for _, ref := range refs {
-		mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-		if err != nil {
+		achievement := pageData.achievement(ref)
+		if achievement == nil {
 			continue
 		}
 
-		achievement, err := s.achievementRepo.FindByID(ctx, mongoID)
-		if err != nil {
-			continue
-		}
-
-		// Get student info
-		student, _ := s.studentRepo.GetByID(ref.StudentID)
 		var studentName, studentNIM string
-		if student != nil {
-			studentUser, _ := s.userRepo.GetByID(student.UserID)
-			if studentUser != nil {
-				studentName = studentUser.FullName
-			}
+		if student := pageData.student(ref); student != nil {
+			studentName = pageData.userName(&student.UserID)
 			studentNIM = student.StudentID
 		}
 
@@ -1109,14 +1083,23 @@ func (s *AchievementService) GetAchievementsByRole(c *fiber.Ctx) error {
 			"submitted_at": ref.SubmittedAt,
 			"verified_at":  ref.VerifiedAt,
 			"created_at":   ref.CreatedAt,
+			"resubmission_count": ref.ResubmissionCount,
 			"student": fiber.Map{
-				"id":   student.ID,
+				"id":   ref.StudentID,
 				"name": studentName,
 				"nim":  studentNIM,
 			},
 		})
 	}
 
+	if cursorMode {
+		return c.JSON(fiber.Map{
+			"success":    true,
+			"data":       achievements,
+			"pagination": cursorPagination(limit, cursorPage),
+		})
+	}
+
 	totalPages := (total + limit - 1) / limit
 	hasNext := page < totalPages
 	hasPrev := page > 1
@@ -1170,96 +1153,53 @@ func (s *AchievementService) GetAchievementHistory(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	// Get user info
-	userID, _ := c.Locals("user_id").(uuid.UUID)
-	user, _ := c.Locals("user").(*models.User)
-	
-	// Get user role
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
+	// Access control berdasarkan relasi dengan pemilik prestasi
+	actor, err := s.authz.Actor(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+		return authorizationErrorResponse(c, err)
 	}
 
-	// Access control berdasarkan role
-	hasAccess := false
-	
-	switch userRole.Name {
-case "Admin":
-		// Admin bisa akses semua
-		hasAccess = true
-	case "Mahasiswa":
-		// Mahasiswa hanya bisa akses miliknya sendiri
-		student, _ := s.studentRepo.GetByUserID(userID)
-		if student != nil && student.ID == ref.StudentID {
-			hasAccess = true
-		}
-	case "Dosen Wali":
-		// Dosen hanya bisa akses mahasiswa bimbingannya
-		lecturer, _ := s.lecturerRepo.GetByUserID(userID)
-		if lecturer != nil {
-			student, _ := s.studentRepo.GetByID(ref.StudentID)
-			if student != nil && student.AdvisorID != nil && *student.AdvisorID == lecturer.ID {
-				hasAccess = true
-			}
-		}
+	hasAccess, err := s.authz.CanAccessAchievement(actor, ref.StudentID, models.ActionRead)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
 	if !hasAccess {
 		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
 	}
 
-	// Create history dari reference data
-	history := []fiber.Map{
-		{
-			"status":    "draft",
-			"timestamp": ref.CreatedAt,
-			"note":      "Achievement created",
-		},
-	}
-
-	// Add submitted event jika ada
-	if ref.SubmittedAt != nil {
-		history = append(history, fiber.Map{
-			"status":    "submitted",
-			"timestamp": *ref.SubmittedAt,
-			"note":      "Submitted for verification",
+	// Ambil riwayat status yang tersimpan di achievement_status_history
+	events, err := s.achievementRefRepo.FindStatusHistory(ctx, ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get achievement history",
+			"details": err.Error(),
 		})
 	}
 
-	// Add verified/rejected event jika ada
-	if ref.VerifiedAt != nil {
-		action := "verified"
-		note := "Achievement verified"
-		
-		if ref.Status == "rejected" {
-			action = "rejected"
-			note = fmt.Sprintf("Achievement rejected: %s", *ref.RejectionNote)
-		}
-
-		// Get verified by user info
-		var verifiedByName string
-		if ref.VerifiedBy != nil {
-			verifiedUser, _ := s.userRepo.GetByID(*ref.VerifiedBy)
-			if verifiedUser != nil {
-				verifiedByName = verifiedUser.FullName
+	actorNames := make(map[uuid.UUID]string)
+	history := make([]fiber.Map, 0, len(events))
+	for _, event := range events {
+		var actorName string
+		if event.ActorID != nil {
+			name, cached := actorNames[*event.ActorID]
+			if !cached {
+				if actor, _ := s.userRepo.GetByID(*event.ActorID); actor != nil {
+					name = actor.FullName
+				}
+				actorNames[*event.ActorID] = name
 			}
+			actorName = name
 		}
 
 		history = append(history, fiber.Map{
-			"status":           action,
-			"timestamp":        *ref.VerifiedAt,
-			"verified_by":      ref.VerifiedBy,
-			"verified_by_name": verifiedByName,
-			"note":             note,
-		})
-	}
-
-	// Add deleted event jika status deleted
-	if ref.Status == "deleted" {
-		history = append(history, fiber.Map{
-			"status":    "deleted",
-			"timestamp": ref.UpdatedAt, // updated_at saat di-delete
-			"note":      "Achievement deleted",
+			"id":          event.ID,
+			"status":      event.ToStatus,
+			"from_status": event.FromStatus,
+			"timestamp":   event.CreatedAt,
+			"actor_id":    event.ActorID,
+			"actor_name":  actorName,
+			"note":        event.Note,
 		})
 	}
 
@@ -1317,13 +1257,9 @@ func (s *AchievementService) UploadAttachments(c *fiber.Ctx) error {
 	refID := c.Params("id")
 	refUUID, _ := uuid.Parse(refID)
 
-	userID, _ := c.Locals("user_id").(uuid.UUID)
-	user, _ := c.Locals("user").(*models.User)
-
-	// Dapatkan role user dari repository
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
 	// Get reference
@@ -1332,19 +1268,10 @@ func (s *AchievementService) UploadAttachments(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	// Cek status
-	if ref.Status != "draft" {
-		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("Only draft achievements can have attachments uploaded. Current: %s", ref.Status),
-		})
-	}
-
-	// Jika Mahasiswa, cek ownership
-	if userRole.Name == "Mahasiswa" {
-		student, _ := s.studentRepo.GetByUserID(userID)
-		if student == nil || student.ID != ref.StudentID {
-			return c.Status(403).JSON(fiber.Map{"error": "Not your achievement"})
-		}
+	// Cek relasi (pemilik / akses global) & status
+	_, err = s.authz.AuthorizeTransition(actor, ref, models.ActionAttach)
+	if err != nil {
+		return transitionErrorResponse(c, err, ref.Status)
 	}
 
 	// Parse multipart form
@@ -1431,9 +1358,9 @@ func (s *AchievementService) UploadAttachments(c *fiber.Ctx) error {
 		// Create attachment object
 		attachment := models.Attachment{
 			ID:         uuid.New(),
-			FileName:   safeFileName, 
-			FileURL:    "/" + filePath,
-			FileType:   cleanContentType.String(), 
+			FileName:   cleanString(safeFileName), 
+			FileURL:    "/" + cleanString(filePath),
+			FileType:   cleanString(cleanContentType.String()), 
 			FileSize:   file.Size,
 			UploadedAt: time.Now(),
 		}
@@ -1456,34 +1383,26 @@ func (s *AchievementService) UploadAttachments(c *fiber.Ctx) error {
 
 	// Update achievement with new attachments
 	achievement.Attachments = append(achievement.Attachments, newAttachments...)
+	achievement.UpdatedAt = time.Now()
 
-	if err := s.achievementRepo.Update(ctx, mongoID, achievement); err != nil {
-		// Cek apakah error karena UTF-8
-		if strings.Contains(err.Error(), "UTF-8") {
-			// Coba cleanup data sebelum save ulang
-			for i := range achievement.Attachments {
-				// Pastikan semua string field bersih
-				achievement.Attachments[i].FileName = cleanString(achievement.Attachments[i].FileName)
-				achievement.Attachments[i].FileType = cleanString(achievement.Attachments[i].FileType)
-				achievement.Attachments[i].FileURL = cleanString(achievement.Attachments[i].FileURL)
-			}
-			
-			// Coba save lagi
-			if err := s.achievementRepo.Update(ctx, mongoID, achievement); err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"error": "Failed to update achievement attachments after cleanup",
-				})
-			}
-		} else {
-			return c.Status(500).JSON(fiber.Map{
-				"error": "Failed to update achievement attachments",
-			})
-		}
+	entry, err := s.sync.NewEntry(models.OutboxOperationUpdate, ref.ID, achievement)
+	if err != nil {
+		removeUploadedFiles(newAttachments)
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to update achievement attachments",
+		})
 	}
 
-	// Update reference timestamp
-	if err := s.achievementRefRepo.UpdateStatus(ctx, ref.ID, "draft", nil, nil); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement reference"})
+	// Status dicek ulang di dalam transaksi; file yang sudah tersimpan dibuang jika ditolak
+	if err := s.achievementRefRepo.EnqueueForAction(ctx, ref.ID, models.ActionAttach, actor.User.ID, entry); err != nil {
+		removeUploadedFiles(newAttachments)
+		return transitionErrorResponse(c, err, ref.Status)
+	}
+
+	syncStatus := models.OutboxStatusDone
+	if err := s.sync.Dispatch(ctx, entry); err != nil {
+		// Perubahan tetap tercatat di outbox dan diulang oleh worker
+		syncStatus = models.OutboxStatusPending
 	}
 
 	return c.JSON(fiber.Map{
@@ -1494,11 +1413,60 @@ func (s *AchievementService) UploadAttachments(c *fiber.Ctx) error {
 			"new_attachments": uploadedAttachments,
 			"total_files":     len(uploadedAttachments),
 			"uploaded_at":     time.Now(),
+			"sync_status":     syncStatus,
 		},
 	})
 }
 
+// transitionErrorResponse memetakan error dari state machine prestasi ke response HTTP
+// recordTransition mencatat verifikasi/penolakan ke audit log dengan snapshot reference sebelum dan sesudah
+func (s *AchievementService) recordTransition(c *fiber.Ctx, action string, before *models.AchievementReference, reason string) {
+	after, err := s.achievementRefRepo.FindByID(c.UserContext(), before.ID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to load achievement %s for audit snapshot: %v\n", before.ID, err)
+	}
+	s.auditLogger.Record(c, AuditEvent{
+		Action:     action,
+		TargetType: models.AuditTargetAchievement,
+		TargetID:   before.ID.String(),
+		Reason:     reason,
+		Before:     before,
+		After:      after,
+	})
+}
+
+func transitionErrorResponse(c *fiber.Ctx, err error, currentStatus string) error {
+	switch {
+	case errors.Is(err, models.ErrTransitionForbidden):
+		return c.Status(403).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, models.ErrInvalidTransition):
+		return c.Status(400).JSON(fiber.Map{
+			"error":          err.Error(),
+			"current_status": currentStatus,
+		})
+	case errors.Is(err, models.ErrStatusConflict):
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Achievement status was changed by another request, please reload",
+			"details": err.Error(),
+		})
+	default:
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to update achievement status",
+			"details": err.Error(),
+		})
+	}
+}
+
 // Simple clean string function (tetap dalam scope yang sama)
+// removeUploadedFiles membuang file lampiran yang tidak jadi disimpan ke prestasi
+func removeUploadedFiles(attachments []models.Attachment) {
+	for _, attachment := range attachments {
+		if err := os.Remove(strings.TrimPrefix(attachment.FileURL, "/")); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Warning: Failed to remove uploaded file %s: %v\n", attachment.FileURL, err)
+		}
+	}
+}
+
 func cleanString(s string) string {
 	var result strings.Builder
 	for _, r := range s {
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/config"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AchievementSync menjaga achievement_references (Postgres) dan koleksi achievements (Mongo)
+// tetap konsisten. Setiap perubahan dicatat dulu di achievement_outbox bersama perubahan
+// reference, lalu operasi Mongo dijalankan langsung oleh request (Dispatch). Kalau request
+// gagal atau proses mati di tengah jalan, worker (Run) menyelesaikan atau mengkompensasi.
+type AchievementSync struct {
+	outboxRepo      repository.AchievementOutboxRepository
+	achievementRepo repository.AchievementRepository
+	cfg             config.AchievementSyncConfig
+}
+
+func NewAchievementSync(outboxRepo repository.AchievementOutboxRepository, achievementRepo repository.AchievementRepository, cfg config.AchievementSyncConfig) *AchievementSync {
+	return &AchievementSync{
+		outboxRepo:      outboxRepo,
+		achievementRepo: achievementRepo,
+		cfg:             cfg,
+	}
+}
+
+// NewEntry menyiapkan baris outbox. next_attempt_at digeser sejauh lease supaya
+// worker tidak ikut memproses selama request masih menjalankan Dispatch.
+func (s *AchievementSync) NewEntry(operation string, refID uuid.UUID, achievement *models.Achievement) (*models.AchievementOutbox, error) {
+	now := time.Now()
+	entry := &models.AchievementOutbox{
+		ID:                 uuid.New(),
+		Operation:          operation,
+		ReferenceID:        refID,
+		MongoAchievementID: achievement.ID.Hex(),
+		Status:             models.OutboxStatusPending,
+		NextAttemptAt:      now.Add(s.cfg.Lease),
+		CreatedAt:          now,
+	}
+
+	if operation != models.OutboxOperationDelete {
+		payload, err := bson.MarshalExtJSON(achievement, true, false)
+		if err != nil {
+			return nil, err
+		}
+		entry.Payload = payload
+	}
+	return entry, nil
+}
+
+// Dispatch menjalankan operasi Mongo setelah transaksi Postgres commit.
+// Create yang gagal langsung dikompensasi; update/delete dijadwalkan ulang untuk worker.
+func (s *AchievementSync) Dispatch(ctx context.Context, entry *models.AchievementOutbox) error {
+	err := s.apply(ctx, entry)
+	if err == nil {
+		if markErr := s.outboxRepo.MarkDone(ctx, entry.ID); markErr != nil {
+			// Operasi Mongo idempotent, worker cukup mengulang lalu menandai selesai
+			log.Printf("Warning: failed to mark outbox %s done: %v", entry.ID, markErr)
+		}
+		return nil
+	}
+
+	if entry.Operation == models.OutboxOperationCreate {
+		s.compensate(ctx, entry, err)
+		return err
+	}
+
+	s.retry(ctx, entry, err)
+	return err
+}
+
+// Run memproses outbox secara berkala sampai ctx dibatalkan
+func (s *AchievementSync) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.processDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *AchievementSync) processDue(ctx context.Context) {
+	entries, err := s.outboxRepo.ClaimDue(ctx, s.cfg.BatchSize, s.cfg.Lease)
+	if err != nil {
+		log.Printf("Warning: failed to claim achievement outbox: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.Status == models.OutboxStatusCompensating {
+			s.compensate(ctx, entry, nil)
+			continue
+		}
+
+		err := s.apply(ctx, entry)
+		if err == nil {
+			if err := s.outboxRepo.MarkDone(ctx, entry.ID); err != nil {
+				log.Printf("Warning: failed to mark outbox %s done: %v", entry.ID, err)
+			}
+			continue
+		}
+
+		if entry.Attempts+1 < s.cfg.MaxAttempts {
+			s.retry(ctx, entry, err)
+			continue
+		}
+
+		// Percobaan habis: create dibatalkan, update/delete ditandai failed dan dibiarkan
+		// di tabel supaya bisa ditelusuri
+		if entry.Operation == models.OutboxOperationCreate {
+			s.compensate(ctx, entry, err)
+			continue
+		}
+		log.Printf("Achievement outbox %s (%s %s) failed after %d attempts: %v",
+			entry.ID, entry.Operation, entry.MongoAchievementID, entry.Attempts+1, err)
+		if err := s.outboxRepo.MarkFailed(ctx, entry.ID, err.Error()); err != nil {
+			log.Printf("Warning: failed to mark outbox %s failed: %v", entry.ID, err)
+		}
+	}
+}
+
+// apply operasi Mongo; semuanya idempotent karena bisa diulang oleh worker
+func (s *AchievementSync) apply(ctx context.Context, entry *models.AchievementOutbox) error {
+	switch entry.Operation {
+	case models.OutboxOperationCreate, models.OutboxOperationUpdate:
+		var achievement models.Achievement
+		if err := bson.UnmarshalExtJSON(entry.Payload, true, &achievement); err != nil {
+			return fmt.Errorf("invalid outbox payload: %w", err)
+		}
+		if entry.Operation == models.OutboxOperationCreate {
+			return s.achievementRepo.CreateIfNotExists(ctx, &achievement)
+		}
+		return s.achievementRepo.UpdateIfNotNewer(ctx, &achievement)
+
+	case models.OutboxOperationDelete:
+		mongoID, err := primitive.ObjectIDFromHex(entry.MongoAchievementID)
+		if err != nil {
+			return fmt.Errorf("invalid mongo id %q: %w", entry.MongoAchievementID, err)
+		}
+		return s.achievementRepo.MarkDeleted(ctx, mongoID, entry.CreatedAt)
+	}
+
+	return fmt.Errorf("unknown outbox operation %q", entry.Operation)
+}
+
+// compensate membatalkan create: dokumen Mongo (kalau sempat tersimpan) dan reference dihapus.
+// Kalau kompensasi juga gagal, baris ditandai compensating dan diulang worker.
+func (s *AchievementSync) compensate(ctx context.Context, entry *models.AchievementOutbox, cause error) {
+	err := s.compensateCreate(ctx, entry)
+	if err == nil {
+		log.Printf("Achievement outbox %s compensated: reference %s removed (cause: %v)", entry.ID, entry.ReferenceID, cause)
+		return
+	}
+
+	log.Printf("Warning: failed to compensate achievement outbox %s: %v", entry.ID, err)
+	if entry.Status == models.OutboxStatusCompensating {
+		s.retry(ctx, entry, err)
+		return
+	}
+	if err := s.outboxRepo.MarkCompensating(ctx, entry.ID, err.Error()); err != nil {
+		log.Printf("Warning: failed to mark outbox %s compensating: %v", entry.ID, err)
+	}
+}
+
+func (s *AchievementSync) compensateCreate(ctx context.Context, entry *models.AchievementOutbox) error {
+	mongoID, err := primitive.ObjectIDFromHex(entry.MongoAchievementID)
+	if err == nil {
+		if err := s.achievementRepo.Delete(ctx, mongoID); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+	}
+	return s.outboxRepo.CompensateCreate(ctx, entry)
+}
+
+func (s *AchievementSync) retry(ctx context.Context, entry *models.AchievementOutbox, cause error) {
+	if err := s.outboxRepo.MarkRetry(ctx, entry.ID, cause.Error(), time.Now().Add(s.backoff(entry.Attempts))); err != nil {
+		log.Printf("Warning: failed to reschedule outbox %s: %v", entry.ID, err)
+	}
+}
+
+// backoff jeda sebelum percobaan berikutnya, berlipat dua tiap kegagalan sampai RetryMax
+func (s *AchievementSync) backoff(attempts int) time.Duration {
+	delay := s.cfg.RetryBase
+	for i := 0; i < attempts && delay < s.cfg.RetryMax; i++ {
+		delay *= 2
+	}
+	if delay > s.cfg.RetryMax {
+		delay = s.cfg.RetryMax
+	}
+	return delay
+}
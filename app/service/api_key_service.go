@@ -0,0 +1,220 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// GetMyAPIKeys godoc
+// @Summary List my API keys
+// @Description Daftar API key milik user yang sedang login (tanpa secret)
+// @Tags API Key
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api-keys [get]
+func (s *APIKeyService) GetMyAPIKeys(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	keys, err := s.apiKeyRepo.GetByUser(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get API keys",
+			"details": err.Error(),
+		})
+	}
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": keys,
+	})
+}
+
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description Membuat API key untuk script/integrasi dengan subset permission milik user. Key hanya ditampilkan sekali.
+// @Tags API Key
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.CreateAPIKeyRequest true "Create API key payload"
+// @Success 201 {object} models.CreateAPIKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api-keys [post]
+func (s *APIKeyService) CreateAPIKey(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+	granted, _ := c.Locals("permissions").([]string)
+
+	var req models.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Name is required (max 100 characters)",
+		})
+	}
+
+	if len(req.Permissions) == 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "At least one permission is required",
+		})
+	}
+
+	permissions, missing := scopePermissions(req.Permissions, granted)
+	if len(missing) > 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "Cannot grant permissions you do not have",
+			"details": strings.Join(missing, ", "),
+		})
+	}
+
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "expiresAt must be in the future",
+		})
+	}
+
+	rawKey, prefix, err := utils.GenerateAPIKey()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate API key",
+			"details": err.Error(),
+		})
+	}
+
+	key := &models.APIKey{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        req.Name,
+		Prefix:      prefix,
+		KeyHash:     utils.HashToken(rawKey),
+		Permissions: permissions,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedAt:   now,
+	}
+
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		if errors.Is(err, models.ErrPermissionNotFound) {
+			return c.Status(400).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create API key",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "API key created successfully, store it now because it will not be shown again",
+		"data": models.CreateAPIKeyResponse{
+			APIKey: *key,
+			Key:    rawKey,
+		},
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke API key
+// @Tags API Key
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "API key UUID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api-keys/{id} [delete]
+func (s *APIKeyService) RevokeAPIKey(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid API key ID",
+		})
+	}
+
+	if err := s.apiKeyRepo.Revoke(id, userID); err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			return c.Status(404).JSON(fiber.Map{
+				"error": "API key not found or already revoked",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to revoke API key",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "API key revoked successfully",
+	})
+}
+
+// scopePermissions menghapus duplikat dan mengembalikan permission yang tidak dimiliki user
+func scopePermissions(requested, granted []string) ([]string, []string) {
+	seen := make(map[string]bool)
+	var scope, missing []string
+	for _, p := range requested {
+		p = strings.TrimSpace(p)
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+
+		actor := Actor{Permissions: granted}
+		if actor.HasPermission(p) {
+			scope = append(scope, p)
+		} else {
+			missing = append(missing, p)
+		}
+	}
+	return scope, missing
+}
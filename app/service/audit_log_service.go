@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AuditLogService struct {
+	auditLogRepo repository.AuditLogRepository
+}
+
+func NewAuditLogService(auditLogRepo repository.AuditLogRepository) *AuditLogService {
+	return &AuditLogService{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// GetAll godoc
+// @Summary List audit logs
+// @Description Jejak audit aksi admin, verifikasi prestasi dan impersonation, terbaru lebih dulu.
+// @Description from/to dalam format RFC3339; to eksklusif.
+// @Tags Audit
+// @Security BearerAuth
+// @Produce json
+// @Param actor_id query string false "Filter actor (user UUID)"
+// @Param impersonator_id query string false "Filter admin yang impersonate (user UUID)"
+// @Param action query string false "Filter action (mis. user.update, achievement.verify)"
+// @Param target_type query string false "Filter jenis target (user, student, achievement)"
+// @Param target_id query string false "Filter ID target"
+// @Param from query string false "Sejak waktu (RFC3339)"
+// @Param to query string false "Sampai sebelum waktu (RFC3339)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit per page (max 100)"
+// @Param cursor query string false "Mode cursor: kosong untuk halaman pertama, lalu next_cursor / prev_cursor"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /audit-logs [get]
+func (s *AuditLogService) GetAll(c *fiber.Ctx) error {
+	filter := models.AuditLogFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Page:       c.QueryInt("page", 1),
+		Limit:      c.QueryInt("limit", 20),
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	var err error
+	if filter.ActorID, err = queryUUID(c, "actor_id"); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid actor_id",
+		})
+	}
+	if filter.ImpersonatorID, err = queryUUID(c, "impersonator_id"); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid impersonator_id",
+		})
+	}
+	if filter.From, err = queryTime(c, "from"); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid from, expected RFC3339",
+		})
+	}
+	if filter.To, err = queryTime(c, "to"); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid to, expected RFC3339",
+		})
+	}
+
+	cursor, cursorMode, err := queryCursor(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid cursor",
+		})
+	}
+	if cursorMode {
+		entries, cursorPage, err := s.auditLogRepo.ListAfter(filter, cursor)
+		if errors.Is(err, models.ErrInvalidCursor) {
+			return c.Status(400).JSON(fiber.Map{
+				"error": "Invalid cursor",
+			})
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "Failed to get audit logs",
+				"details": err.Error(),
+			})
+		}
+		if entries == nil {
+			entries = []models.AuditLog{}
+		}
+		return c.JSON(fiber.Map{
+			"data":       entries,
+			"pagination": cursorPagination(filter.Limit, cursorPage),
+		})
+	}
+
+	entries, total, err := s.auditLogRepo.List(filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get audit logs",
+			"details": err.Error(),
+		})
+	}
+	if entries == nil {
+		entries = []models.AuditLog{}
+	}
+
+	totalPages := (total + filter.Limit - 1) / filter.Limit
+
+	return c.JSON(fiber.Map{
+		"data": entries,
+		"pagination": fiber.Map{
+			"page":        filter.Page,
+			"limit":       filter.Limit,
+			"total":       total,
+			"total_pages": totalPages,
+			"has_next":    filter.Page < totalPages,
+			"has_prev":    filter.Page > 1,
+		},
+	})
+}
+
+func queryUUID(c *fiber.Ctx, key string) (*uuid.UUID, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func queryTime(c *fiber.Ctx, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AuditLogger mencatat aksi administratif dan verifikasi dari service layer.
+// Actor, impersonator dan metadata request diambil dari locals RequireAuth.
+type AuditLogger struct {
+	auditLogRepo repository.AuditLogRepository
+}
+
+func NewAuditLogger(auditLogRepo repository.AuditLogRepository) *AuditLogger {
+	return &AuditLogger{auditLogRepo: auditLogRepo}
+}
+
+// AuditEvent aksi yang dicatat; Before/After nil jika tidak relevan (mis. create tidak punya Before)
+type AuditEvent struct {
+	Action     string
+	TargetType string
+	TargetID   string
+	Reason     string
+	Before     interface{}
+	After      interface{}
+}
+
+// Entry membangun entri audit tanpa menyimpannya. Perubahan user dan role meneruskan
+// entri ini ke repository supaya ditulis dalam transaksi yang sama dengan perubahannya.
+func (a *AuditLogger) Entry(c *fiber.Ctx, event AuditEvent) *models.AuditLog {
+	entry := &models.AuditLog{
+		ID:         uuid.New(),
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		Reason:     event.Reason,
+		Before:     auditSnapshot(event.Before),
+		After:      auditSnapshot(event.After),
+		Method:     c.Method(),
+		Path:       c.OriginalURL(),
+		IPAddress:  c.IP(),
+		UserAgent:  truncate(c.Get("User-Agent"), 255),
+		CreatedAt:  time.Now(),
+	}
+	if actorID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		entry.ActorID = &actorID
+	}
+	if impersonator, _ := c.Locals("impersonator").(*models.User); impersonator != nil {
+		entry.ImpersonatorID = &impersonator.ID
+	}
+	if sessionID, ok := c.Locals("session_id").(uuid.UUID); ok {
+		entry.SessionID = &sessionID
+	}
+	return entry
+}
+
+// Record dipanggil setelah aksi berhasil. Gagal menulis audit hanya di-log,
+// tidak membatalkan aksi yang sudah terjadi.
+func (a *AuditLogger) Record(c *fiber.Ctx, event AuditEvent) {
+	entry := a.Entry(c, event)
+	if err := a.auditLogRepo.Create(entry); err != nil {
+		fmt.Printf("Warning: Failed to write audit log %s for %s %s: %v\n", event.Action, event.TargetType, event.TargetID, err)
+	}
+}
+
+func auditSnapshot(value interface{}) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		fmt.Printf("Warning: Failed to encode audit snapshot: %v\n", err)
+		return nil
+	}
+	if string(data) == "null" {
+		return nil
+	}
+	return data
+}
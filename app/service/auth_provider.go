@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/config"
+	"achievement-backend/utils"
+)
+
+var (
+	errInvalidCredentials = errors.New("invalid credentials")
+	errNoLocalAccount     = errors.New("account is not registered in this system")
+)
+
+// Nama provider untuk AUTH_PROVIDERS
+const (
+	AuthProviderLocal = "local"
+	AuthProviderLDAP  = "ldap"
+)
+
+// LoginCredentials data /auth/login beserta user lokal hasil lookup username/email (nil jika tidak ada)
+type LoginCredentials struct {
+	Username string
+	Email    string
+	Password string
+	User     *models.User
+}
+
+// AuthProvider satu backend pemeriksa password. AuthService mencoba provider berurutan;
+// errInvalidCredentials berarti lanjut ke provider berikutnya.
+type AuthProvider interface {
+	Name() string
+	Authenticate(creds LoginCredentials) (*models.User, error)
+}
+
+// NewAuthProviders membangun provider sesuai urutan AUTH_PROVIDERS
+func NewAuthProviders(
+	cfg config.AuthProvidersConfig,
+	ldapConfig config.LDAPConfig,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	lecturerRepo repository.LecturerRepository,
+	identityRepo repository.OIDCRepository,
+) ([]AuthProvider, error) {
+	var providers []AuthProvider
+	for _, name := range cfg.Providers {
+		switch name {
+		case AuthProviderLocal:
+			providers = append(providers, NewLocalAuthProvider())
+		case AuthProviderLDAP:
+			providers = append(providers, NewLDAPAuthProvider(
+				utils.NewLDAPAuthenticator(ldapConfig, nil),
+				userRepo, roleRepo, lecturerRepo, identityRepo,
+				ldapConfig.AutoProvision,
+			))
+		default:
+			return nil, fmt.Errorf("unsupported auth provider %q", name)
+		}
+	}
+	return providers, nil
+}
+
+// localAuthProvider password bcrypt di users.password_hash
+type localAuthProvider struct{}
+
+func NewLocalAuthProvider() AuthProvider {
+	return &localAuthProvider{}
+}
+
+func (p *localAuthProvider) Name() string {
+	return AuthProviderLocal
+}
+
+func (p *localAuthProvider) Authenticate(creds LoginCredentials) (*models.User, error) {
+	if creds.User == nil || !utils.CheckPasswordHash(creds.Password, creds.User.PasswordHash) {
+		return nil, errInvalidCredentials
+	}
+	return creds.User, nil
+}
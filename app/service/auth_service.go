@@ -1,7 +1,9 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"achievement-backend/app/models"
 	"achievement-backend/app/repository"
@@ -16,24 +18,46 @@ type AuthService struct {
 	roleRepo repository.RoleRepository
 	studentRepo  repository.StudentRepository  
 	lecturerRepo repository.LecturerRepository 
+	refreshTokenRepo repository.RefreshTokenRepository
+	sessionRepo repository.SessionRepository
+	twoFactorRepo repository.TwoFactorRepository
+	loginThrottle *LoginThrottle
+	passwordPolicy *PasswordPolicy
+	providers []AuthProvider
 }
 
 func NewAuthService(
 	userRepo repository.UserRepository,
 	roleRepo repository.RoleRepository,
 	studentRepo repository.StudentRepository,   
-	lecturerRepo repository.LecturerRepository, ) *AuthService {
+	lecturerRepo repository.LecturerRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	sessionRepo repository.SessionRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	loginThrottle *LoginThrottle,
+	passwordPolicy *PasswordPolicy,
+	providers []AuthProvider,
+) *AuthService {
 	return &AuthService{
 		userRepo:     userRepo,
 		roleRepo:     roleRepo,
 		studentRepo:  studentRepo,   
 		lecturerRepo: lecturerRepo,  
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo: sessionRepo,
+		twoFactorRepo: twoFactorRepo,
+		loginThrottle: loginThrottle,
+		passwordPolicy: passwordPolicy,
+		providers: providers,
 	}
 }
 
 // Login godoc
 // @Summary Login user
-// @Description Login menggunakan username atau email dan password
+// @Description Login menggunakan username atau email dan password. Password diperiksa oleh provider
+// @Description sesuai urutan AUTH_PROVIDERS (local bcrypt, LDAP bind).
+// @Description Jika user mengaktifkan 2FA (atau role-nya mewajibkan), respon berisi twoFactorRequired dan challengeToken
+// @Description yang ditukar di /auth/2fa/verify (atau /auth/2fa/enroll bila setupRequired).
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -41,8 +65,10 @@ func NewAuthService(
 // @Success 200 {object} models.LoginResponse "Login berhasil"
 // @Failure 400 {object} object{error=string} "Invalid request body"
 // @Failure 401 {object} object{error=string} "Invalid credentials"
-// @Failure 403 {object} object{error=string} "Account is inactive"
+// @Failure 403 {object} object{error=string} "Account is inactive / not registered"
+// @Failure 429 {object} object{error=string} "Too many failed attempts / account locked (lihat header Retry-After)"
 // @Failure 500 {object} object{error=string,details=string} "Server error"
+// @Failure 503 {object} object{error=string,details=string} "Authentication backend (LDAP) unavailable"
 // @Router /auth/login [post]
 func (s *AuthService) Login(c *fiber.Ctx) error {
 	var req models.LoginRequest
@@ -52,8 +78,24 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		})
 	}
 
+	ip := c.IP()
+	identifier := req.Username
+	if identifier == "" {
+		identifier = req.Email
+	}
+
+	block, err := s.loginThrottle.CheckIP(ip)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Error checking login attempts",
+			"details": err.Error(),
+		})
+	}
+	if block != nil {
+		return loginBlockedResponse(c, block)
+	}
+
 	var user *models.User
-	var err error
 
 	user, err = s.userRepo.GetByUsername(req.Username)
 	if err != nil {
@@ -73,17 +115,50 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		}
 	}
 
-	if user == nil {
-		return c.Status(401).JSON(fiber.Map{
-			"error": "Invalid credentials",
-		})
+	if user != nil {
+		block, err = s.loginThrottle.CheckAccount(user.ID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error": "Error checking login attempts",
+				"details": err.Error(),
+			})
+		}
+		if block != nil {
+			return loginBlockedResponse(c, block)
+		}
 	}
 
-	if !utils.CheckPasswordHash(req.Password, user.PasswordHash) {
-		return c.Status(401).JSON(fiber.Map{
-			"error": "Invalid credentials",
+	// User yang belum ada di database lokal tetap dicoba ke provider lain (mis. LDAP)
+	authenticated, err := s.authenticate(LoginCredentials{
+		Username: req.Username,
+		Email:    req.Email,
+		Password: req.Password,
+		User:     user,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidCredentials):
+			var userID *uuid.UUID
+			if user != nil {
+				userID = &user.ID
+			}
+			if err := s.loginThrottle.RecordFailure(identifier, userID, ip); err != nil {
+				fmt.Printf("Warning: Failed to record login attempt: %v\n", err)
+			}
+			return c.Status(401).JSON(fiber.Map{
+				"error": "Invalid credentials",
+			})
+		case errors.Is(err, errNoLocalAccount):
+			return c.Status(403).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(503).JSON(fiber.Map{
+			"error": "Authentication backend unavailable",
+			"details": err.Error(),
 		})
 	}
+	user = authenticated
 
 	if !user.IsActive {
 		return c.Status(403).JSON(fiber.Map{
@@ -99,13 +174,19 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	permissions, err := s.roleRepo.GetPermissionNamesByRoleID(user.RoleID)
+	// Password benar tapi 2FA aktif / diwajibkan: kembalikan challenge token, bukan token akses
+	challenge, err := s.twoFactorChallenge(user, role)
 	if err != nil {
-		permissions = []string{}
-		fmt.Printf("Warning: Failed to get permissions for role %s: %v\n", role.Name, err)
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to create two-factor challenge",
+			"details": err.Error(),
+		})
+	}
+	if challenge != nil {
+		return c.JSON(challenge)
 	}
 
-	token, err := utils.GenerateToken(user, permissions)
+	response, err := s.completeLogin(c, user, role, identifier)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to generate token",
@@ -113,23 +194,98 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(user.ID)
+	return c.JSON(response)
+}
+
+// authenticate mencoba provider sesuai urutan AUTH_PROVIDERS. Kegagalan backend (mis. LDAP mati)
+// tidak menghentikan provider berikutnya, tapi dilaporkan jika tidak ada yang berhasil.
+func (s *AuthService) authenticate(creds LoginCredentials) (*models.User, error) {
+	var backendErr error
+	for _, provider := range s.providers {
+		user, err := provider.Authenticate(creds)
+		if err == nil {
+			return user, nil
+		}
+		if errors.Is(err, errInvalidCredentials) {
+			continue
+		}
+		if errors.Is(err, errNoLocalAccount) {
+			return nil, err
+		}
+		fmt.Printf("Warning: Auth provider %s failed: %v\n", provider.Name(), err)
+		backendErr = err
+	}
+	if backendErr != nil {
+		return nil, backendErr
+	}
+	return nil, errInvalidCredentials
+}
+
+// twoFactorChallenge nil jika user tidak perlu langkah 2FA
+func (s *AuthService) twoFactorChallenge(user *models.User, role *models.Role) (*models.TwoFactorChallengeResponse, error) {
+	totp, err := s.twoFactorRepo.GetByUserID(user.ID)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to generate refresh token",
-			"details": err.Error(),
-		})
+		return nil, err
+	}
+
+	purpose := ""
+	switch {
+	case totp != nil && totp.Enabled:
+		purpose = models.ChallengeTwoFactorVerify
+	case role.RequireTwoFactor:
+		purpose = models.ChallengeTwoFactorEnroll
+	default:
+		return nil, nil
+	}
+
+	token, expiresAt, err := utils.GenerateTwoFactorChallenge(user.ID, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		SetupRequired:     purpose == models.ChallengeTwoFactorEnroll,
+		ChallengeToken:    token,
+		ExpiresAt:         expiresAt,
+	}, nil
+}
+
+// completeLogin langkah terakhir login (setelah password dan 2FA): reset hitungan gagal dan terbitkan token
+func (s *AuthService) completeLogin(c *fiber.Ctx, user *models.User, role *models.Role, identifier string) (*models.LoginResponse, error) {
+	if err := s.loginThrottle.RecordSuccess(identifier, user.ID, c.IP()); err != nil {
+		fmt.Printf("Warning: Failed to reset login attempts for user %s: %v\n", user.ID, err)
+	}
+
+	permissions, err := s.roleRepo.GetPermissionNamesByRoleID(user.RoleID)
+	if err != nil {
+		permissions = []string{}
+		fmt.Printf("Warning: Failed to get permissions for role %s: %v\n", role.Name, err)
+	}
+
+	// Login baru = sesi baru = refresh token family baru
+	session := newSession(c, user.ID)
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, fmt.Errorf("session: %w", err)
+	}
+
+	token, err := utils.GenerateToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := s.issueRefreshToken(c, user.ID, session.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("refresh token: %w", err)
 	}
 
-	response := models.LoginResponse{
+	return &models.LoginResponse{
 		Token:        token,
 		RefreshToken: refreshToken,
 		User:         *user,
 		Permissions:  permissions,
 		RoleName:     role.Name,
-	}
-
-	return c.JSON(response)
+	}, nil
 }
 
 
@@ -143,7 +299,7 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 // @Success 200 {object} object{token=string,refreshToken=string,user=object,permissions=[]string,roleName=string} "Successfully refreshed tokens"
 // @Failure 400 {object} object{error=string} "Invalid request body or missing refresh token"
 // @Failure 401 {object} object{error=string,details=string} "Invalid/expired refresh token or user not found"
-// @Failure 403 {object} object{error=string} "Account is inactive"
+// @Failure 403 {object} object{error=string} "Account is inactive or two-factor enrolment required"
 // @Failure 500 {object} object{error=string,details=string} "Server error"
 // @Router /auth/refresh [post]
 func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
@@ -179,6 +335,57 @@ func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid refresh token",
+		})
+	}
+
+	stored, err := s.refreshTokenRepo.GetByID(tokenID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Error checking refresh token",
+			"details": err.Error(),
+		})
+	}
+
+	if stored == nil || stored.UserID != userID {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid refresh token",
+		})
+	}
+
+	// Token yang sudah pernah dirotasi/di-revoke dipakai lagi: anggap bocor, matikan seluruh sesi
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		if err := s.sessionRepo.Revoke(stored.FamilyID); err != nil {
+			fmt.Printf("Warning: Failed to revoke session %s: %v\n", stored.FamilyID, err)
+		}
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Refresh token reuse detected, please login again",
+		})
+	}
+
+	if !stored.IsActive() {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Refresh token expired",
+		})
+	}
+
+	session, err := s.sessionRepo.GetByID(stored.FamilyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Error checking session",
+			"details": err.Error(),
+		})
+	}
+
+	if session == nil || !session.IsActive(time.Now()) {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Session has been revoked, please login again",
+		})
+	}
+
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -212,7 +419,23 @@ func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 		permissions = []string{}
 	}
 
-	newToken, err := utils.GenerateToken(user, permissions)
+	// Role yang baru diwajibkan 2FA: sesi lama harus login ulang dan enrol
+	if role.RequireTwoFactor {
+		totp, err := s.twoFactorRepo.GetByUserID(user.ID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error": "Error checking two-factor status",
+				"details": err.Error(),
+			})
+		}
+		if totp == nil || !totp.Enabled {
+			return c.Status(403).JSON(fiber.Map{
+				"error": models.ErrTwoFactorRequired.Error(),
+			})
+		}
+	}
+
+	newToken, err := utils.GenerateToken(user, session.ID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to generate new token",
@@ -220,14 +443,23 @@ func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	newRefreshToken, err := utils.GenerateRefreshToken(user.ID)
+	newRefreshToken, err := s.rotateRefreshToken(c, stored)
 	if err != nil {
+		if errors.Is(err, errRefreshTokenReused) {
+			return c.Status(401).JSON(fiber.Map{
+				"error": "Refresh token reuse detected, please login again",
+			})
+		}
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to generate new refresh token",
 			"details": err.Error(),
 		})
 	}
 
+	if err := s.sessionRepo.Extend(session.ID, truncate(c.Get("User-Agent"), 255), c.IP(), time.Now().Add(utils.RefreshTokenTTL)); err != nil {
+		fmt.Printf("Warning: Failed to extend session %s: %v\n", session.ID, err)
+	}
+
 	return c.JSON(fiber.Map{
 		"token":        newToken,
 		"refreshToken": newRefreshToken,
@@ -239,22 +471,187 @@ func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 
 // Logout godoc
 // @Summary Logout user
-// @Description Logout user (client-side token invalidation)
+// @Description Revoke refresh token yang dikirim (beserta rotasinya) sehingga tidak bisa dipakai lagi
 // @Tags Authentication
+// @Accept json
 // @Produce json
-// @Success 200 {object} object{message=string,note=string} "Logout berhasil"
-// @Security BearerAuth
+// @Param request body object{refreshToken=string} true "Refresh Token Request"
+// @Success 200 {object} object{message=string} "Logout berhasil"
+// @Failure 400 {object} object{error=string} "Invalid request body or missing refresh token"
+// @Failure 401 {object} object{error=string} "Invalid refresh token"
+// @Failure 500 {object} object{error=string,details=string} "Server error"
 // @Router /auth/logout [post]
 func (s *AuthService) Logout(c *fiber.Ctx) error {
+	var req struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.RefreshToken == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Refresh token is required",
+		})
+	}
+
+	claims, err := utils.ValidateRefreshToken(req.RefreshToken)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid or expired refresh token",
+		})
+	}
+
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid refresh token",
+		})
+	}
+
+	stored, err := s.refreshTokenRepo.GetByID(tokenID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Error checking refresh token",
+			"details": err.Error(),
+		})
+	}
+
+	if stored == nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid refresh token",
+		})
+	}
+
+	// Satu family = satu sesi login, jadi sesi dan seluruh rotasinya ikut di-revoke
+	if err := s.sessionRepo.Revoke(stored.FamilyID); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to revoke refresh token",
+			"details": err.Error(),
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "Logged out successfully",
-		"note":    "Token Deleted with Exp Date",
 	})
 }
 
+// LogoutAll godoc
+// @Summary Logout from all devices
+// @Description Revoke semua sesi (dan refresh token-nya) milik user yang sedang login, termasuk sesi saat ini
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} object{message=string,revoked=int} "Semua sesi di-revoke"
+// @Failure 401 {object} object{error=string} "User not authenticated"
+// @Failure 500 {object} object{error=string,details=string} "Server error"
+// @Security BearerAuth
+// @Router /auth/logout-all [post]
+func (s *AuthService) LogoutAll(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	revoked, err := s.sessionRepo.RevokeAllByUser(userID, nil)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to revoke sessions",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Logged out from all devices",
+		"revoked": revoked,
+	})
+}
+
+var errRefreshTokenReused = errors.New("refresh token already used")
+
+// issueRefreshToken membuat refresh token baru dan menyimpannya di refresh_tokens
+func (s *AuthService) issueRefreshToken(c *fiber.Ctx, userID, familyID uuid.UUID, parentID *uuid.UUID) (string, error) {
+	token := s.newRefreshTokenRecord(c, userID, familyID, parentID)
+
+	signed, err := utils.GenerateRefreshToken(userID, token.ID, token.ExpiresAt)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.refreshTokenRepo.Create(token); err != nil {
+		return "", err
+	}
+
+	return signed, nil
+}
+
+// rotateRefreshToken mengganti token lama dengan token baru di family yang sama
+func (s *AuthService) rotateRefreshToken(c *fiber.Ctx, old *models.RefreshToken) (string, error) {
+	next := s.newRefreshTokenRecord(c, old.UserID, old.FamilyID, &old.ID)
+
+	signed, err := utils.GenerateRefreshToken(old.UserID, next.ID, next.ExpiresAt)
+	if err != nil {
+		return "", err
+	}
+
+	rotated, err := s.refreshTokenRepo.Rotate(old.ID, next)
+	if err != nil {
+		return "", err
+	}
+
+	if !rotated {
+		// Kalah race dengan request lain yang memakai token yang sama
+		if err := s.sessionRepo.Revoke(old.FamilyID); err != nil {
+			fmt.Printf("Warning: Failed to revoke session %s: %v\n", old.FamilyID, err)
+		}
+		return "", errRefreshTokenReused
+	}
+
+	return signed, nil
+}
+
+func (s *AuthService) newRefreshTokenRecord(c *fiber.Ctx, userID, familyID uuid.UUID, parentID *uuid.UUID) *models.RefreshToken {
+	now := time.Now()
+	return &models.RefreshToken{
+		ID:         uuid.New(),
+		UserID:     userID,
+		FamilyID:   familyID,
+		ParentID:   parentID,
+		DeviceInfo: truncate(c.Get("User-Agent"), 255),
+		IPAddress:  c.IP(),
+		ExpiresAt:  now.Add(utils.RefreshTokenTTL),
+		CreatedAt:  now,
+	}
+}
+
+func newSession(c *fiber.Ctx, userID uuid.UUID) *models.Session {
+	now := time.Now()
+	return &models.Session{
+		ID:         uuid.New(),
+		UserID:     userID,
+		UserAgent:  truncate(c.Get("User-Agent"), 255),
+		IPAddress:  c.IP(),
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(utils.RefreshTokenTTL),
+	}
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
+
 // Profile godoc
 // @Summary Get user profile
-// @Description Mengambil data profil user beserta role, permissions, dan data tambahan (mahasiswa/dosen wali)
+// @Description Mengambil data profil user beserta role, permissions, dan data tambahan (mahasiswa/dosen wali).
+// @Description Selama impersonation respon berisi impersonation (admin asli dan waktu berakhirnya sesi).
 // @Tags Authentication
 // @Produce json
 // @Success 200 {object} object{data=object} "Profil user"
@@ -307,18 +704,27 @@ func (s *AuthService) Profile(c *fiber.Ctx) error {
 		"permissions": permissions,
 	}
 
-	if role.Name == "Mahasiswa" {
-		student, err := s.getStudentProfile(user.ID)
-		if err == nil && student != nil {
-			profileData["studentProfile"] = student
-		}
+	// Penanda jelas bahwa yang memakai sesi ini admin, bukan user itu sendiri
+	impersonation, err := impersonationInfo(c, s.sessionRepo)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Error checking session",
+			"details": err.Error(),
+		})
+	}
+	if impersonation != nil {
+		profileData["impersonation"] = impersonation
 	}
 
-	if role.Name == "Dosen Wali" {
-		lecturer, err := s.getLecturerProfile(user.ID)
-		if err == nil && lecturer != nil {
-			profileData["lecturerProfile"] = lecturer
-		}
+	// Profil dicari dari relasi user, bukan nama role, supaya role baru (mis. Kaprodi) ikut tampil
+	student, err := s.getStudentProfile(user.ID)
+	if err == nil && student != nil {
+		profileData["studentProfile"] = student
+	}
+
+	lecturer, err := s.getLecturerProfile(user.ID)
+	if err == nil && lecturer != nil {
+		profileData["lecturerProfile"] = lecturer
 	}
 
 	return c.JSON(fiber.Map{
@@ -347,6 +753,20 @@ func (s *AuthService) getLecturerProfile(userID uuid.UUID) (*models.Lecturer, er
 	return lecturer, nil
 }
 
+// ChangePassword godoc
+// @Summary Change password
+// @Description Mengganti password user yang sedang login (butuh password lama)
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.ChangePasswordRequest true "Change password request"
+// @Success 200 {object} object{message=string}
+// @Failure 400 {object} object{error=string,violations=[]models.PasswordViolation} "Validation error or password policy violation"
+// @Failure 401 {object} object{error=string}
+// @Failure 404 {object} object{error=string}
+// @Failure 500 {object} object{error=string,details=string}
+// @Security BearerAuth
+// @Router /auth/change-password [post]
 func (s *AuthService) ChangePassword(c *fiber.Ctx) error {
 	userID, ok := c.Locals("user_id").(uuid.UUID)
 	if !ok {
@@ -355,11 +775,7 @@ func (s *AuthService) ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
-	var req struct {
-		CurrentPassword string `json:"currentPassword"`
-		NewPassword     string `json:"newPassword"`
-		ConfirmPassword string `json:"confirmPassword"`
-	}
+	var req models.ChangePasswordRequest
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{
@@ -374,12 +790,6 @@ func (s *AuthService) ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
-	if len(req.NewPassword) < 6 {
-		return c.Status(400).JSON(fiber.Map{
-			"error": "New password must be at least 6 characters",
-		})
-	}
-
 	// Get user
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil || user == nil {
@@ -395,6 +805,10 @@ func (s *AuthService) ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := s.passwordPolicy.Validate(req.NewPassword, user); err != nil {
+		return passwordPolicyErrorResponse(c, err)
+	}
+
 	// Hash new password
 	hashedPassword, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
@@ -412,6 +826,10 @@ func (s *AuthService) ChangePassword(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := s.passwordPolicy.Remember(user.ID, hashedPassword); err != nil {
+		fmt.Printf("Warning: Failed to record password history for user %s: %v\n", user.ID, err)
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "Password updated successfully",
 	})
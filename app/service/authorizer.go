@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var errNoActor = errors.New("no authenticated user in request")
+
+// Actor user yang sedang login beserta permission dan profil mahasiswa/dosennya
+type Actor struct {
+	User        *models.User
+	Permissions []string
+	Student     *models.Student
+	Lecturer    *models.Lecturer
+}
+
+func (a *Actor) HasPermission(permission string) bool {
+	for _, p := range a.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorizer menjawab pertanyaan "boleh actor X melakukan Y pada data mahasiswa Z"
+// berdasarkan relasi (pemilik / dosen wali) dan permission, bukan nama role.
+type Authorizer struct {
+	studentRepo  repository.StudentRepository
+	lecturerRepo repository.LecturerRepository
+}
+
+func NewAuthorizer(
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+) *Authorizer {
+	return &Authorizer{
+		studentRepo:  studentRepo,
+		lecturerRepo: lecturerRepo,
+	}
+}
+
+// Actor membangun Actor dari locals yang di-set RequireAuth
+func (z *Authorizer) Actor(c *fiber.Ctx) (*Actor, error) {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok || user == nil {
+		return nil, errNoActor
+	}
+	permissions, _ := c.Locals("permissions").([]string)
+
+	student, err := z.studentRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	lecturer, err := z.lecturerRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Actor{
+		User:        user,
+		Permissions: permissions,
+		Student:     student,
+		Lecturer:    lecturer,
+	}, nil
+}
+
+// RelationsTo relasi actor terhadap mahasiswa. globalPermission menentukan
+// permission yang memberi akses ke semua mahasiswa untuk resource terkait.
+func (z *Authorizer) RelationsTo(actor *Actor, student *models.Student, globalPermission string) []models.Relation {
+	var relations []models.Relation
+	if student == nil {
+		return relations
+	}
+	if student.UserID == actor.User.ID {
+		relations = append(relations, models.RelationOwner)
+	}
+	if actor.Lecturer != nil && student.AdvisorID != nil && *student.AdvisorID == actor.Lecturer.ID {
+		relations = append(relations, models.RelationAdvisor)
+	}
+	if actor.HasPermission(globalPermission) {
+		relations = append(relations, models.RelationGlobal)
+	}
+	return relations
+}
+
+// AchievementRelations relasi actor terhadap pemilik prestasi (studentID)
+func (z *Authorizer) AchievementRelations(actor *Actor, studentID uuid.UUID) ([]models.Relation, error) {
+	// Pemilik dan akses global tidak butuh data mahasiswa, hanya dosen wali yang perlu advisor_id
+	if actor.Lecturer == nil {
+		student := &models.Student{ID: studentID}
+		if actor.Student != nil && actor.Student.ID == studentID {
+			student = actor.Student
+		}
+		return z.RelationsTo(actor, student, models.PermissionAchievementManage), nil
+	}
+
+	student, err := z.studentRepo.GetByID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	return z.RelationsTo(actor, student, models.PermissionAchievementManage), nil
+}
+
+// CanAccessAchievement apakah actor boleh menjalankan action non-transisi (create/read) pada prestasi mahasiswa
+func (z *Authorizer) CanAccessAchievement(actor *Actor, studentID uuid.UUID, action models.AchievementAction) (bool, error) {
+	relations, err := z.AchievementRelations(actor, studentID)
+	if err != nil {
+		return false, err
+	}
+	return models.HasAnyRelation(models.AchievementActionRelations(action), relations), nil
+}
+
+// AuthorizeTransition mengecek relasi actor dan status prestasi terhadap tabel transisi
+func (z *Authorizer) AuthorizeTransition(actor *Actor, ref *models.AchievementReference, action models.AchievementAction) (models.AchievementTransition, error) {
+	relations, err := z.AchievementRelations(actor, ref.StudentID)
+	if err != nil {
+		return models.AchievementTransition{}, err
+	}
+	return models.GuardAchievementTransition(relations, ref.Status, action)
+}
+
+// AchievementScope cakupan daftar prestasi/statistik yang boleh dilihat actor
+func (z *Authorizer) AchievementScope(actor *Actor) (models.AchievementScope, error) {
+	switch {
+	case actor.HasPermission(models.PermissionAchievementManage):
+		return models.AchievementScope{Relation: models.RelationGlobal}, nil
+	case actor.Lecturer != nil:
+		return models.AchievementScope{Relation: models.RelationAdvisor, ID: actor.Lecturer.ID}, nil
+	case actor.Student != nil:
+		return models.AchievementScope{Relation: models.RelationOwner, ID: actor.Student.ID}, nil
+	}
+	return models.AchievementScope{}, models.ErrNoAchievementScope
+}
+
+// CanViewStudent profil mahasiswa: dirinya sendiri, dosen walinya, atau pengelola user
+func (z *Authorizer) CanViewStudent(actor *Actor, student *models.Student) bool {
+	return len(z.RelationsTo(actor, student, models.PermissionUserManage)) > 0
+}
+
+// CanViewAdvisees daftar bimbingan dosen: dosen itu sendiri atau pengelola user
+func (z *Authorizer) CanViewAdvisees(actor *Actor, lecturer *models.Lecturer) bool {
+	return lecturer.UserID == actor.User.ID || actor.HasPermission(models.PermissionUserManage)
+}
+
+// authorizationErrorResponse respon standar jika Actor gagal dibangun
+func authorizationErrorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errNoActor) {
+		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	return c.Status(500).JSON(fiber.Map{
+		"error":   "Failed to resolve access",
+		"details": err.Error(),
+	})
+}
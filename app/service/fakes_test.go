@@ -0,0 +1,226 @@
+package service
+
+import (
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+
+	"github.com/google/uuid"
+)
+
+// Fake repository in-memory untuk pengujian service tanpa Postgres. Interface repository
+// di-embed sehingga method yang tidak dipakai test panic jika terpanggil.
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func newFakeUserRepo(users ...*models.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: map[uuid.UUID]*models.User{}}
+	for _, user := range users {
+		r.users[user.ID] = user
+	}
+	return r
+}
+
+func (r *fakeUserRepo) GetByID(id uuid.UUID) (*models.User, error) {
+	return r.users[id], nil
+}
+
+func (r *fakeUserRepo) GetByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) GetByUsername(username string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) Create(user *models.User) (uuid.UUID, error) {
+	r.users[user.ID] = user
+	return user.ID, nil
+}
+
+func (r *fakeUserRepo) Update(id uuid.UUID, req *models.UpdateUserRequest) error {
+	user := r.users[id]
+	if req.FullName != nil {
+		user.FullName = *req.FullName
+	}
+	if req.Email != nil {
+		user.Email = *req.Email
+	}
+	return nil
+}
+
+func (r *fakeUserRepo) HardDelete(id uuid.UUID) error {
+	delete(r.users, id)
+	return nil
+}
+
+type fakeRoleRepo struct {
+	repository.RoleRepository
+	roles       []*models.Role
+	permissions []models.Permission
+	// assigned permission per role; audits entri yang diteruskan ke mutasi
+	assigned map[uuid.UUID][]uuid.UUID
+	audits   []*models.AuditLog
+}
+
+func newFakeRoleRepo(names ...string) *fakeRoleRepo {
+	r := &fakeRoleRepo{assigned: map[uuid.UUID][]uuid.UUID{}}
+	for _, name := range names {
+		r.roles = append(r.roles, &models.Role{ID: uuid.New(), Name: name})
+	}
+	return r
+}
+
+func (r *fakeRoleRepo) GetByName(name string) (*models.Role, error) {
+	for _, role := range r.roles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRoleRepo) GetByID(id uuid.UUID) (*models.Role, error) {
+	for _, role := range r.roles {
+		if role.ID == id {
+			return role, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRoleRepo) GetPermissionByID(id uuid.UUID) (*models.Permission, error) {
+	for i := range r.permissions {
+		if r.permissions[i].ID == id {
+			return &r.permissions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRoleRepo) GetPermissionsByRoleID(roleID uuid.UUID) ([]models.Permission, error) {
+	var permissions []models.Permission
+	for _, id := range r.assigned[roleID] {
+		p, _ := r.GetPermissionByID(id)
+		permissions = append(permissions, *p)
+	}
+	return permissions, nil
+}
+
+func (r *fakeRoleRepo) AssignPermission(roleID, permissionID uuid.UUID, audit *models.AuditLog) error {
+	r.assigned[roleID] = append(r.assigned[roleID], permissionID)
+	r.audits = append(r.audits, audit)
+	return nil
+}
+
+func (r *fakeRoleRepo) SetTwoFactorRequired(id uuid.UUID, required bool, audit *models.AuditLog) error {
+	role, _ := r.GetByID(id)
+	role.RequireTwoFactor = required
+	r.audits = append(r.audits, audit)
+	return nil
+}
+
+type fakeStudentRepo struct {
+	repository.StudentRepository
+	students []models.Student
+}
+
+func (r *fakeStudentRepo) GetByStudentID(studentID string) (*models.Student, error) {
+	for i := range r.students {
+		if r.students[i].StudentID == studentID {
+			return &r.students[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeStudentRepo) Create(student models.Student) (uuid.UUID, error) {
+	r.students = append(r.students, student)
+	return student.ID, nil
+}
+
+type fakeLecturerRepo struct {
+	repository.LecturerRepository
+	lecturers []models.Lecturer
+}
+
+func (r *fakeLecturerRepo) GetByLecturerID(lecturerID string) (*models.Lecturer, error) {
+	for i := range r.lecturers {
+		if r.lecturers[i].LecturerID == lecturerID {
+			return &r.lecturers[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeLecturerRepo) Create(lecturer models.Lecturer) (uuid.UUID, error) {
+	r.lecturers = append(r.lecturers, lecturer)
+	return lecturer.ID, nil
+}
+
+type fakeOIDCRepo struct {
+	states     map[string]*models.OIDCLoginState
+	identities []*models.UserIdentity
+	touched    []uuid.UUID
+}
+
+func newFakeOIDCRepo(identities ...*models.UserIdentity) *fakeOIDCRepo {
+	return &fakeOIDCRepo{states: map[string]*models.OIDCLoginState{}, identities: identities}
+}
+
+func (r *fakeOIDCRepo) CreateState(state *models.OIDCLoginState) error {
+	r.states[state.StateHash] = state
+	return nil
+}
+
+func (r *fakeOIDCRepo) ConsumeState(stateHash string) (*models.OIDCLoginState, error) {
+	state := r.states[stateHash]
+	delete(r.states, stateHash)
+	if state == nil || !time.Now().Before(state.ExpiresAt) {
+		return nil, nil
+	}
+	return state, nil
+}
+
+func (r *fakeOIDCRepo) GetIdentity(issuer, subject string) (*models.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeOIDCRepo) CreateIdentity(identity *models.UserIdentity) error {
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *fakeOIDCRepo) TouchIdentity(id uuid.UUID, email string, at time.Time) error {
+	r.touched = append(r.touched, id)
+	return nil
+}
+
+func newTestUser(username, email string) *models.User {
+	return &models.User{
+		ID:       uuid.New(),
+		Username: username,
+		Email:    email,
+		FullName: username,
+		IsActive: true,
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"strings"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/config"
+	"achievement-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ImpersonationService admin login sebagai user lain untuk troubleshooting.
+// Token-nya pendek, tanpa refresh token, membawa klaim "act" berisi admin asli,
+// dan setiap request selama impersonation dicatat RequireAuth ke audit_logs.
+type ImpersonationService struct {
+	userRepo    repository.UserRepository
+	roleRepo    repository.RoleRepository
+	sessionRepo repository.SessionRepository
+	auditLogger *AuditLogger
+	config      config.ImpersonationConfig
+}
+
+func NewImpersonationService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	sessionRepo repository.SessionRepository,
+	auditLogger *AuditLogger,
+	cfg config.ImpersonationConfig,
+) *ImpersonationService {
+	return &ImpersonationService{
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		sessionRepo: sessionRepo,
+		auditLogger: auditLogger,
+		config:      cfg,
+	}
+}
+
+// Start godoc
+// @Summary Impersonate a user
+// @Description Admin mendapat access token berumur pendek (IMPERSONATION_TTL) yang bertindak sebagai user target.
+// @Description Token membawa klaim "act" berisi admin asli, tidak bisa di-refresh, dan semua request-nya masuk audit log.
+// @Description User yang bisa mengelola user (admin lain) tidak bisa di-impersonate.
+// @Tags User
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User UUID"
+// @Param request body models.ImpersonateRequest true "Alasan impersonation"
+// @Success 200 {object} models.ImpersonateResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/impersonate [post]
+func (s *ImpersonationService) Start(c *fiber.Ctx) error {
+	admin, ok := c.Locals("user").(*models.User)
+	if !ok || admin == nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req models.ImpersonateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Reason is required",
+		})
+	}
+
+	if id == admin.ID {
+		return c.Status(400).JSON(fiber.Map{
+			"error": models.ErrImpersonateSelf.Error(),
+		})
+	}
+
+	target, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check user",
+			"details": err.Error(),
+		})
+	}
+	if target == nil || !target.IsActive {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "User not found or inactive",
+		})
+	}
+
+	// Impersonate admin lain sama dengan menaikkan hak akses tanpa jejak yang jelas
+	permissions, err := s.roleRepo.GetPermissionNamesByRoleID(target.RoleID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to resolve permissions",
+			"details": err.Error(),
+		})
+	}
+	for _, p := range permissions {
+		if p == models.PermissionUserManage {
+			return c.Status(403).JSON(fiber.Map{
+				"error": models.ErrImpersonateAdmin.Error(),
+			})
+		}
+	}
+
+	session := newSession(c, target.ID)
+	session.ImpersonatorID = &admin.ID
+	session.ExpiresAt = session.CreatedAt.Add(s.config.TTL)
+	if err := s.sessionRepo.Create(session); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create session",
+			"details": err.Error(),
+		})
+	}
+
+	token, err := utils.GenerateImpersonationToken(target, admin, session.ID, session.ExpiresAt)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate token",
+			"details": err.Error(),
+		})
+	}
+
+	// Sesi baru dicatat di snapshot; session_id entri ini adalah sesi admin yang memulai
+	s.auditLogger.Record(c, AuditEvent{
+		Action:     models.AuditActionImpersonationStart,
+		TargetType: models.AuditTargetUser,
+		TargetID:   target.ID.String(),
+		Reason:     req.Reason,
+		After:      session,
+	})
+
+	return c.JSON(models.ImpersonateResponse{
+		Token:        token,
+		ExpiresAt:    session.ExpiresAt,
+		SessionID:    session.ID,
+		User:         *target,
+		Impersonator: *admin,
+	})
+}
+
+// Stop godoc
+// @Summary Stop impersonating
+// @Description Mengakhiri sesi impersonation yang dipakai request ini; token-nya langsung ditolak
+// @Tags Authentication
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/impersonation/stop [post]
+func (s *ImpersonationService) Stop(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok || user == nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	impersonator, _ := c.Locals("impersonator").(*models.User)
+	sessionID, ok := c.Locals("session_id").(uuid.UUID)
+	if impersonator == nil || !ok {
+		return c.Status(400).JSON(fiber.Map{
+			"error": models.ErrNotImpersonating.Error(),
+		})
+	}
+
+	if err := s.sessionRepo.Revoke(sessionID); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to revoke session",
+			"details": err.Error(),
+		})
+	}
+
+	s.auditLogger.Record(c, AuditEvent{
+		Action:     models.AuditActionImpersonationStop,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID.String(),
+	})
+
+	return c.JSON(fiber.Map{
+		"message": "Impersonation stopped",
+	})
+}
+
+// impersonationInfo penanda sesi impersonation untuk /auth/profile, nil untuk sesi biasa
+func impersonationInfo(c *fiber.Ctx, sessionRepo repository.SessionRepository) (*models.ImpersonationInfo, error) {
+	impersonator, _ := c.Locals("impersonator").(*models.User)
+	sessionID, ok := c.Locals("session_id").(uuid.UUID)
+	if impersonator == nil || !ok {
+		return nil, nil
+	}
+
+	session, err := sessionRepo.GetByID(sessionID)
+	if err != nil || session == nil {
+		return nil, err
+	}
+
+	return &models.ImpersonationInfo{
+		Active:       true,
+		Impersonator: *impersonator,
+		ExpiresAt:    session.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"achievement-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type KeyService struct{}
+
+func NewKeyService() *KeyService {
+	return &KeyService{}
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public key untuk memverifikasi access token, dipakai layanan kampus lain tanpa berbagi secret.
+// @Description Refresh token dan challenge 2FA ditandatangani kunci yang sama, jadi verifier wajib mengecek
+// @Description klaim iss == "achievement-system" dan token_type == "access" selain tanda tangan dan exp.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} object{keys=[]utils.JWK} "JWKS"
+// @Router /.well-known/jwks.json [get]
+func (s *KeyService) JWKS(c *fiber.Ctx) error {
+	// kunci bisa dirotasi, jangan di-cache terlalu lama
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(fiber.Map{
+		"keys": utils.PublicJWKs(),
+	})
+}
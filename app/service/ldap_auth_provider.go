@@ -0,0 +1,241 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/utils"
+
+	"github.com/google/uuid"
+)
+
+// ldapAuthProvider bind ke LDAP sebagai user. Hanya user lokal yang terhubung ke entry LDAP
+// lewat user_identities (issuer ldap, subject DN) yang bisa login; nama dan email lokal
+// disinkronkan dari directory setiap login berhasil, entry yang belum terhubung ke akun
+// mana pun bisa dibuatkan akun Dosen Wali baru
+type ldapAuthProvider struct {
+	ldap          *utils.LDAPAuthenticator
+	userRepo      repository.UserRepository
+	roleRepo      repository.RoleRepository
+	lecturerRepo  repository.LecturerRepository
+	identityRepo  repository.OIDCRepository
+	autoProvision bool
+}
+
+func NewLDAPAuthProvider(
+	ldap *utils.LDAPAuthenticator,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	lecturerRepo repository.LecturerRepository,
+	identityRepo repository.OIDCRepository,
+	autoProvision bool,
+) AuthProvider {
+	return &ldapAuthProvider{
+		ldap:          ldap,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		lecturerRepo:  lecturerRepo,
+		identityRepo:  identityRepo,
+		autoProvision: autoProvision,
+	}
+}
+
+func (p *ldapAuthProvider) Name() string {
+	return AuthProviderLDAP
+}
+
+func (p *ldapAuthProvider) Authenticate(creds LoginCredentials) (*models.User, error) {
+	// Login pakai email: bind memakai username user lokal. Entry hasil bind tetap harus
+	// terhubung ke user itu lewat user_identities.
+	username := creds.Username
+	if creds.User != nil {
+		username = creds.User.Username
+	}
+	if username == "" {
+		return nil, errInvalidCredentials
+	}
+
+	entry, err := p.ldap.Authenticate(username, creds.Password)
+	if err != nil {
+		if errors.Is(err, utils.ErrLDAPInvalidCredentials) {
+			return nil, errInvalidCredentials
+		}
+		return nil, err
+	}
+
+	// Subject identity = DN yang benar-benar berhasil bind (entry hasil search sudah dicocokkan)
+	identity, err := p.identityRepo.GetIdentity(models.IdentityIssuerLDAP, strings.ToLower(entry.DN))
+	if err != nil {
+		return nil, err
+	}
+
+	if identity == nil {
+		// Akun lokal yang tidak terhubung ke LDAP tidak boleh diambil alih lewat username / email yang sama
+		if creds.User != nil {
+			return nil, errInvalidCredentials
+		}
+		if !p.autoProvision {
+			return nil, errNoLocalAccount
+		}
+		return p.provisionLecturer(entry)
+	}
+
+	user := creds.User
+	if user != nil && user.ID != identity.UserID {
+		return nil, errInvalidCredentials
+	}
+	if user == nil {
+		user, err = p.userRepo.GetByID(identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, errNoLocalAccount
+		}
+	}
+
+	p.syncAttributes(user, entry)
+	if err := p.identityRepo.TouchIdentity(identity.ID, user.Email, time.Now()); err != nil {
+		fmt.Printf("Warning: Failed to update LDAP identity for user %s: %v\n", user.ID, err)
+	}
+	return user, nil
+}
+
+func (p *ldapAuthProvider) syncAttributes(user *models.User, entry *utils.LDAPEntry) {
+	var req models.UpdateUserRequest
+	if entry.FullName != "" && entry.FullName != user.FullName {
+		fullName := truncate(entry.FullName, 100)
+		req.FullName = &fullName
+	}
+	if entry.Email != "" && entry.Email != user.Email {
+		// Email yang sudah dipakai akun lain tidak ditimpa, nama tetap disinkronkan
+		taken, err := p.userRepo.GetByEmail(entry.Email)
+		switch {
+		case err != nil:
+			fmt.Printf("Warning: Failed to check LDAP email %s for user %s: %v\n", entry.Email, user.ID, err)
+		case taken != nil && taken.ID != user.ID:
+			fmt.Printf("Warning: LDAP email %s for user %s is already used by user %s, not synced\n", entry.Email, user.ID, taken.ID)
+		default:
+			req.Email = &entry.Email
+		}
+	}
+	if req.FullName == nil && req.Email == nil {
+		return
+	}
+
+	if err := p.userRepo.Update(user.ID, &req); err != nil {
+		fmt.Printf("Warning: Failed to sync LDAP attributes for user %s: %v\n", user.ID, err)
+		return
+	}
+	if req.FullName != nil {
+		user.FullName = *req.FullName
+	}
+	if req.Email != nil {
+		user.Email = *req.Email
+	}
+}
+
+// provisionLecturer membuat user Dosen Wali beserta profil dosen dari atribut LDAP.
+// Password lokal diisi acak karena password sebenarnya tetap di LDAP.
+func (p *ldapAuthProvider) provisionLecturer(entry *utils.LDAPEntry) (*models.User, error) {
+	if entry.Email == "" {
+		return nil, fmt.Errorf("ldap entry %s has no email attribute", entry.DN)
+	}
+
+	role, err := p.roleRepo.GetByName("Dosen Wali")
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, errors.New("role Dosen Wali not found")
+	}
+
+	lecturerID := entry.LecturerID
+	if lecturerID == "" {
+		lecturerID = entry.Username
+	}
+	if len(lecturerID) > 20 {
+		return nil, fmt.Errorf("lecturer ID %q from ldap is too long", lecturerID)
+	}
+	existing, err := p.lecturerRepo.GetByLecturerID(lecturerID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("lecturer ID %s is already registered to another account", lecturerID)
+	}
+	// Email yang sudah dipakai akun lokal tidak otomatis dihubungkan; admin yang menautkan
+	taken, err := p.userRepo.GetByEmail(entry.Email)
+	if err != nil {
+		return nil, err
+	}
+	if taken != nil {
+		return nil, fmt.Errorf("email %s from ldap is already registered to another account", entry.Email)
+	}
+
+	randomPassword, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := entry.FullName
+	if fullName == "" {
+		fullName = entry.Username
+	}
+
+	now := time.Now()
+	user := &models.User{
+		ID:           uuid.New(),
+		Username:     entry.Username,
+		Email:        entry.Email,
+		PasswordHash: hashedPassword,
+		FullName:     truncate(fullName, 100),
+		RoleID:       role.ID,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if _, err := p.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	// Identity dibuat sebelum profil dosen supaya rollback cukup menghapus user (cascade)
+	if err := p.identityRepo.CreateIdentity(&models.UserIdentity{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Issuer:      models.IdentityIssuerLDAP,
+		Subject:     strings.ToLower(entry.DN),
+		Email:       entry.Email,
+		CreatedAt:   now,
+		LastLoginAt: &now,
+	}); err != nil {
+		if deleteErr := p.userRepo.HardDelete(user.ID); deleteErr != nil {
+			fmt.Printf("CRITICAL: Failed to rollback provisioned user: %v\n", deleteErr)
+		}
+		return nil, err
+	}
+
+	lecturer := models.Lecturer{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		LecturerID: lecturerID,
+		Department: truncate(entry.Department, 100),
+		CreatedAt:  now,
+	}
+	if _, err := p.lecturerRepo.Create(lecturer); err != nil {
+		if deleteErr := p.userRepo.HardDelete(user.ID); deleteErr != nil {
+			fmt.Printf("CRITICAL: Failed to rollback provisioned user: %v\n", deleteErr)
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
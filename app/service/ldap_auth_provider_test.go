@@ -0,0 +1,224 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/config"
+	"achievement-backend/utils"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+)
+
+const testLecturerDN = "uid=sari,ou=dosen,dc=kampus,dc=ac,dc=id"
+
+// fakeLDAPDirectory satu entry dosen dengan password "rahasia"
+type fakeLDAPDirectory struct {
+	email string
+}
+
+func (d *fakeLDAPDirectory) dial(config.LDAPConfig) (utils.LDAPConn, error) {
+	return d, nil
+}
+
+func (d *fakeLDAPDirectory) Bind(username, password string) error {
+	if username != testLecturerDN {
+		return ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))
+	}
+	if password != "rahasia" {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	return nil
+}
+
+func (d *fakeLDAPDirectory) Search(*ldap.SearchRequest) (*ldap.SearchResult, error) {
+	return &ldap.SearchResult{Entries: []*ldap.Entry{ldap.NewEntry(testLecturerDN, map[string][]string{
+		"cn":             {"Dr. Sari"},
+		"mail":           {d.email},
+		"employeeNumber": {"198001"},
+		"ou":             {"Informatika"},
+	})}}, nil
+}
+
+func (d *fakeLDAPDirectory) Close() error {
+	return nil
+}
+
+type ldapProviderFixture struct {
+	provider     AuthProvider
+	userRepo     *fakeUserRepo
+	lecturerRepo *fakeLecturerRepo
+	identityRepo *fakeOIDCRepo
+}
+
+func newLDAPProviderFixture(autoProvision bool, users []*models.User, identities ...*models.UserIdentity) *ldapProviderFixture {
+	cfg := config.LDAPConfig{
+		UserDNTemplates: []string{"uid=%s,ou=dosen,dc=kampus,dc=ac,dc=id"},
+		AttrFullName:    "cn",
+		AttrEmail:       "mail",
+		AttrLecturerID:  "employeeNumber",
+		AttrDepartment:  "ou",
+		Timeout:         time.Second,
+	}
+	directory := &fakeLDAPDirectory{email: "sari@kampus.ac.id"}
+
+	f := &ldapProviderFixture{
+		userRepo:     newFakeUserRepo(users...),
+		lecturerRepo: &fakeLecturerRepo{},
+		identityRepo: newFakeOIDCRepo(identities...),
+	}
+	f.provider = NewLDAPAuthProvider(
+		utils.NewLDAPAuthenticator(cfg, directory.dial),
+		f.userRepo,
+		newFakeRoleRepo("Dosen Wali"),
+		f.lecturerRepo,
+		f.identityRepo,
+		autoProvision,
+	)
+	return f
+}
+
+func ldapIdentity(userID uuid.UUID) *models.UserIdentity {
+	return &models.UserIdentity{
+		ID:      uuid.New(),
+		UserID:  userID,
+		Issuer:  models.IdentityIssuerLDAP,
+		Subject: strings.ToLower(testLecturerDN),
+	}
+}
+
+func TestLDAPProviderRefusesUnlinkedLocalAccount(t *testing.T) {
+	// Akun lokal dengan username sama tapi belum pernah dihubungkan ke entry LDAP
+	local := newTestUser("sari", "sari.lokal@kampus.ac.id")
+	f := newLDAPProviderFixture(true, []*models.User{local})
+
+	_, err := f.provider.Authenticate(LoginCredentials{Username: "sari", Password: "rahasia", User: local})
+	if !errors.Is(err, errInvalidCredentials) {
+		t.Fatalf("err = %v, want errInvalidCredentials", err)
+	}
+	if len(f.identityRepo.identities) != 0 || len(f.userRepo.users) != 1 {
+		t.Error("unlinked account must not be linked or provisioned")
+	}
+	if local.Email != "sari.lokal@kampus.ac.id" {
+		t.Errorf("local email overwritten with %q", local.Email)
+	}
+}
+
+func TestLDAPProviderLinkedAccount(t *testing.T) {
+	user := newTestUser("sari", "sari.lama@kampus.ac.id")
+	identity := ldapIdentity(user.ID)
+	f := newLDAPProviderFixture(false, []*models.User{user}, identity)
+
+	got, err := f.provider.Authenticate(LoginCredentials{Username: "sari", Password: "rahasia", User: user})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != user.ID {
+		t.Fatalf("user = %s, want %s", got.ID, user.ID)
+	}
+	if got.FullName != "Dr. Sari" || got.Email != "sari@kampus.ac.id" {
+		t.Errorf("attributes not synced: %+v", got)
+	}
+	if len(f.identityRepo.touched) != 1 || f.identityRepo.touched[0] != identity.ID {
+		t.Errorf("touched = %v, want %s", f.identityRepo.touched, identity.ID)
+	}
+}
+
+func TestLDAPProviderRejectsIdentityOfAnotherUser(t *testing.T) {
+	owner := newTestUser("sari.dosen", "sari@kampus.ac.id")
+	other := newTestUser("sari", "sari.lain@kampus.ac.id")
+	f := newLDAPProviderFixture(false, []*models.User{owner, other}, ldapIdentity(owner.ID))
+
+	// Username cocok dengan akun lain, tapi DN itu terhubung ke owner
+	_, err := f.provider.Authenticate(LoginCredentials{Username: "sari", Password: "rahasia", User: other})
+	if !errors.Is(err, errInvalidCredentials) {
+		t.Fatalf("err = %v, want errInvalidCredentials", err)
+	}
+}
+
+func TestLDAPProviderRejectsEmptyPassword(t *testing.T) {
+	user := newTestUser("sari", "sari@kampus.ac.id")
+	f := newLDAPProviderFixture(true, []*models.User{user}, ldapIdentity(user.ID))
+
+	_, err := f.provider.Authenticate(LoginCredentials{Username: "sari", Password: "", User: user})
+	if !errors.Is(err, errInvalidCredentials) {
+		t.Fatalf("err = %v, want errInvalidCredentials", err)
+	}
+}
+
+func TestLDAPProviderSkipsEmailSyncOnClash(t *testing.T) {
+	user := newTestUser("sari", "sari.lama@kampus.ac.id")
+	clash := newTestUser("admin", "sari@kampus.ac.id")
+	f := newLDAPProviderFixture(false, []*models.User{user, clash}, ldapIdentity(user.ID))
+
+	got, err := f.provider.Authenticate(LoginCredentials{Username: "sari", Password: "rahasia", User: user})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Email != "sari.lama@kampus.ac.id" {
+		t.Errorf("email = %q, must not take over the address of another account", got.Email)
+	}
+	if got.FullName != "Dr. Sari" {
+		t.Errorf("full name = %q, want it synced even when email is skipped", got.FullName)
+	}
+}
+
+func TestLDAPProviderAutoProvision(t *testing.T) {
+	f := newLDAPProviderFixture(true, nil)
+
+	user, err := f.provider.Authenticate(LoginCredentials{Username: "sari", Password: "rahasia"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "sari" || user.Email != "sari@kampus.ac.id" || user.FullName != "Dr. Sari" {
+		t.Errorf("user = %+v", user)
+	}
+	if len(f.identityRepo.identities) != 1 {
+		t.Fatalf("identities = %d, want 1", len(f.identityRepo.identities))
+	}
+	identity := f.identityRepo.identities[0]
+	if identity.UserID != user.ID || identity.Issuer != models.IdentityIssuerLDAP || identity.Subject != strings.ToLower(testLecturerDN) {
+		t.Errorf("identity = %+v", identity)
+	}
+	if len(f.lecturerRepo.lecturers) != 1 || f.lecturerRepo.lecturers[0].LecturerID != "198001" || f.lecturerRepo.lecturers[0].UserID != user.ID {
+		t.Errorf("lecturers = %+v", f.lecturerRepo.lecturers)
+	}
+
+	// Login berikutnya memakai identitas yang baru dibuat, bukan provisioning ulang
+	again, err := f.provider.Authenticate(LoginCredentials{Username: "sari", Password: "rahasia", User: user})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != user.ID || len(f.userRepo.users) != 1 {
+		t.Errorf("second login provisioned another account")
+	}
+}
+
+func TestLDAPProviderWithoutAutoProvision(t *testing.T) {
+	f := newLDAPProviderFixture(false, nil)
+
+	_, err := f.provider.Authenticate(LoginCredentials{Username: "sari", Password: "rahasia"})
+	if !errors.Is(err, errNoLocalAccount) {
+		t.Fatalf("err = %v, want errNoLocalAccount", err)
+	}
+	if len(f.userRepo.users) != 0 {
+		t.Error("no account may be created when auto-provisioning is off")
+	}
+}
+
+func TestLDAPProviderAutoProvisionRefusesTakenEmail(t *testing.T) {
+	// Login lewat username lain (mis. NIP) tapi email LDAP sudah dipakai akun lokal
+	local := newTestUser("sari.lokal", "sari@kampus.ac.id")
+	f := newLDAPProviderFixture(true, []*models.User{local})
+
+	if _, err := f.provider.Authenticate(LoginCredentials{Username: "sari", Password: "rahasia"}); err == nil {
+		t.Fatal("provisioning must not reuse an email that belongs to a local account")
+	}
+	if len(f.userRepo.users) != 1 || len(f.identityRepo.identities) != 0 {
+		t.Error("no account or identity may be created")
+	}
+}
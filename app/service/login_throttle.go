@@ -0,0 +1,177 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// loginBlock alasan login ditolak sebelum password dicek
+type loginBlock struct {
+	Until  time.Time
+	Locked bool
+}
+
+func (b *loginBlock) Error() string {
+	return fmt.Sprintf("login blocked until %s", b.Until.Format(time.RFC3339))
+}
+
+// LoginThrottle membatasi percobaan login per akun dan per IP:
+// jeda progresif setelah beberapa kali gagal, lalu kunci sementara.
+// Semua pengecekan dilakukan sebelum bcrypt supaya brute force tidak membebani server.
+type LoginThrottle struct {
+	repo repository.LoginAttemptRepository
+	cfg  config.LoginThrottleConfig
+}
+
+func NewLoginThrottle(repo repository.LoginAttemptRepository, cfg config.LoginThrottleConfig) *LoginThrottle {
+	return &LoginThrottle{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+// CheckIP menolak IP yang sudah terlalu banyak gagal dalam jendela waktu
+func (t *LoginThrottle) CheckIP(ip string) (*loginBlock, error) {
+	now := time.Now()
+	count, last, err := t.repo.CountRecentFailuresByIP(ip, now.Add(-t.cfg.FailureWindow))
+	if err != nil {
+		return nil, err
+	}
+	if count < t.cfg.IPMaxFailures || last == nil {
+		return nil, nil
+	}
+
+	until := last.Add(t.cfg.FailureWindow)
+	if !now.Before(until) {
+		return nil, nil
+	}
+	return &loginBlock{Until: until}, nil
+}
+
+// CheckAccount menolak akun yang sedang dikunci atau masih dalam jeda progresif
+func (t *LoginThrottle) CheckAccount(userID uuid.UUID) (*loginBlock, error) {
+	lockout, err := t.repo.GetLockout(userID)
+	if err != nil || lockout == nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if lockout.IsLocked(now) {
+		return &loginBlock{Until: *lockout.LockedUntil, Locked: true}, nil
+	}
+
+	if lockout.LastFailedAt != nil {
+		until := lockout.LastFailedAt.Add(t.delay(lockout.FailedCount))
+		if now.Before(until) {
+			return &loginBlock{Until: until}, nil
+		}
+	}
+	return nil, nil
+}
+
+// RecordFailure mencatat login gagal. userID nil jika username/email tidak dikenal.
+func (t *LoginThrottle) RecordFailure(identifier string, userID *uuid.UUID, ip string) error {
+	now := time.Now()
+	if err := t.repo.Record(t.newAttempt(identifier, userID, ip, false, now)); err != nil {
+		return err
+	}
+	if userID == nil {
+		return nil
+	}
+
+	// Kegagalan sebelum kunci yang sudah lewat atau di luar jendela tidak ikut dihitung
+	lockout, err := t.repo.RegisterFailure(*userID, now, now.Add(-t.cfg.FailureWindow))
+	if err != nil {
+		return err
+	}
+	if lockout.FailedCount < t.cfg.MaxFailures || lockout.IsLocked(now) {
+		return nil
+	}
+
+	if err := t.repo.Lock(*userID, now.Add(t.cfg.LockoutDuration)); err != nil {
+		return err
+	}
+	return t.repo.RecordEvent(&models.LockoutEvent{
+		ID:        uuid.New(),
+		UserID:    *userID,
+		Event:     models.LockoutEventLocked,
+		IPAddress: ip,
+		Reason:    fmt.Sprintf("%d consecutive failed logins", lockout.FailedCount),
+		CreatedAt: now,
+	})
+}
+
+// RecordSuccess mencatat login berhasil dan mereset hitungan gagal akun
+func (t *LoginThrottle) RecordSuccess(identifier string, userID uuid.UUID, ip string) error {
+	if err := t.repo.Record(t.newAttempt(identifier, &userID, ip, true, time.Now())); err != nil {
+		return err
+	}
+	return t.repo.Reset(userID)
+}
+
+// Unlock membuka kunci akun secara manual oleh admin
+func (t *LoginThrottle) Unlock(userID, actorID uuid.UUID, ip string) error {
+	if err := t.repo.Reset(userID); err != nil {
+		return err
+	}
+	return t.repo.RecordEvent(&models.LockoutEvent{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Event:     models.LockoutEventUnlocked,
+		IPAddress: ip,
+		ActorID:   &actorID,
+		Reason:    "unlocked by admin",
+		CreatedAt: time.Now(),
+	})
+}
+
+// delay jeda sebelum percobaan berikutnya: DelayBase * 2^(failures-DelayAfter), maksimal DelayMax
+func (t *LoginThrottle) delay(failures int) time.Duration {
+	if failures < t.cfg.DelayAfter {
+		return 0
+	}
+	d := t.cfg.DelayBase
+	for i := t.cfg.DelayAfter; i < failures && d < t.cfg.DelayMax; i++ {
+		d *= 2
+	}
+	if d > t.cfg.DelayMax {
+		d = t.cfg.DelayMax
+	}
+	return d
+}
+
+func (t *LoginThrottle) newAttempt(identifier string, userID *uuid.UUID, ip string, succeeded bool, at time.Time) *models.LoginAttempt {
+	return &models.LoginAttempt{
+		ID:         uuid.New(),
+		Identifier: truncate(identifier, 100),
+		UserID:     userID,
+		IPAddress:  ip,
+		Succeeded:  succeeded,
+		CreatedAt:  at,
+	}
+}
+
+// loginBlockedResponse 429 dengan Retry-After, tanpa menahan request di server
+func loginBlockedResponse(c *fiber.Ctx, block *loginBlock) error {
+	retryAfter := int(time.Until(block.Until).Seconds()) + 1
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+	if block.Locked {
+		return c.Status(429).JSON(fiber.Map{
+			"error":        "Account is temporarily locked due to too many failed login attempts",
+			"locked_until": block.Until,
+		})
+	}
+	return c.Status(429).JSON(fiber.Map{
+		"error":       "Too many failed login attempts, please try again later",
+		"retry_after": retryAfter,
+	})
+}
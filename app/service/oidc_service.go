@@ -0,0 +1,422 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/config"
+	"achievement-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var errOIDCStudentIDTaken = errors.New("student ID from identity provider is already registered")
+
+// OIDCService login SSO lewat IdP kampus. Setelah identitas dipetakan ke user lokal,
+// token yang diterbitkan sama persis dengan login password (termasuk langkah 2FA).
+type OIDCService struct {
+	oidcRepo    repository.OIDCRepository
+	userRepo    repository.UserRepository
+	roleRepo    repository.RoleRepository
+	studentRepo repository.StudentRepository
+	auth        *AuthService
+	client      *utils.OIDCClient
+	cfg         config.OIDCConfig
+}
+
+func NewOIDCService(
+	oidcRepo repository.OIDCRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	studentRepo repository.StudentRepository,
+	auth *AuthService,
+	client *utils.OIDCClient,
+	cfg config.OIDCConfig,
+) *OIDCService {
+	return &OIDCService{
+		oidcRepo:    oidcRepo,
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		studentRepo: studentRepo,
+		auth:        auth,
+		client:      client,
+		cfg:         cfg,
+	}
+}
+
+// Login godoc
+// @Summary Start SSO login
+// @Description Memulai login OIDC (authorization code + PKCE). Default redirect 302 ke halaman login IdP;
+// @Description dengan redirect=false URL-nya dikembalikan sebagai JSON (untuk SPA).
+// @Tags Authentication
+// @Produce json
+// @Param redirect query bool false "Redirect ke IdP (default true)"
+// @Success 200 {object} models.OIDCLoginURLResponse
+// @Success 302 {string} string "Redirect ke IdP"
+// @Failure 404 {object} object{error=string} "SSO is not configured"
+// @Failure 502 {object} object{error=string,details=string} "Identity provider unreachable"
+// @Failure 500 {object} object{error=string,details=string} "Server error"
+// @Router /auth/oidc/login [get]
+func (s *OIDCService) Login(c *fiber.Ctx) error {
+	if !s.cfg.Enabled() {
+		return c.Status(404).JSON(fiber.Map{
+			"error": models.ErrOIDCNotConfigured.Error(),
+		})
+	}
+
+	state, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to start login",
+			"details": err.Error(),
+		})
+	}
+	nonce, err := utils.GenerateOpaqueToken(16)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to start login",
+			"details": err.Error(),
+		})
+	}
+	verifier, err := utils.GeneratePKCEVerifier()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to start login",
+			"details": err.Error(),
+		})
+	}
+
+	authURL, err := s.client.AuthCodeURL(c.UserContext(), state, nonce, verifier)
+	if err != nil {
+		return c.Status(502).JSON(fiber.Map{
+			"error":   "Identity provider is unavailable",
+			"details": err.Error(),
+		})
+	}
+
+	now := time.Now()
+	loginState := &models.OIDCLoginState{
+		StateHash:    utils.HashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    now.Add(s.cfg.StateTTL),
+		CreatedAt:    now,
+	}
+	if err := s.oidcRepo.CreateState(loginState); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to start login",
+			"details": err.Error(),
+		})
+	}
+
+	if !c.QueryBool("redirect", true) {
+		return c.JSON(models.OIDCLoginURLResponse{
+			AuthorizationURL: authURL,
+			ExpiresAt:        loginState.ExpiresAt,
+		})
+	}
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// Callback godoc
+// @Summary SSO login callback
+// @Description Redirect URI yang dipanggil IdP. Menukar code, memverifikasi ID token lalu memetakan subject/email ke user.
+// @Description Email dengan domain di OIDC_AUTO_PROVISION_DOMAINS otomatis dibuatkan akun Mahasiswa.
+// @Description Respon sama dengan /auth/login (termasuk challenge 2FA bila aktif).
+// @Tags Authentication
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State dari /auth/oidc/login"
+// @Success 200 {object} models.LoginResponse "Login berhasil"
+// @Failure 400 {object} object{error=string} "Missing or expired state / code"
+// @Failure 401 {object} object{error=string,details=string} "Identity provider rejected the login"
+// @Failure 403 {object} object{error=string} "No linked account / email not verified / account inactive"
+// @Failure 409 {object} object{error=string} "Student ID already registered"
+// @Failure 500 {object} object{error=string,details=string} "Server error"
+// @Router /auth/oidc/callback [get]
+func (s *OIDCService) Callback(c *fiber.Ctx) error {
+	if !s.cfg.Enabled() {
+		return c.Status(404).JSON(fiber.Map{
+			"error": models.ErrOIDCNotConfigured.Error(),
+		})
+	}
+
+	if idpError := c.Query("error"); idpError != "" {
+		return c.Status(401).JSON(fiber.Map{
+			"error":   "Login at identity provider failed",
+			"details": strings.TrimSpace(idpError + " " + c.Query("error_description")),
+		})
+	}
+
+	code := c.Query("code")
+	state := c.Query("state")
+	if code == "" || state == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "code and state are required",
+		})
+	}
+
+	loginState, err := s.oidcRepo.ConsumeState(utils.HashToken(state))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check login state",
+			"details": err.Error(),
+		})
+	}
+	if loginState == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": models.ErrOIDCInvalidState.Error(),
+		})
+	}
+
+	profile, err := s.client.Authenticate(c.UserContext(), code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error":   "Failed to verify identity provider response",
+			"details": err.Error(),
+		})
+	}
+
+	user, err := s.resolveUser(profile)
+	if err != nil {
+		return oidcErrorResponse(c, err)
+	}
+
+	if !user.IsActive {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Account is inactive",
+		})
+	}
+
+	role, err := s.roleRepo.GetByID(user.RoleID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error getting role information",
+			"details": err.Error(),
+		})
+	}
+
+	challenge, err := s.auth.twoFactorChallenge(user, role)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create two-factor challenge",
+			"details": err.Error(),
+		})
+	}
+	if challenge != nil {
+		return c.JSON(challenge)
+	}
+
+	response, err := s.auth.completeLogin(c, user, role, user.Email)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate token",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(response)
+}
+
+// resolveUser urutan pemetaan: identitas yang sudah terhubung, lalu email terverifikasi,
+// lalu auto-provision Mahasiswa untuk domain yang diizinkan
+func (s *OIDCService) resolveUser(profile *models.OIDCProfile) (*models.User, error) {
+	now := time.Now()
+
+	identity, err := s.oidcRepo.GetIdentity(profile.Issuer, profile.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := s.userRepo.GetByID(identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, models.ErrOIDCAccountNotFound
+		}
+		if err := s.oidcRepo.TouchIdentity(identity.ID, profile.Email, now); err != nil {
+			fmt.Printf("Warning: Failed to update identity %s: %v\n", identity.ID, err)
+		}
+		return user, nil
+	}
+
+	if profile.Email == "" {
+		return nil, models.ErrOIDCAccountNotFound
+	}
+	// Email belum terverifikasi tidak boleh dipakai untuk mengambil alih akun yang ada
+	if !profile.EmailVerified {
+		return nil, models.ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.userRepo.GetByEmail(profile.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		if !s.canAutoProvision(profile.Email) {
+			return nil, models.ErrOIDCAccountNotFound
+		}
+		user, err = s.provisionStudent(profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.oidcRepo.CreateIdentity(&models.UserIdentity{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Issuer:      profile.Issuer,
+		Subject:     profile.Subject,
+		Email:       profile.Email,
+		CreatedAt:   now,
+		LastLoginAt: &now,
+	}); err != nil {
+		return nil, fmt.Errorf("link identity: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s *OIDCService) canAutoProvision(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range s.cfg.AutoProvisionDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// provisionStudent membuat user Mahasiswa beserta profil mahasiswanya dari claim IdP.
+// Password diisi acak sehingga akun hanya bisa dipakai lewat SSO sampai user reset password.
+func (s *OIDCService) provisionStudent(profile *models.OIDCProfile) (*models.User, error) {
+	role, err := s.roleRepo.GetByName("Mahasiswa")
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, errors.New("role Mahasiswa not found")
+	}
+
+	localPart := profile.Email[:strings.LastIndex(profile.Email, "@")]
+
+	studentID := profile.StringClaim(s.cfg.StudentIDClaim)
+	if studentID == "" {
+		studentID = localPart
+	}
+	if len(studentID) > 20 {
+		return nil, fmt.Errorf("student ID %q from identity provider is too long", studentID)
+	}
+	existing, err := s.studentRepo.GetByStudentID(studentID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errOIDCStudentIDTaken
+	}
+
+	username, err := s.availableUsername(profile, localPart)
+	if err != nil {
+		return nil, err
+	}
+
+	randomPassword, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := profile.Name
+	if fullName == "" {
+		fullName = username
+	}
+
+	now := time.Now()
+	user := &models.User{
+		ID:           uuid.New(),
+		Username:     username,
+		Email:        profile.Email,
+		PasswordHash: hashedPassword,
+		FullName:     truncate(fullName, 100),
+		RoleID:       role.ID,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if _, err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	student := models.Student{
+		ID:           uuid.New(),
+		UserID:       user.ID,
+		StudentID:    studentID,
+		ProgramStudy: truncate(profile.StringClaim(s.cfg.ProgramStudyClaim), 100),
+		AcademicYear: truncate(profile.StringClaim(s.cfg.AcademicYearClaim), 10),
+		CreatedAt:    now,
+	}
+	if _, err := s.studentRepo.Create(student); err != nil {
+		if deleteErr := s.userRepo.HardDelete(user.ID); deleteErr != nil {
+			fmt.Printf("CRITICAL: Failed to rollback provisioned user: %v\n", deleteErr)
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// availableUsername preferred_username / bagian lokal email, ditambah akhiran acak jika sudah dipakai
+func (s *OIDCService) availableUsername(profile *models.OIDCProfile, localPart string) (string, error) {
+	base := profile.PreferredUsername
+	if base == "" || strings.Contains(base, "@") {
+		base = localPart
+	}
+	base = truncate(strings.ToLower(base), 40)
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		existing, err := s.userRepo.GetByUsername(candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+		suffix, err := utils.GenerateOpaqueToken(3)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "-" + strings.ToLower(suffix)
+	}
+	return "", fmt.Errorf("could not find a free username for %q", base)
+}
+
+func oidcErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, models.ErrOIDCAccountNotFound), errors.Is(err, models.ErrOIDCEmailNotVerified):
+		return c.Status(403).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, errOIDCStudentIDTaken):
+		return c.Status(409).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(500).JSON(fiber.Map{
+		"error":   "Failed to resolve account",
+		"details": err.Error(),
+	})
+}
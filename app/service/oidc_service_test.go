@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/config"
+	"achievement-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const testIssuer = "https://sso.kampus.ac.id"
+
+func newTestOIDCService(oidcRepo *fakeOIDCRepo, userRepo *fakeUserRepo, studentRepo *fakeStudentRepo) *OIDCService {
+	cfg := config.OIDCConfig{
+		IssuerURL:            testIssuer,
+		ClientID:             "achievement",
+		AutoProvisionDomains: []string{"student.kampus.ac.id"},
+		StudentIDClaim:       "student_id",
+		ProgramStudyClaim:    "program_study",
+		AcademicYearClaim:    "academic_year",
+		StateTTL:             10 * time.Minute,
+	}
+	return NewOIDCService(oidcRepo, userRepo, newFakeRoleRepo("Mahasiswa"), studentRepo, nil, utils.NewOIDCClient(cfg), cfg)
+}
+
+func newTestProfile(subject, email string, verified bool) *models.OIDCProfile {
+	return &models.OIDCProfile{
+		Issuer:        testIssuer,
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: verified,
+		Claims:        map[string]interface{}{},
+	}
+}
+
+func TestOIDCCallbackRejectsUnknownState(t *testing.T) {
+	oidcRepo := newFakeOIDCRepo()
+	svc := newTestOIDCService(oidcRepo, newFakeUserRepo(), &fakeStudentRepo{})
+
+	// State milik login lain yang sudah kadaluarsa juga ditolak
+	_ = oidcRepo.CreateState(&models.OIDCLoginState{
+		StateHash: utils.HashToken("expired"),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+
+	app := fiber.New()
+	app.Get("/callback", svc.Callback)
+
+	for _, query := range []string{
+		"?code=abc",
+		"?code=abc&state=forged",
+		"?code=abc&state=expired",
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/callback"+query, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestOIDCResolveUserLinkedIdentity(t *testing.T) {
+	user := newTestUser("budi", "budi@kampus.ac.id")
+	identity := &models.UserIdentity{ID: uuid.New(), UserID: user.ID, Issuer: testIssuer, Subject: "sub-1"}
+	oidcRepo := newFakeOIDCRepo(identity)
+	svc := newTestOIDCService(oidcRepo, newFakeUserRepo(user), &fakeStudentRepo{})
+
+	// Email di IdP boleh berubah; identitas yang terhubung tetap memetakan ke user yang sama
+	got, err := svc.resolveUser(newTestProfile("sub-1", "budi.baru@kampus.ac.id", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("user = %s, want %s", got.ID, user.ID)
+	}
+	if len(oidcRepo.touched) != 1 || len(oidcRepo.identities) != 1 {
+		t.Errorf("touched = %d, identities = %d, want 1 and 1", len(oidcRepo.touched), len(oidcRepo.identities))
+	}
+}
+
+func TestOIDCResolveUserLinksVerifiedEmail(t *testing.T) {
+	user := newTestUser("budi", "budi@kampus.ac.id")
+	oidcRepo := newFakeOIDCRepo()
+	svc := newTestOIDCService(oidcRepo, newFakeUserRepo(user), &fakeStudentRepo{})
+
+	got, err := svc.resolveUser(newTestProfile("sub-1", "budi@kampus.ac.id", true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("user = %s, want %s", got.ID, user.ID)
+	}
+	if len(oidcRepo.identities) != 1 || oidcRepo.identities[0].UserID != user.ID || oidcRepo.identities[0].Subject != "sub-1" {
+		t.Fatalf("identities = %+v, want one link to %s", oidcRepo.identities, user.ID)
+	}
+}
+
+func TestOIDCResolveUserRejectsUnverifiedEmail(t *testing.T) {
+	user := newTestUser("budi", "budi@kampus.ac.id")
+	oidcRepo := newFakeOIDCRepo()
+	svc := newTestOIDCService(oidcRepo, newFakeUserRepo(user), &fakeStudentRepo{})
+
+	_, err := svc.resolveUser(newTestProfile("sub-1", "budi@kampus.ac.id", false))
+	if !errors.Is(err, models.ErrOIDCEmailNotVerified) {
+		t.Fatalf("err = %v, want ErrOIDCEmailNotVerified", err)
+	}
+	if len(oidcRepo.identities) != 0 {
+		t.Error("unverified email must not link an identity")
+	}
+}
+
+func TestOIDCResolveUserUnknownEmailOutsideProvisionDomains(t *testing.T) {
+	oidcRepo := newFakeOIDCRepo()
+	userRepo := newFakeUserRepo()
+	svc := newTestOIDCService(oidcRepo, userRepo, &fakeStudentRepo{})
+
+	_, err := svc.resolveUser(newTestProfile("sub-1", "tamu@gmail.com", true))
+	if !errors.Is(err, models.ErrOIDCAccountNotFound) {
+		t.Fatalf("err = %v, want ErrOIDCAccountNotFound", err)
+	}
+	if len(userRepo.users) != 0 || len(oidcRepo.identities) != 0 {
+		t.Error("no account or identity may be created outside the provisioning domains")
+	}
+}
+
+func TestOIDCResolveUserAutoProvisionsStudent(t *testing.T) {
+	oidcRepo := newFakeOIDCRepo()
+	userRepo := newFakeUserRepo()
+	studentRepo := &fakeStudentRepo{}
+	svc := newTestOIDCService(oidcRepo, userRepo, studentRepo)
+
+	profile := newTestProfile("sub-1", "ani@student.kampus.ac.id", true)
+	profile.Name = "Ani"
+	profile.Claims["student_id"] = "2201001"
+	profile.Claims["program_study"] = "Informatika"
+	profile.Claims["academic_year"] = "2022"
+
+	user, err := svc.resolveUser(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Email != "ani@student.kampus.ac.id" || user.FullName != "Ani" || user.Username != "ani" {
+		t.Errorf("user = %+v", user)
+	}
+	if len(studentRepo.students) != 1 || studentRepo.students[0].UserID != user.ID || studentRepo.students[0].StudentID != "2201001" {
+		t.Fatalf("students = %+v, want profile 2201001 for new user", studentRepo.students)
+	}
+	if studentRepo.students[0].ProgramStudy != "Informatika" || studentRepo.students[0].AcademicYear != "2022" {
+		t.Errorf("student claims not mapped: %+v", studentRepo.students[0])
+	}
+	if len(oidcRepo.identities) != 1 || oidcRepo.identities[0].UserID != user.ID {
+		t.Errorf("identities = %+v, want link to new user", oidcRepo.identities)
+	}
+}
+
+func TestOIDCResolveUserRejectsTakenStudentID(t *testing.T) {
+	oidcRepo := newFakeOIDCRepo()
+	userRepo := newFakeUserRepo()
+	studentRepo := &fakeStudentRepo{students: []models.Student{{ID: uuid.New(), StudentID: "2201001"}}}
+	svc := newTestOIDCService(oidcRepo, userRepo, studentRepo)
+
+	profile := newTestProfile("sub-1", "ani@student.kampus.ac.id", true)
+	profile.Claims["student_id"] = "2201001"
+
+	_, err := svc.resolveUser(profile)
+	if !errors.Is(err, errOIDCStudentIDTaken) {
+		t.Fatalf("err = %v, want errOIDCStudentIDTaken", err)
+	}
+	if len(userRepo.users) != 0 || len(oidcRepo.identities) != 0 {
+		t.Error("no account or identity may be created when the student ID is taken")
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"achievement-backend/app/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// queryCursor mode cursor aktif jika parameter cursor dikirim; cursor kosong (?cursor=)
+// berarti halaman pertama. Tanpa parameter cursor list memakai page/limit seperti biasa.
+func queryCursor(c *fiber.Ctx) (*models.Cursor, bool, error) {
+	if !c.Context().QueryArgs().Has("cursor") {
+		return nil, false, nil
+	}
+	value := c.Query("cursor")
+	if value == "" {
+		return nil, true, nil
+	}
+	cursor, err := models.DecodeCursor(value)
+	if err != nil {
+		return nil, true, err
+	}
+	return cursor, true, nil
+}
+
+// cursorPagination blok "pagination" untuk mode cursor; total sengaja tidak dihitung
+func cursorPagination(limit int, page models.CursorPage) fiber.Map {
+	var next, prev *string
+	if page.Next != nil {
+		encoded := page.Next.Encode()
+		next = &encoded
+	}
+	if page.Prev != nil {
+		encoded := page.Prev.Encode()
+		prev = &encoded
+	}
+	return fiber.Map{
+		"limit":       limit,
+		"next_cursor": next,
+		"prev_cursor": prev,
+		"has_next":    next != nil,
+		"has_prev":    prev != nil,
+	}
+}
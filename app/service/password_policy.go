@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/config"
+	"achievement-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// bcrypt hanya memakai 72 byte pertama, sisanya diabaikan
+const passwordMaxBytes = 72
+
+// PasswordPolicy aturan password baru untuk create user, update oleh admin,
+// change password, dan reset password
+type PasswordPolicy struct {
+	historyRepo repository.PasswordHistoryRepository
+	cfg         config.PasswordPolicyConfig
+}
+
+func NewPasswordPolicy(historyRepo repository.PasswordHistoryRepository, cfg config.PasswordPolicyConfig) *PasswordPolicy {
+	return &PasswordPolicy{
+		historyRepo: historyRepo,
+		cfg:         cfg,
+	}
+}
+
+// Validate mengembalikan *models.PasswordPolicyError jika ada aturan yang dilanggar.
+// user nil untuk user baru (tanpa cek riwayat).
+func (p *PasswordPolicy) Validate(password string, user *models.User) error {
+	violations := p.check(password)
+
+	// Cek riwayat butuh beberapa kali bcrypt, jadi hanya jika aturan lain sudah lolos
+	if len(violations) == 0 && user != nil && p.cfg.HistorySize > 0 {
+		reused, err := p.reused(password, user)
+		if err != nil {
+			return err
+		}
+		if reused {
+			violations = append(violations, models.PasswordViolation{
+				Rule:    models.PasswordRuleReused,
+				Message: fmt.Sprintf("password must not match any of your last %d passwords", p.cfg.HistorySize),
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &models.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Remember mencatat hash password yang baru di-set ke riwayat
+func (p *PasswordPolicy) Remember(userID uuid.UUID, passwordHash string) error {
+	if p.cfg.HistorySize <= 0 {
+		return nil
+	}
+	return p.historyRepo.Add(&models.PasswordHistory{
+		ID:           uuid.New(),
+		UserID:       userID,
+		PasswordHash: passwordHash,
+		CreatedAt:    time.Now(),
+	}, p.cfg.HistorySize)
+}
+
+func (p *PasswordPolicy) check(password string) []models.PasswordViolation {
+	var violations []models.PasswordViolation
+	add := func(rule, message string) {
+		violations = append(violations, models.PasswordViolation{Rule: rule, Message: message})
+	}
+
+	if len([]rune(password)) < p.cfg.MinLength {
+		add(models.PasswordRuleMinLength, fmt.Sprintf("password must be at least %d characters", p.cfg.MinLength))
+	}
+	if len(password) > passwordMaxBytes {
+		add(models.PasswordRuleMaxLength, fmt.Sprintf("password must be at most %d bytes", passwordMaxBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r), unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.cfg.RequireUpper && !hasUpper {
+		add(models.PasswordRuleUpper, "password must contain an uppercase letter")
+	}
+	if p.cfg.RequireLower && !hasLower {
+		add(models.PasswordRuleLower, "password must contain a lowercase letter")
+	}
+	if p.cfg.RequireDigit && !hasDigit {
+		add(models.PasswordRuleDigit, "password must contain a digit")
+	}
+	if p.cfg.RequireSymbol && !hasSymbol {
+		add(models.PasswordRuleSymbol, "password must contain a symbol")
+	}
+	if p.cfg.BlockCommon && utils.IsCommonPassword(password) {
+		add(models.PasswordRuleCommon, "password is too common")
+	}
+
+	return violations
+}
+
+// reused membandingkan dengan password saat ini dan riwayat, total HistorySize hash terakhir
+func (p *PasswordPolicy) reused(password string, user *models.User) (bool, error) {
+	hashes, err := p.historyRepo.GetRecentHashes(user.ID, p.cfg.HistorySize)
+	if err != nil {
+		return false, err
+	}
+
+	// User lama belum punya riwayat; password saat ini selalu ikut dicek
+	if user.PasswordHash != "" && (len(hashes) == 0 || hashes[0] != user.PasswordHash) {
+		hashes = append([]string{user.PasswordHash}, hashes...)
+		if len(hashes) > p.cfg.HistorySize {
+			hashes = hashes[:p.cfg.HistorySize]
+		}
+	}
+
+	for _, hash := range hashes {
+		if utils.CheckPasswordHash(password, hash) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// passwordPolicyErrorResponse 400 dengan daftar aturan yang dilanggar
+func passwordPolicyErrorResponse(c *fiber.Ctx, err error) error {
+	var policyErr *models.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		return c.Status(400).JSON(fiber.Map{
+			"error":      "Password does not meet the password policy",
+			"violations": policyErr.Violations,
+		})
+	}
+
+	return c.Status(500).JSON(fiber.Map{
+		"error":   "Failed to validate password",
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/config"
+	"achievement-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// passwordResetCooldown jeda minimum antar permintaan reset untuk user yang sama
+const passwordResetCooldown = time.Minute
+
+type PasswordResetService struct {
+	userRepo          repository.UserRepository
+	passwordResetRepo repository.PasswordResetRepository
+	sessionRepo       repository.SessionRepository
+	passwordPolicy    *PasswordPolicy
+	mailer            utils.Mailer
+	cfg               config.PasswordResetConfig
+}
+
+func NewPasswordResetService(
+	userRepo repository.UserRepository,
+	passwordResetRepo repository.PasswordResetRepository,
+	sessionRepo repository.SessionRepository,
+	passwordPolicy *PasswordPolicy,
+	mailer utils.Mailer,
+	cfg config.PasswordResetConfig,
+) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:          userRepo,
+		passwordResetRepo: passwordResetRepo,
+		sessionRepo:       sessionRepo,
+		passwordPolicy:    passwordPolicy,
+		mailer:            mailer,
+		cfg:               cfg,
+	}
+}
+
+// ForgotPassword godoc
+// @Summary Request password reset
+// @Description Mengirim link reset password ke email user. Respon selalu sama agar tidak membocorkan email yang terdaftar.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Forgot password request"
+// @Success 200 {object} object{message=string}
+// @Failure 400 {object} object{error=string}
+// @Failure 500 {object} object{error=string,details=string}
+// @Router /auth/forgot-password [post]
+func (s *PasswordResetService) ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Email is required",
+		})
+	}
+
+	accepted := fiber.Map{
+		"message": "If the email is registered, a password reset link has been sent",
+	}
+
+	user, err := s.userRepo.GetByEmail(req.Email)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error checking user",
+			"details": err.Error(),
+		})
+	}
+	if user == nil || !user.IsActive {
+		return c.JSON(accepted)
+	}
+
+	latest, err := s.passwordResetRepo.GetLatestByUser(user.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error checking reset requests",
+			"details": err.Error(),
+		})
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < passwordResetCooldown {
+		return c.JSON(accepted)
+	}
+
+	token, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate reset token",
+			"details": err.Error(),
+		})
+	}
+
+	now := time.Now()
+	reset := &models.PasswordResetToken{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		TokenHash:   utils.HashToken(token),
+		RequestedIP: c.IP(),
+		ExpiresAt:   now.Add(s.cfg.TTL),
+		CreatedAt:   now,
+	}
+	if err := s.passwordResetRepo.Create(reset); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to save reset token",
+			"details": err.Error(),
+		})
+	}
+
+	// Dikirim di background supaya waktu respon tidak membedakan email terdaftar / tidak
+	msg := s.resetMail(user, token)
+	go func() {
+		if err := s.mailer.Send(msg); err != nil {
+			fmt.Printf("Warning: Failed to send password reset email to user %s: %v\n", user.ID, err)
+		}
+	}()
+
+	return c.JSON(accepted)
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Mengganti password memakai token dari email. Token hanya bisa dipakai sekali dan semua sesi user di-revoke.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset password request"
+// @Success 200 {object} object{message=string}
+// @Failure 400 {object} object{error=string,violations=[]models.PasswordViolation} "Invalid token or password policy violation"
+// @Failure 500 {object} object{error=string,details=string}
+// @Router /auth/reset-password [post]
+func (s *PasswordResetService) ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Token == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Reset token is required",
+		})
+	}
+
+	if req.NewPassword != req.ConfirmPassword {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "New password and confirmation do not match",
+		})
+	}
+
+	tokenHash := utils.HashToken(req.Token)
+
+	// Token dicek dulu tanpa dipakai supaya tidak hangus jika password ditolak policy
+	reset, err := s.passwordResetRepo.GetValidByHash(tokenHash)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error checking reset token",
+			"details": err.Error(),
+		})
+	}
+	if reset == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid or expired reset token",
+		})
+	}
+
+	user, err := s.userRepo.GetByID(reset.UserID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error checking user",
+			"details": err.Error(),
+		})
+	}
+	if user == nil || !user.IsActive {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid or expired reset token",
+		})
+	}
+
+	if err := s.passwordPolicy.Validate(req.NewPassword, user); err != nil {
+		return passwordPolicyErrorResponse(c, err)
+	}
+
+	// Consume atomik: request paralel dengan token yang sama hanya satu yang lolos
+	reset, err = s.passwordResetRepo.Consume(tokenHash)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error checking reset token",
+			"details": err.Error(),
+		})
+	}
+	if reset == nil || reset.UserID != user.ID {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid or expired reset token",
+		})
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to hash password",
+			"details": err.Error(),
+		})
+	}
+
+	if err := s.userRepo.UpdatePassword(user.ID, hashedPassword); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to update password",
+			"details": err.Error(),
+		})
+	}
+
+	if err := s.passwordPolicy.Remember(user.ID, hashedPassword); err != nil {
+		fmt.Printf("Warning: Failed to record password history for user %s: %v\n", user.ID, err)
+	}
+
+	// Password lama mungkin bocor, jadi semua sesi yang ada diputus
+	if _, err := s.sessionRepo.RevokeAllByUser(user.ID, nil); err != nil {
+		fmt.Printf("Warning: Failed to revoke sessions for user %s: %v\n", user.ID, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Password has been reset successfully",
+	})
+}
+
+func (s *PasswordResetService) resetMail(user *models.User, token string) utils.MailMessage {
+	link := s.cfg.URL
+	if strings.Contains(link, "?") {
+		link += "&token=" + url.QueryEscape(token)
+	} else {
+		link += "?token=" + url.QueryEscape(token)
+	}
+
+	body := fmt.Sprintf(`Halo %s,
+
+Kami menerima permintaan reset password untuk akun %s.
+Buka link berikut untuk membuat password baru (berlaku %s):
+
+%s
+
+Jika Anda tidak meminta reset password, abaikan email ini.
+`, user.FullName, user.Username, s.cfg.TTL, link)
+
+	return utils.MailMessage{
+		To:      user.Email,
+		Subject: "Reset password Student Achievement System",
+		Body:    body,
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ReconciliationService struct {
+	reportRepo repository.ReconciliationReportRepository
+}
+
+func NewReconciliationService(reportRepo repository.ReconciliationReportRepository) *ReconciliationService {
+	return &ReconciliationService{
+		reportRepo: reportRepo,
+	}
+}
+
+// GetAll godoc
+// @Summary List reconciliation reports
+// @Description Ringkasan laporan rekonsiliasi Postgres ↔ Mongo (command & job terjadwal), terbaru lebih dulu.
+// @Tags Reconciliation
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit per page (max 100)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /reconciliation-reports [get]
+func (s *ReconciliationService) GetAll(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	reports, total, err := s.reportRepo.List(page, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get reconciliation reports",
+			"details": err.Error(),
+		})
+	}
+
+	totalPages := (total + limit - 1) / limit
+
+	return c.JSON(fiber.Map{
+		"data": reports,
+		"pagination": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": totalPages,
+			"has_next":    page < totalPages,
+			"has_prev":    page > 1,
+		},
+	})
+}
+
+// GetByID godoc
+// @Summary Get reconciliation report
+// @Description Detail laporan rekonsiliasi beserta daftar issue dan tindakan yang diambil.
+// @Tags Reconciliation
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Report ID (UUID)"
+// @Success 200 {object} models.ReconciliationReport
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /reconciliation-reports/{id} [get]
+func (s *ReconciliationService) GetByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid report ID",
+		})
+	}
+
+	report, err := s.reportRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get reconciliation report",
+			"details": err.Error(),
+		})
+	}
+	if report == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Reconciliation report not found",
+		})
+	}
+	if report.Issues == nil {
+		report.Issues = []models.ReconciliationIssue{}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": report,
+	})
+}
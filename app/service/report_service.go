@@ -18,6 +18,7 @@ type ReportService struct {
 	studentRepo repository.StudentRepository
 	lecturerRepo repository.LecturerRepository
 	roleRepo    repository.RoleRepository
+	authz       *Authorizer
 }
 
 func NewReportService(
@@ -33,6 +34,7 @@ func NewReportService(
 		studentRepo: studentRepo,
 		lecturerRepo: lecturerRepo,
 		roleRepo:    roleRepo,
+		authz:       NewAuthorizer(studentRepo, lecturerRepo),
 	}
 }
 
@@ -48,6 +50,7 @@ func NewReportService(
 //
 // @Tags Report
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Produce json
 //
 // @Param start_date query string false "Start date (YYYY-MM-DD)"
@@ -60,14 +63,9 @@ func NewReportService(
 //
 // @Router /reports/statistics [get]
 func (s *ReportService) GetStatistics(c *fiber.Ctx) error {
-	currentUser, ok := c.Locals("user").(*models.User)
-	if !ok {
-		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
-	}
-
-	role, err := s.roleRepo.GetByID(currentUser.RoleID)
-	if err != nil || role == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
 	// Parse dates
@@ -83,25 +81,10 @@ func (s *ReportService) GetStatistics(c *fiber.Ctx) error {
 		}
 	}
 
-	// Determine actor ID
-	var actorID uuid.UUID
-	switch role.Name {
-	case "Admin":
-		actorID = currentUser.ID
-	case "Dosen Wali":
-		lecturer, err := s.lecturerRepo.GetByUserID(currentUser.ID)
-		if err != nil || lecturer == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "You are not registered as a lecturer"})
-		}
-		actorID = lecturer.ID
-	case "Mahasiswa":
-		student, err := s.studentRepo.GetByUserID(currentUser.ID)
-		if err != nil || student == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "You are not registered as a student"})
-		}
-		actorID = student.ID
-	default:
-		return c.Status(403).JSON(fiber.Map{"error": "Access denied for your role"})
+	// Cakupan statistik: semua, mahasiswa bimbingan, atau milik sendiri
+	scope, err := s.authz.AchievementScope(actor)
+	if err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": "You are not registered as a student or lecturer"})
 	}
 
 	// Get stats
@@ -110,7 +93,7 @@ func (s *ReportService) GetStatistics(c *fiber.Ctx) error {
 		ctx = context.Background()
 	}
 
-	stats, err := s.reportRepo.GetAchievementStats(ctx, actorID, role.Name, startDate, endDate)
+	stats, err := s.reportRepo.GetAchievementStats(ctx, scope, startDate, endDate)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error":   "Failed to generate statistics",
@@ -137,6 +120,7 @@ func (s *ReportService) GetStatistics(c *fiber.Ctx) error {
 //
 // @Tags Report
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Produce json
 //
 // @Param id path string true "Student UUID"
@@ -158,9 +142,9 @@ func (s *ReportService) GetStudentReport(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid student ID"})
 	}
 
-	currentUser, ok := c.Locals("user").(*models.User)
-	if !ok {
-		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
 	student, err := s.studentRepo.GetByID(studentID)
@@ -171,22 +155,9 @@ func (s *ReportService) GetStudentReport(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Student not found"})
 	}
 
-	// Authorization
-	role, err := s.roleRepo.GetByID(currentUser.RoleID)
-	if err != nil || role == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to verify user role"})
-	}
-
-	authorized := false
-	switch role.Name {
-	case "Admin":
-		authorized = true
-	case "Dosen Wali":
-		lecturer, _ := s.lecturerRepo.GetByUserID(currentUser.ID)
-		authorized = lecturer != nil && student.AdvisorID != nil && *student.AdvisorID == lecturer.ID
-	case "Mahasiswa":
-		authorized = student.UserID == currentUser.ID
-	}
+	// Authorization: pemilik, dosen wali, atau akses global prestasi
+	relations := s.authz.RelationsTo(actor, student, models.PermissionAchievementManage)
+	authorized := models.HasAnyRelation(models.AchievementActionRelations(models.ActionRead), relations)
 
 	if !authorized {
 		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
@@ -211,7 +182,7 @@ func (s *ReportService) GetStudentReport(c *fiber.Ctx) error {
 		ctx = context.Background()
 	}
 
-	stats, err := s.reportRepo.GetAchievementStats(ctx, studentID, "Mahasiswa", startDate, endDate)
+	stats, err := s.reportRepo.GetAchievementStats(ctx, models.AchievementScope{Relation: models.RelationOwner, ID: studentID}, startDate, endDate)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error":   "Failed to generate student statistics",
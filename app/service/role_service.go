@@ -0,0 +1,602 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RoleService struct {
+	roleRepo    repository.RoleRepository
+	auditLogger *AuditLogger
+}
+
+func NewRoleService(roleRepo repository.RoleRepository, auditLogger *AuditLogger) *RoleService {
+	return &RoleService{
+		roleRepo:    roleRepo,
+		auditLogger: auditLogger,
+	}
+}
+
+// GetAllRoles godoc
+// @Summary Get all roles
+// @Description Mengambil daftar role dengan pagination
+// @Tags Role
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit per page (max 100)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /roles [get]
+func (s *RoleService) GetAllRoles(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	roles, total, err := s.roleRepo.GetAll(page, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get roles",
+			"details": err.Error(),
+		})
+	}
+
+	totalPages := (total + limit - 1) / limit
+
+	return c.JSON(fiber.Map{
+		"data": roles,
+		"pagination": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": totalPages,
+			"has_next":    page < totalPages,
+			"has_prev":    page > 1,
+		},
+	})
+}
+
+// GetRoleByID godoc
+// @Summary Get role detail
+// @Description Mengambil detail role beserta permission-nya
+// @Tags Role
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Role UUID"
+// @Success 200 {object} models.RoleWithPermissions
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /roles/{id} [get]
+func (s *RoleService) GetRoleByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	role, err := s.roleRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get role",
+			"details": err.Error(),
+		})
+	}
+	if role == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Role not found",
+		})
+	}
+
+	permissions, err := s.roleRepo.GetPermissionsByRoleID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get role permissions",
+			"details": err.Error(),
+		})
+	}
+	if permissions == nil {
+		permissions = []models.Permission{}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": models.RoleWithPermissions{
+			Role:        *role,
+			Permissions: permissions,
+		},
+	})
+}
+
+// CreateRole godoc
+// @Summary Create role
+// @Description Membuat role baru (tanpa permission)
+// @Tags Role
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.CreateRoleRequest true "Create role payload"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /roles [post]
+func (s *RoleService) CreateRole(c *fiber.Ctx) error {
+	var req models.CreateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 50 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Role name is required (max 50 characters)",
+		})
+	}
+
+	existing, err := s.roleRepo.GetByName(req.Name)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check role",
+			"details": err.Error(),
+		})
+	}
+	if existing != nil {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Role name already exists",
+		})
+	}
+
+	role := &models.Role{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   time.Now(),
+	}
+
+	audit := s.auditLogger.Entry(c, AuditEvent{
+		Action:     models.AuditActionRoleCreate,
+		TargetType: models.AuditTargetRole,
+		TargetID:   role.ID.String(),
+		After:      role,
+	})
+
+	if err := s.roleRepo.Create(role, audit); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create role",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Role created successfully",
+		"data":    role,
+	})
+}
+
+// DeleteRole godoc
+// @Summary Delete role
+// @Description Menghapus role yang tidak lagi dipakai user. Role bawaan tidak bisa dihapus.
+// @Tags Role
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Role UUID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /roles/{id} [delete]
+func (s *RoleService) DeleteRole(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	role, err := s.roleRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get role",
+			"details": err.Error(),
+		})
+	}
+	if role == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Role not found",
+		})
+	}
+
+	if models.IsSystemRole(role.Name) {
+		return roleErrorResponse(c, models.ErrSystemRole, "Failed to delete role")
+	}
+
+	permissions, err := s.roleRepo.GetPermissionsByRoleID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get role permissions",
+			"details": err.Error(),
+		})
+	}
+
+	audit := s.auditLogger.Entry(c, AuditEvent{
+		Action:     models.AuditActionRoleDelete,
+		TargetType: models.AuditTargetRole,
+		TargetID:   id.String(),
+		Before:     models.RoleWithPermissions{Role: *role, Permissions: permissions},
+	})
+
+	if err := s.roleRepo.Delete(id, audit); err != nil {
+		return roleErrorResponse(c, err, "Failed to delete role")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Role deleted successfully",
+	})
+}
+
+// UpdateTwoFactorRequirement godoc
+// @Summary Enforce two-factor authentication for a role
+// @Description Jika diwajibkan, user dengan role ini harus enrol TOTP saat login berikutnya
+// @Tags Role
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Role UUID"
+// @Param body body models.UpdateRoleTwoFactorRequest true "Two-factor requirement"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /roles/{id}/two-factor [put]
+func (s *RoleService) UpdateTwoFactorRequirement(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	var req models.UpdateRoleTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	role, err := s.roleRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get role",
+			"details": err.Error(),
+		})
+	}
+	if role == nil {
+		return roleErrorResponse(c, models.ErrRoleNotFound, "Failed to update two-factor requirement")
+	}
+
+	updated := *role
+	updated.RequireTwoFactor = req.Required
+	audit := s.auditLogger.Entry(c, AuditEvent{
+		Action:     models.AuditActionRoleTwoFactorUpdate,
+		TargetType: models.AuditTargetRole,
+		TargetID:   id.String(),
+		Before:     role,
+		After:      updated,
+	})
+
+	if err := s.roleRepo.SetTwoFactorRequired(id, req.Required, audit); err != nil {
+		return roleErrorResponse(c, err, "Failed to update two-factor requirement")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Two-factor requirement updated successfully",
+	})
+}
+
+// AttachPermission godoc
+// @Summary Attach permission to role
+// @Tags Role
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Role UUID"
+// @Param permissionId path string true "Permission UUID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /roles/{id}/permissions/{permissionId} [post]
+func (s *RoleService) AttachPermission(c *fiber.Ctx) error {
+	roleID, permissionID, ok := parseRolePermissionParams(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid role ID or permission ID",
+		})
+	}
+
+	audit, err := s.rolePermissionAudit(c, models.AuditActionRolePermissionAttach, roleID, permissionID)
+	if err != nil {
+		return roleErrorResponse(c, err, "Failed to attach permission")
+	}
+
+	if err := s.roleRepo.AssignPermission(roleID, permissionID, audit); err != nil {
+		return roleErrorResponse(c, err, "Failed to attach permission")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Permission attached successfully",
+	})
+}
+
+// DetachPermission godoc
+// @Summary Detach permission from role
+// @Description Role terakhir yang memegang user:manage tidak bisa kehilangan permission tersebut
+// @Tags Role
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Role UUID"
+// @Param permissionId path string true "Permission UUID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /roles/{id}/permissions/{permissionId} [delete]
+func (s *RoleService) DetachPermission(c *fiber.Ctx) error {
+	roleID, permissionID, ok := parseRolePermissionParams(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid role ID or permission ID",
+		})
+	}
+
+	audit, err := s.rolePermissionAudit(c, models.AuditActionRolePermissionDetach, roleID, permissionID)
+	if err != nil {
+		return roleErrorResponse(c, err, "Failed to detach permission")
+	}
+
+	if err := s.roleRepo.RemovePermission(roleID, permissionID, audit); err != nil {
+		return roleErrorResponse(c, err, "Failed to detach permission")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Permission detached successfully",
+	})
+}
+
+// GetAllPermissions godoc
+// @Summary Get all permissions
+// @Tags Permission
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /permissions [get]
+func (s *RoleService) GetAllPermissions(c *fiber.Ctx) error {
+	permissions, err := s.roleRepo.GetAllPermissions()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get permissions",
+			"details": err.Error(),
+		})
+	}
+	if permissions == nil {
+		permissions = []models.Permission{}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": permissions,
+	})
+}
+
+// CreatePermission godoc
+// @Summary Create permission
+// @Description Membuat permission baru, nama dibentuk dari resource:action
+// @Tags Permission
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.CreatePermissionRequest true "Create permission payload"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /permissions [post]
+func (s *RoleService) CreatePermission(c *fiber.Ctx) error {
+	var req models.CreatePermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	req.Resource = strings.TrimSpace(req.Resource)
+	req.Action = strings.TrimSpace(req.Action)
+	if req.Resource == "" || req.Action == "" ||
+		strings.Contains(req.Resource, ":") || strings.Contains(req.Action, ":") ||
+		len(req.Resource) > 50 || len(req.Action) > 50 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Resource and action are required (max 50 characters, no ':')",
+		})
+	}
+
+	name := req.Resource + ":" + req.Action
+	existing, err := s.roleRepo.GetPermissionByName(name)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check permission",
+			"details": err.Error(),
+		})
+	}
+	if existing != nil {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Permission already exists",
+		})
+	}
+
+	permission := &models.Permission{
+		ID:          uuid.New(),
+		Name:        name,
+		Resource:    req.Resource,
+		Action:      req.Action,
+		Description: req.Description,
+	}
+
+	audit := s.auditLogger.Entry(c, AuditEvent{
+		Action:     models.AuditActionPermissionCreate,
+		TargetType: models.AuditTargetPermission,
+		TargetID:   permission.ID.String(),
+		After:      permission,
+	})
+
+	if err := s.roleRepo.CreatePermission(permission, audit); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create permission",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Permission created successfully",
+		"data":    permission,
+	})
+}
+
+// DeletePermission godoc
+// @Summary Delete permission
+// @Description Menghapus permission yang tidak lagi dipasang di role manapun. user:manage tidak bisa dihapus.
+// @Tags Permission
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Permission UUID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /permissions/{id} [delete]
+func (s *RoleService) DeletePermission(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid permission ID",
+		})
+	}
+
+	permission, err := s.roleRepo.GetPermissionByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get permission",
+			"details": err.Error(),
+		})
+	}
+	if permission == nil {
+		return roleErrorResponse(c, models.ErrPermissionNotFound, "Failed to delete permission")
+	}
+
+	audit := s.auditLogger.Entry(c, AuditEvent{
+		Action:     models.AuditActionPermissionDelete,
+		TargetType: models.AuditTargetPermission,
+		TargetID:   id.String(),
+		Before:     permission,
+	})
+
+	if err := s.roleRepo.DeletePermission(id, audit); err != nil {
+		return roleErrorResponse(c, err, "Failed to delete permission")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Permission deleted successfully",
+	})
+}
+
+// rolePermissionAudit entri audit attach/detach dengan snapshot daftar permission role
+// sebelum dan sesudah perubahan
+func (s *RoleService) rolePermissionAudit(c *fiber.Ctx, action string, roleID, permissionID uuid.UUID) (*models.AuditLog, error) {
+	permission, err := s.roleRepo.GetPermissionByID(permissionID)
+	if err != nil {
+		return nil, err
+	}
+	if permission == nil {
+		return nil, models.ErrPermissionNotFound
+	}
+
+	current, err := s.roleRepo.GetPermissionsByRoleID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	before := make([]string, 0, len(current))
+	after := make([]string, 0, len(current)+1)
+	for _, p := range current {
+		before = append(before, p.Name)
+		if p.ID != permissionID {
+			after = append(after, p.Name)
+		}
+	}
+	if action == models.AuditActionRolePermissionAttach {
+		after = append(after, permission.Name)
+	}
+
+	return s.auditLogger.Entry(c, AuditEvent{
+		Action:     action,
+		TargetType: models.AuditTargetRole,
+		TargetID:   roleID.String(),
+		Before:     fiber.Map{"permissions": before},
+		After:      fiber.Map{"permissions": after},
+	}), nil
+}
+
+func parseRolePermissionParams(c *fiber.Ctx) (uuid.UUID, uuid.UUID, bool) {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	permissionID, err := uuid.Parse(c.Params("permissionId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return roleID, permissionID, true
+}
+
+// roleErrorResponse memetakan error repository role/permission ke HTTP status
+func roleErrorResponse(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, models.ErrRoleNotFound),
+		errors.Is(err, models.ErrPermissionNotFound),
+		errors.Is(err, models.ErrPermissionNotAssigned):
+		return c.Status(404).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, models.ErrRoleInUse),
+		errors.Is(err, models.ErrSystemRole),
+		errors.Is(err, models.ErrPermissionInUse),
+		errors.Is(err, models.ErrPermissionAlreadyAssigned),
+		errors.Is(err, models.ErrLastUserManager):
+		return c.Status(409).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(500).JSON(fiber.Map{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
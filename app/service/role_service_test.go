@@ -0,0 +1,100 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"achievement-backend/app/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// newRoleServiceApp route role dengan actor terautentikasi di locals, seperti setelah RequireAuth
+func newRoleServiceApp(roleRepo *fakeRoleRepo, actorID uuid.UUID) *fiber.App {
+	svc := NewRoleService(roleRepo, NewAuditLogger(nil))
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", actorID)
+		return c.Next()
+	})
+	app.Put("/roles/:id/two-factor", svc.UpdateTwoFactorRequirement)
+	app.Post("/roles/:id/permissions/:permissionId", svc.AttachPermission)
+	return app
+}
+
+func TestRoleServiceAuditsTwoFactorUpdate(t *testing.T) {
+	roleRepo := newFakeRoleRepo("Admin")
+	role := roleRepo.roles[0]
+	actorID := uuid.New()
+	app := newRoleServiceApp(roleRepo, actorID)
+
+	req := httptest.NewRequest("PUT", "/roles/"+role.ID.String()+"/two-factor", strings.NewReader(`{"required":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	if len(roleRepo.audits) != 1 || roleRepo.audits[0] == nil {
+		t.Fatalf("audits = %v, want one entry passed to the repository", roleRepo.audits)
+	}
+	audit := roleRepo.audits[0]
+	if audit.Action != models.AuditActionRoleTwoFactorUpdate || audit.TargetID != role.ID.String() {
+		t.Errorf("audit = %s %s", audit.Action, audit.TargetID)
+	}
+	if audit.ActorID == nil || *audit.ActorID != actorID {
+		t.Errorf("actor = %v, want %s", audit.ActorID, actorID)
+	}
+	var before, after models.Role
+	if err := json.Unmarshal(audit.Before, &before); err != nil || before.RequireTwoFactor {
+		t.Errorf("before = %s", audit.Before)
+	}
+	if err := json.Unmarshal(audit.After, &after); err != nil || !after.RequireTwoFactor {
+		t.Errorf("after = %s", audit.After)
+	}
+}
+
+func TestRoleServiceAuditsAttachPermission(t *testing.T) {
+	roleRepo := newFakeRoleRepo("Dosen Wali")
+	role := roleRepo.roles[0]
+	read := models.Permission{ID: uuid.New(), Name: "achievements:read"}
+	verify := models.Permission{ID: uuid.New(), Name: "achievements:verify"}
+	roleRepo.permissions = []models.Permission{read, verify}
+	roleRepo.assigned[role.ID] = []uuid.UUID{read.ID}
+	app := newRoleServiceApp(roleRepo, uuid.New())
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/roles/"+role.ID.String()+"/permissions/"+verify.ID.String(), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	if len(roleRepo.audits) != 1 || roleRepo.audits[0] == nil {
+		t.Fatalf("audits = %v, want one entry passed to the repository", roleRepo.audits)
+	}
+	audit := roleRepo.audits[0]
+	if audit.Action != models.AuditActionRolePermissionAttach || audit.TargetType != models.AuditTargetRole {
+		t.Errorf("audit = %s %s", audit.Action, audit.TargetType)
+	}
+
+	var before, after struct {
+		Permissions []string `json:"permissions"`
+	}
+	_ = json.Unmarshal(audit.Before, &before)
+	_ = json.Unmarshal(audit.After, &after)
+	if !reflect.DeepEqual(before.Permissions, []string{"achievements:read"}) {
+		t.Errorf("before = %s", audit.Before)
+	}
+	if !reflect.DeepEqual(after.Permissions, []string{"achievements:read", "achievements:verify"}) {
+		t.Errorf("after = %s", audit.After)
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SessionService struct {
+	sessionRepo repository.SessionRepository
+}
+
+func NewSessionService(sessionRepo repository.SessionRepository) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+	}
+}
+
+// GetMySessions godoc
+// @Summary List my active sessions
+// @Description Daftar sesi login aktif milik user (perangkat, IP, waktu login dan terakhir aktif). Sesi yang dipakai request ini ditandai current=true.
+// @Tags Session
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/sessions [get]
+func (s *SessionService) GetMySessions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+	currentID, _ := c.Locals("session_id").(uuid.UUID)
+
+	sessions, err := s.sessionRepo.GetActiveByUser(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get sessions",
+			"details": err.Error(),
+		})
+	}
+	if sessions == nil {
+		sessions = []models.Session{}
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+
+	return c.JSON(fiber.Map{
+		"data": sessions,
+	})
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Logout paksa satu sesi milik user. Access token dan refresh token sesi itu langsung ditolak.
+// @Tags Session
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session UUID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/sessions/{id} [delete]
+func (s *SessionService) RevokeSession(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid session ID",
+		})
+	}
+
+	session, err := s.sessionRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get session",
+			"details": err.Error(),
+		})
+	}
+	// Sesi user lain diperlakukan sama dengan tidak ada
+	if session == nil || session.UserID != userID || !session.IsActive(time.Now()) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": models.ErrSessionNotFound.Error(),
+		})
+	}
+
+	if err := s.sessionRepo.Revoke(session.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to revoke session",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeOtherSessions godoc
+// @Summary Revoke all other sessions
+// @Description Logout dari semua perangkat lain, sesi yang dipakai request ini tetap aktif
+// @Tags Session
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} object{message=string,revoked=int}
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/sessions [delete]
+func (s *SessionService) RevokeOtherSessions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	var except *uuid.UUID
+	if currentID, ok := c.Locals("session_id").(uuid.UUID); ok {
+		except = &currentID
+	}
+
+	revoked, err := s.sessionRepo.RevokeAllByUser(userID, except)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to revoke sessions",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Other sessions revoked successfully",
+		"revoked": revoked,
+	})
+}
@@ -2,13 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strconv"
 
 	"achievement-backend/app/models"
 	"achievement-backend/app/repository"
 
-	"go.mongodb.org/mongo-driver/bson/primitive"
-
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
@@ -20,6 +20,8 @@ type StudentLecturerService struct {
 	roleRepo           repository.RoleRepository
 	achievementRepo    repository.AchievementRepository
 	achievementRefRepo repository.AchievementReferenceRepository
+	authz              *Authorizer
+	auditLogger        *AuditLogger
 }
 
 func NewStudentLecturerService(
@@ -29,6 +31,7 @@ func NewStudentLecturerService(
 	roleRepo	 repository.RoleRepository,
 	achievementRepo repository.AchievementRepository,
 	achievementRefRepo repository.AchievementReferenceRepository,
+	auditLogger *AuditLogger,
 ) *StudentLecturerService {
 	return &StudentLecturerService{
 		studentRepo:        studentRepo,
@@ -37,6 +40,8 @@ func NewStudentLecturerService(
 		achievementRepo:    achievementRepo,
 		achievementRefRepo: achievementRefRepo,
 		roleRepo: 			roleRepo,
+		authz:              NewAuthorizer(studentRepo, lecturerRepo),
+		auditLogger:        auditLogger,
 	}
 }
 
@@ -46,6 +51,7 @@ func NewStudentLecturerService(
 // Mengambil daftar mahasiswa
 // @Tags Student
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Produce json
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Limit per page (default: 10, max: 100)"
@@ -53,8 +59,10 @@ func NewStudentLecturerService(
 // @Param program_study query string false "Filter by program study"
 // @Param academic_year query string false "Filter by academic year"
 // @Param has_advisor query bool false "Filter students with/without advisor"
+// @Param cursor query string false "Mode cursor (tanpa search/filter): kosong untuk halaman pertama, lalu next_cursor / prev_cursor"
 //
 // @Success 200 {object} map[string]interface{} "List of students"
+// @Failure 400 {object} map[string]string "Invalid cursor"
 // @Failure 500 {object} map[string]string "Failed to get students"
 //
 // @Router /students [get]
@@ -74,11 +82,22 @@ func (s *StudentLecturerService) GetAllStudents(c *fiber.Ctx) error {
 		limit = 10
 	}
 
+	cursor, cursorMode, err := queryCursor(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid cursor"})
+	}
+	if cursorMode && (search != "" || programStudy != "" || academicYear != "" || hasAdvisor != "") {
+		return c.Status(400).JSON(fiber.Map{"error": "Cursor pagination does not support search or filters, use page instead"})
+	}
+
 	var students []models.StudentResponse
 	var total int
+	var cursorPage models.CursorPage
 	var errQuery error
 
-	if search != "" {
+	if cursorMode {
+		students, cursorPage, errQuery = s.studentRepo.GetWithUserDetailsAfter(cursor, limit)
+	} else if search != "" {
 		students, total, errQuery = s.studentRepo.SearchByName(search, page, limit)
 	} else if programStudy != "" {
 		rawStudents, count, err := s.studentRepo.GetByProgramStudy(programStudy, page, limit)
@@ -166,6 +185,9 @@ func (s *StudentLecturerService) GetAllStudents(c *fiber.Ctx) error {
 		students, total, errQuery = s.studentRepo.GetWithUserDetails(page, limit)
 	}
 
+	if errors.Is(errQuery, models.ErrInvalidCursor) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid cursor"})
+	}
 	if errQuery != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error":   "Failed to get students",
@@ -186,6 +208,14 @@ func (s *StudentLecturerService) GetAllStudents(c *fiber.Ctx) error {
 		}
 	}
 
+	if cursorMode {
+		return c.JSON(fiber.Map{
+			"success":    true,
+			"data":       students,
+			"pagination": cursorPagination(limit, cursorPage),
+		})
+	}
+
 	totalPages := (total + limit - 1) / limit
 	hasNext := page < totalPages
 	hasPrev := page > 1
@@ -210,6 +240,7 @@ func (s *StudentLecturerService) GetAllStudents(c *fiber.Ctx) error {
 // Mengambil detail mahasiswa berdasarkan ID.
 // @Tags Student
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Produce json
 //
 // @Param id path string true "Student UUID"
@@ -229,16 +260,9 @@ func (s *StudentLecturerService) GetStudentByID(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid student ID"})
 	}
 
-	// Get current user ID
-	userID, ok := c.Locals("user_id").(uuid.UUID)
-	if !ok {
-		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
-	}
-
-	// Get current user object
-	currentUser, ok := c.Locals("user").(*models.User)
-	if !ok {
-		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
 	// Get student data
@@ -250,28 +274,9 @@ func (s *StudentLecturerService) GetStudentByID(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Student not found"})
 	}
 
-	// 1. Cek apakah user adalah mahasiswa ini sendiri
-	if student.UserID == userID {
-		// Mahasiswa akses data sendiri - ALLOWED
-	} else {
-		// 2. Cek apakah user adalah dosen wali mahasiswa ini
-		lecturer, _ := s.lecturerRepo.GetByUserID(userID)
-		isDosenWali := lecturer != nil && student.AdvisorID != nil && *student.AdvisorID == lecturer.ID
-		
-		if !isDosenWali {
-			// 3. Cek apakah user adalah Admin
-			role, err := s.roleRepo.GetByID(currentUser.RoleID)
-			if err != nil || role == nil {
-				return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
-			}
-			
-			if role.Name != "Admin" {
-				// Bukan Admin, bukan dosen wali, bukan mahasiswa sendiri
-				return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
-			}
-			// Admin - ALLOWED
-		}
-		// Dosen wali akses mahasiswa bimbingannya - ALLOWED
+	// Mahasiswa itu sendiri, dosen walinya, atau pengelola user
+	if !s.authz.CanViewStudent(actor, student) {
+		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
 	}
 
 	// Get user details
@@ -317,6 +322,7 @@ func (s *StudentLecturerService) GetStudentByID(c *fiber.Ctx) error {
 // Mengambil daftar prestasi mahasiswa tertentu.
 // @Tags Student
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Produce json
 //
 // @Param id path string true "Student UUID"
@@ -340,16 +346,9 @@ func (s *StudentLecturerService) GetStudentAchievements(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid student ID"})
 	}
 
-	// Get current user ID
-	userID, ok := c.Locals("user_id").(uuid.UUID)
-	if !ok {
-		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
-	}
-
-	// Get current user object
-	currentUser, ok := c.Locals("user").(*models.User)
-	if !ok {
-		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
 	// Get student data
@@ -361,20 +360,10 @@ func (s *StudentLecturerService) GetStudentAchievements(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Student not found"})
 	}
 
-	if student.UserID != userID {
-		lecturer, _ := s.lecturerRepo.GetByUserID(userID)
-		isDosenWali := lecturer != nil && student.AdvisorID != nil && *student.AdvisorID == lecturer.ID
-		
-		if !isDosenWali {
-			role, err := s.roleRepo.GetByID(currentUser.RoleID)
-			if err != nil || role == nil {
-				return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
-			}
-			
-			if role.Name != "Admin" {
-				return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
-			}
-		}
+	// Pemilik, dosen wali, atau akses global prestasi
+	relations := s.authz.RelationsTo(actor, student, models.PermissionAchievementManage)
+	if !models.HasAnyRelation(models.AchievementActionRelations(models.ActionRead), relations) {
+		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
 	}
 
 	// Get query parameters
@@ -402,34 +391,25 @@ func (s *StudentLecturerService) GetStudentAchievements(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get achievement details
+	// Dokumen MongoDB dan nama verifikator dimuat sekali untuk satu halaman
+	pageData, err := loadAchievementPage(ctx, s.achievementRepo, s.studentRepo, s.userRepo, refs)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get achievement details",
+			"details": err.Error(),
+		})
+	}
+
 	var achievements []fiber.Map
 	for _, ref := range refs {
-		if ref.MongoAchievementID == "" {
-			continue
-		}
-
-		mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-		if err != nil {
-			continue
-		}
-
-		achievement, err := s.achievementRepo.FindByID(ctx, mongoID)
-		if err != nil || achievement == nil {
+		achievement := pageData.achievement(ref)
+		if achievement == nil {
 			continue
 		}
 
-		var verifiedByName string
-		if ref.VerifiedBy != nil {
-			verifiedUser, _ := s.userRepo.GetByID(*ref.VerifiedBy)
-			if verifiedUser != nil {
-				verifiedByName = verifiedUser.FullName
-			}
-		}
-
 		achievements = append(achievements, fiber.Map{
 			"id":           ref.ID,
-			"mongo_id":     mongoID.Hex(),
+			"mongo_id":     achievement.ID.Hex(),
 			"status":       ref.Status,
 			"title":        achievement.Title,
 			"type":         achievement.AchievementType,
@@ -438,7 +418,7 @@ func (s *StudentLecturerService) GetStudentAchievements(c *fiber.Ctx) error {
 			"verified_at":  ref.VerifiedAt,
 			"verified_by": fiber.Map{
 				"id":   ref.VerifiedBy,
-				"name": verifiedByName,
+				"name": pageData.userName(ref.VerifiedBy),
 			},
 			"rejection_note": ref.RejectionNote,
 			"created_at":     ref.CreatedAt,
@@ -492,6 +472,7 @@ func (s *StudentLecturerService) GetStudentAchievements(c *fiber.Ctx) error {
 //
 // @Tags Student
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Accept json
 // @Produce json
 //
@@ -572,6 +553,18 @@ func (s *StudentLecturerService) UpdateStudentAdvisor(c *fiber.Ctx) error {
 		}
 	}
 
+	updated, err := s.studentRepo.GetByID(studentID)
+	if err != nil {
+		fmt.Printf("Warning: Failed to load student %s for audit snapshot: %v\n", studentID, err)
+	}
+	s.auditLogger.Record(c, AuditEvent{
+		Action:     models.AuditActionStudentAdvisorUpdate,
+		TargetType: models.AuditTargetStudent,
+		TargetID:   studentID.String(),
+		Before:     student,
+		After:      updated,
+	})
+
 	// Get student user info for response
 	studentUser, _ := s.userRepo.GetByID(student.UserID)
 
@@ -610,13 +603,16 @@ func (s *StudentLecturerService) UpdateStudentAdvisor(c *fiber.Ctx) error {
 // - Menampilkan jumlah mahasiswa bimbingan
 // @Tags Lecturer
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Produce json
 // @Param page query int false "Page number"
 // @Param limit query int false "Limit per page"
 // @Param search query string false "Search by lecturer name"
 // @Param department query string false "Filter by department"
+// @Param cursor query string false "Mode cursor (tanpa search/filter): kosong untuk halaman pertama, lalu next_cursor / prev_cursor"
 //
 // @Success 200 {object} map[string]interface{} "List of lecturers"
+// @Failure 400 {object} map[string]string "Invalid cursor"
 // @Failure 500 {object} map[string]string "Failed to get lecturers"
 // @Router /lecturers [get]
 func (s *StudentLecturerService) GetAllLecturers(c *fiber.Ctx) error {
@@ -635,12 +631,23 @@ func (s *StudentLecturerService) GetAllLecturers(c *fiber.Ctx) error {
 
 	// NOTE: Permission sudah di-check di middleware
 
+	cursor, cursorMode, err := queryCursor(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid cursor"})
+	}
+	if cursorMode && (search != "" || department != "") {
+		return c.Status(400).JSON(fiber.Map{"error": "Cursor pagination does not support search or filters, use page instead"})
+	}
+
 	var lecturers []models.LecturerResponse
 	var total int
+	var cursorPage models.CursorPage
 	var errQuery error
 
 	// Apply filters
-	if search != "" {
+	if cursorMode {
+		lecturers, cursorPage, errQuery = s.lecturerRepo.GetWithUserDetailsAfter(cursor, limit)
+	} else if search != "" {
 		lecturers, total, errQuery = s.lecturerRepo.SearchByName(search, page, limit)
 	} else if department != "" {
 		rawLecturers, count, err := s.lecturerRepo.GetByDepartment(department, page, limit)
@@ -668,6 +675,9 @@ func (s *StudentLecturerService) GetAllLecturers(c *fiber.Ctx) error {
 		lecturers, total, errQuery = s.lecturerRepo.GetWithUserDetails(page, limit)
 	}
 
+	if errors.Is(errQuery, models.ErrInvalidCursor) {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid cursor"})
+	}
 	if errQuery != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error":   "Failed to get lecturers",
@@ -695,6 +705,14 @@ func (s *StudentLecturerService) GetAllLecturers(c *fiber.Ctx) error {
 		lecturersWithCount = append(lecturersWithCount, lecturerMap)
 	}
 
+	if cursorMode {
+		return c.JSON(fiber.Map{
+			"success":    true,
+			"data":       lecturersWithCount,
+			"pagination": cursorPagination(limit, cursorPage),
+		})
+	}
+
 	totalPages := (total + limit - 1) / limit
 	hasNext := page < totalPages
 	hasPrev := page > 1
@@ -725,6 +743,7 @@ func (s *StudentLecturerService) GetAllLecturers(c *fiber.Ctx) error {
 //
 // @Tags Lecturer
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Produce json
 //
 // @Param id path string true "Lecturer UUID"
@@ -747,10 +766,9 @@ func (s *StudentLecturerService) GetLecturerAdvisees(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid lecturer ID"})
 	}
 
-	// Get current user ID
-	userID, ok := c.Locals("user_id").(uuid.UUID)
-	if !ok {
-		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
+	actor, err := s.authz.Actor(c)
+	if err != nil {
+		return authorizationErrorResponse(c, err)
 	}
 
 	// Check if lecturer exists
@@ -765,20 +783,8 @@ func (s *StudentLecturerService) GetLecturerAdvisees(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Lecturer not found"})
 	}
 
-	// Check authorization
-	// Jika middleware RBAC sudah mengecek permission, cukup cek ownership
-	currentUser, ok := c.Locals("user").(*models.User)
-	if !ok {
-		return c.Status(401).JSON(fiber.Map{"error": "Unauthorized"})
-	}
-
-	role, err := s.roleRepo.GetByID(currentUser.RoleID)
-	if err != nil || role == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to verify user role"})
-	}
-
-	// Admin bisa akses semua, Dosen Wali hanya akses data sendiri
-	if role.Name == "Dosen Wali" && lecturer.UserID != userID {
+	// Dosen hanya akses data sendiri, pengelola user bisa akses semua
+	if !s.authz.CanViewAdvisees(actor, lecturer) {
 		return c.Status(403).JSON(fiber.Map{
 			"error":   "Access denied",
 			"details": "You can only access your own advisees",
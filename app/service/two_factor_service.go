@@ -0,0 +1,571 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RecoveryCodeCount jumlah recovery code yang dibuat setiap enrol / regenerate
+const RecoveryCodeCount = 10
+
+type TwoFactorService struct {
+	twoFactorRepo repository.TwoFactorRepository
+	userRepo      repository.UserRepository
+	roleRepo      repository.RoleRepository
+	auth          *AuthService
+	loginThrottle *LoginThrottle
+	issuer        string
+}
+
+func NewTwoFactorService(
+	twoFactorRepo repository.TwoFactorRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	auth *AuthService,
+	loginThrottle *LoginThrottle,
+	issuer string,
+) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		auth:          auth,
+		loginThrottle: loginThrottle,
+		issuer:        issuer,
+	}
+}
+
+// Status godoc
+// @Summary Two-factor status
+// @Description Status 2FA user yang sedang login
+// @Tags Two-Factor
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.TwoFactorStatusResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa [get]
+func (s *TwoFactorService) Status(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	status, err := s.status(user)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get two-factor status",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": status,
+	})
+}
+
+// Setup godoc
+// @Summary Start two-factor enrolment
+// @Description Membuat secret TOTP baru dan provisioning URI (otpauth://) untuk QR code. 2FA baru aktif setelah /auth/2fa/enable.
+// @Tags Two-Factor
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.TwoFactorSetupResponse
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa/setup [post]
+func (s *TwoFactorService) Setup(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	setup, err := s.beginSetup(user)
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to start two-factor setup")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": setup,
+	})
+}
+
+// Enable godoc
+// @Summary Confirm two-factor enrolment
+// @Description Mengaktifkan 2FA dengan kode TOTP pertama dan mengembalikan recovery code (hanya ditampilkan sekali)
+// @Tags Two-Factor
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa/enable [post]
+func (s *TwoFactorService) Enable(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	codes, err := s.confirmSetup(c, user, req.Code)
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to enable two-factor authentication")
+	}
+
+	return c.JSON(fiber.Map{
+		"message":       "Two-factor authentication enabled",
+		"recoveryCodes": codes,
+	})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Mematikan 2FA dengan kode TOTP atau recovery code. Ditolak jika role user mewajibkan 2FA.
+// @Tags Two-Factor
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa/disable [post]
+func (s *TwoFactorService) Disable(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	role, err := s.roleRepo.GetByID(user.RoleID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error getting role information",
+			"details": err.Error(),
+		})
+	}
+	if role != nil && role.RequireTwoFactor {
+		return twoFactorErrorResponse(c, models.ErrTwoFactorRequired, "")
+	}
+
+	if err := s.verify(c, user, req.Code, req.RecoveryCode); err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to verify two-factor code")
+	}
+
+	if err := s.twoFactorRepo.Disable(user.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to disable two-factor authentication",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Mengganti semua recovery code lama dengan yang baru (butuh kode TOTP)
+// @Tags Two-Factor
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa/recovery-codes [post]
+func (s *TwoFactorService) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Recovery code tidak boleh dipakai untuk membuat recovery code baru
+	if err := s.verify(c, user, req.Code, ""); err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to verify two-factor code")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate recovery codes",
+			"details": err.Error(),
+		})
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to save recovery codes",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"recoveryCodes": codes,
+	})
+}
+
+// Verify godoc
+// @Summary Complete login with two-factor code
+// @Description Menukar challenge token dari /auth/login dan kode TOTP (atau recovery code) dengan token akses
+// @Tags Two-Factor
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorChallengeRequest true "Challenge token and code"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa/verify [post]
+func (s *TwoFactorService) Verify(c *fiber.Ctx) error {
+	var req models.TwoFactorChallengeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, err := s.challengeUser(req.ChallengeToken, models.ChallengeTwoFactorVerify)
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to check user")
+	}
+
+	if err := s.verify(c, user, req.Code, req.RecoveryCode); err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to verify two-factor code")
+	}
+
+	return s.finishLogin(c, user, nil)
+}
+
+// Enroll godoc
+// @Summary Start mandatory two-factor enrolment during login
+// @Description Untuk role yang mewajibkan 2FA: challenge token (setupRequired) ditukar dengan secret TOTP baru
+// @Tags Two-Factor
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorChallengeRequest true "Challenge token"
+// @Success 200 {object} models.TwoFactorSetupResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa/enroll [post]
+func (s *TwoFactorService) Enroll(c *fiber.Ctx) error {
+	var req models.TwoFactorChallengeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, err := s.challengeUser(req.ChallengeToken, models.ChallengeTwoFactorEnroll)
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to check user")
+	}
+
+	setup, err := s.beginSetup(user)
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to start two-factor setup")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": setup,
+	})
+}
+
+// EnrollConfirm godoc
+// @Summary Confirm mandatory enrolment and complete login
+// @Description Mengaktifkan 2FA dengan kode TOTP pertama lalu menerbitkan token akses beserta recovery code
+// @Tags Two-Factor
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorChallengeRequest true "Challenge token and code"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/2fa/enroll/confirm [post]
+func (s *TwoFactorService) EnrollConfirm(c *fiber.Ctx) error {
+	var req models.TwoFactorChallengeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, err := s.challengeUser(req.ChallengeToken, models.ChallengeTwoFactorEnroll)
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to check user")
+	}
+
+	codes, err := s.confirmSetup(c, user, req.Code)
+	if err != nil {
+		return twoFactorErrorResponse(c, err, "Failed to enable two-factor authentication")
+	}
+
+	return s.finishLogin(c, user, codes)
+}
+
+var errInvalidChallenge = errors.New("invalid or expired challenge token")
+
+// challengeUser user pemilik challenge token; user nonaktif ditolak seperti di Login
+func (s *TwoFactorService) challengeUser(token, purpose string) (*models.User, error) {
+	if token == "" {
+		return nil, errInvalidChallenge
+	}
+	userID, err := utils.ValidateTwoFactorChallenge(token, purpose)
+	if err != nil {
+		return nil, errInvalidChallenge
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, errInvalidChallenge
+	}
+	return user, nil
+}
+
+func (s *TwoFactorService) finishLogin(c *fiber.Ctx, user *models.User, recoveryCodes []string) error {
+	role, err := s.roleRepo.GetByID(user.RoleID)
+	if err != nil || role == nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Error getting role information",
+		})
+	}
+
+	response, err := s.auth.completeLogin(c, user, role, user.Username)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate token",
+			"details": err.Error(),
+		})
+	}
+	response.RecoveryCodes = recoveryCodes
+
+	return c.JSON(response)
+}
+
+func (s *TwoFactorService) status(user *models.User) (*models.TwoFactorStatusResponse, error) {
+	status := &models.TwoFactorStatusResponse{}
+
+	role, err := s.roleRepo.GetByID(user.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	status.RequiredByRole = role != nil && role.RequireTwoFactor
+
+	totp, err := s.twoFactorRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if totp == nil || !totp.Enabled {
+		return status, nil
+	}
+
+	status.Enabled = true
+	status.ConfirmedAt = totp.ConfirmedAt
+	status.RecoveryCodesRemaining, err = s.twoFactorRepo.CountUnusedRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// beginSetup membuat secret baru; setup ulang sebelum konfirmasi mengganti secret lama
+func (s *TwoFactorService) beginSetup(user *models.User) (*models.TwoFactorSetupResponse, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.twoFactorRepo.SavePending(user.ID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, models.ErrTwoFactorAlreadyEnabled
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// confirmSetup mengaktifkan secret yang tertunda dan mengembalikan recovery code plaintext
+func (s *TwoFactorService) confirmSetup(c *fiber.Ctx, user *models.User, code string) ([]string, error) {
+	totp, err := s.twoFactorRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if totp == nil {
+		return nil, models.ErrTwoFactorNotEnabled
+	}
+	if totp.Enabled {
+		return nil, models.ErrTwoFactorAlreadyEnabled
+	}
+
+	var step int64
+	err = s.throttled(c, user, func() (bool, error) {
+		var ok bool
+		step, ok = utils.ValidateTOTP(totp.Secret, code, time.Now())
+		return ok, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.Enable(user.ID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verify mengecek kode TOTP (atau recovery code jika code kosong) untuk user yang 2FA-nya aktif
+func (s *TwoFactorService) verify(c *fiber.Ctx, user *models.User, code, recoveryCode string) error {
+	totp, err := s.twoFactorRepo.GetByUserID(user.ID)
+	if err != nil {
+		return err
+	}
+	if totp == nil || !totp.Enabled {
+		return models.ErrTwoFactorNotEnabled
+	}
+
+	return s.throttled(c, user, func() (bool, error) {
+		if code != "" {
+			step, ok := utils.ValidateTOTP(totp.Secret, code, time.Now())
+			if !ok {
+				return false, nil
+			}
+			// Kode yang sama tidak boleh dipakai dua kali dalam jendela waktunya
+			return s.twoFactorRepo.UseStep(user.ID, step)
+		}
+		if recoveryCode != "" {
+			return s.twoFactorRepo.UseRecoveryCode(user.ID, utils.HashRecoveryCode(recoveryCode))
+		}
+		return false, nil
+	})
+}
+
+// throttled menjalankan pengecekan kode di bawah batas percobaan login yang sama
+// dengan password, supaya kode 6 digit tidak bisa di-brute force
+func (s *TwoFactorService) throttled(c *fiber.Ctx, user *models.User, check func() (bool, error)) error {
+	block, err := s.loginThrottle.CheckAccount(user.ID)
+	if err != nil {
+		return err
+	}
+	if block != nil {
+		return block
+	}
+
+	ok, err := check()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err := s.loginThrottle.RecordFailure(user.Username, &user.ID, c.IP()); err != nil {
+			fmt.Printf("Warning: Failed to record two-factor failure for user %s: %v\n", user.ID, err)
+		}
+		return models.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
+
+// twoFactorErrorResponse memetakan error 2FA ke HTTP status
+func twoFactorErrorResponse(c *fiber.Ctx, err error, message string) error {
+	var block *loginBlock
+	switch {
+	case errors.As(err, &block):
+		return loginBlockedResponse(c, block)
+	case errors.Is(err, errInvalidChallenge),
+		errors.Is(err, models.ErrInvalidTwoFactorCode):
+		return c.Status(401).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, models.ErrTwoFactorNotEnabled):
+		return c.Status(400).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, models.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, models.ErrTwoFactorRequired):
+		return c.Status(409).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(500).JSON(fiber.Map{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -18,6 +18,10 @@ type UserService struct {
 	roleRepo     repository.RoleRepository
 	studentRepo  repository.StudentRepository
 	lecturerRepo repository.LecturerRepository
+	sessionRepo repository.SessionRepository
+	loginThrottle *LoginThrottle
+	passwordPolicy *PasswordPolicy
+	auditLogger *AuditLogger
 }
 
 func NewUserService(
@@ -25,12 +29,20 @@ func NewUserService(
 	roleRepo repository.RoleRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
+	sessionRepo repository.SessionRepository,
+	loginThrottle *LoginThrottle,
+	passwordPolicy *PasswordPolicy,
+	auditLogger *AuditLogger,
 ) *UserService {
 	return &UserService{
 		userRepo:     userRepo,
 		roleRepo:     roleRepo,
 		studentRepo:  studentRepo,
 		lecturerRepo: lecturerRepo,
+		sessionRepo: sessionRepo,
+		loginThrottle: loginThrottle,
+		passwordPolicy: passwordPolicy,
+		auditLogger: auditLogger,
 	}
 }
 
@@ -42,7 +54,9 @@ func NewUserService(
 // @Produce json
 // @Param page query int false "Page number"
 // @Param limit query int false "Limit per page (max 100)"
+// @Param cursor query string false "Mode cursor: kosong untuk halaman pertama, lalu next_cursor / prev_cursor"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /users [get]
 func (s *UserService) GetAll(c *fiber.Ctx) error {
@@ -57,6 +71,27 @@ func (s *UserService) GetAll(c *fiber.Ctx) error {
 		limit = 10
 	}
 
+	cursor, cursorMode, err := queryCursor(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid cursor"})
+	}
+	if cursorMode {
+		users, cursorPage, err := s.userRepo.GetAllAfter(cursor, limit)
+		if errors.Is(err, models.ErrInvalidCursor) {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid cursor"})
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error": "Failed to get users",
+				"details": err.Error(),
+			})
+		}
+		return c.JSON(fiber.Map{
+			"data":       users,
+			"pagination": cursorPagination(limit, cursorPage),
+		})
+	}
+
 	// Get active users with pagination
 	users, total, err := s.userRepo.GetAll(page, limit)
 	if err != nil {
@@ -216,6 +251,10 @@ func (s *UserService) Create(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := s.passwordPolicy.Validate(req.Password, nil); err != nil {
+		return passwordPolicyErrorResponse(c, err)
+	}
+
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -236,24 +275,30 @@ func (s *UserService) Create(c *fiber.Ctx) error {
 		UpdatedAt:    time.Now(),
 	}
 
-	userID, err := s.userRepo.Create(user)
+	student, lecturer, err := s.newUserProfile(user, &req, role.Name)
 	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Failed to create user profile",
+			"details": err.Error(), 
+		})
+	}
+
+	audit := s.auditLogger.Entry(c, AuditEvent{
+		Action:     models.AuditActionUserCreate,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID.String(),
+		After:      user,
+	})
+
+	if err := s.userRepo.CreateWithProfile(user, student, lecturer, audit); err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to create user",
 			"details": err.Error(),
 		})
 	}
-	user.ID = userID
 
-	if err := s.createUserProfile(user, &req, role.Name); err != nil {
-		if deleteErr := s.userRepo.HardDelete(user.ID); deleteErr != nil {
-			fmt.Printf("CRITICAL: Failed to rollback user creation: %v\n", deleteErr)
-		}
-		
-		return c.Status(400).JSON(fiber.Map{
-			"error": "Failed to create user profile",
-			"details": err.Error(), 
-		})
+	if err := s.passwordPolicy.Remember(user.ID, hashedPassword); err != nil {
+		fmt.Printf("Warning: Failed to record password history for user %s: %v\n", user.ID, err)
 	}
 
 	createdUser, err := s.userRepo.GetByID(user.ID)
@@ -270,14 +315,24 @@ func (s *UserService) Create(c *fiber.Ctx) error {
 	})
 }
 
-func (s *UserService) createUserProfile(user *models.User, req *models.CreateUserRequest, roleName string) error {
+// newUserProfile menyiapkan profil mahasiswa / dosen sesuai role; keduanya nil untuk role lain.
+// Profil disimpan bersama user oleh CreateWithProfile.
+func (s *UserService) newUserProfile(user *models.User, req *models.CreateUserRequest, roleName string) (*models.Student, *models.Lecturer, error) {
 	switch roleName {
 	case "Mahasiswa":
 		if req.StudentID == nil || req.ProgramStudy == nil || req.AcademicYear == nil {
-			return errors.New("student data incomplete: need studentId, programStudy, and academicYear")
+			return nil, nil, errors.New("student data incomplete: need studentId, programStudy, and academicYear")
+		}
+
+		existing, err := s.studentRepo.GetByStudentID(*req.StudentID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error checking student ID: %w", err)
+		}
+		if existing != nil {
+			return nil, nil, fmt.Errorf("student ID %s already exists", *req.StudentID)
 		}
 
-		student := models.Student{
+		student := &models.Student{
 			ID:           uuid.New(),
 			UserID:       user.ID,
 			StudentID:    *req.StudentID,
@@ -290,29 +345,36 @@ func (s *UserService) createUserProfile(user *models.User, req *models.CreateUse
 		if req.AdvisorID != nil && *req.AdvisorID != "" {
 			advisorID, err := uuid.Parse(*req.AdvisorID)
 			if err != nil {
-					return fmt.Errorf("invalid advisor ID format: %w", err)
+				return nil, nil, fmt.Errorf("invalid advisor ID format: %w", err)
 			}
-			
+
 			advisor, err := s.lecturerRepo.GetByID(advisorID)
 			if err != nil {
-					return fmt.Errorf("error checking advisor: %w", err)
+				return nil, nil, fmt.Errorf("error checking advisor: %w", err)
 			}
 			if advisor == nil {
-					return fmt.Errorf("advisor not found with ID: %s", advisorID)
+				return nil, nil, fmt.Errorf("advisor not found with ID: %s", advisorID)
 			}
-			
+
 			student.AdvisorID = &advisorID
-	}
+		}
 
-		_, err := s.studentRepo.Create(student)
-		return err
+		return student, nil, nil
 
 	case "Dosen Wali":
 		if req.LecturerID == nil || req.Department == nil {
-			return errors.New("lecturer data incomplete: need lecturerId and department")
+			return nil, nil, errors.New("lecturer data incomplete: need lecturerId and department")
 		}
 
-		lecturer := models.Lecturer{
+		existing, err := s.lecturerRepo.GetByLecturerID(*req.LecturerID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error checking lecturer ID: %w", err)
+		}
+		if existing != nil {
+			return nil, nil, fmt.Errorf("lecturer ID %s already exists", *req.LecturerID)
+		}
+
+		lecturer := &models.Lecturer{
 			ID:         uuid.New(),
 			UserID:     user.ID,
 			LecturerID: *req.LecturerID,
@@ -320,11 +382,10 @@ func (s *UserService) createUserProfile(user *models.User, req *models.CreateUse
 			CreatedAt:  time.Now(),
 		}
 
-		_, err := s.lecturerRepo.Create(lecturer)
-		return err
+		return nil, lecturer, nil
 	}
 
-	return nil
+	return nil, nil, nil
 }
 
 // Update godoc
@@ -384,6 +445,10 @@ func (s *UserService) Update(c *fiber.Ctx) error {
 
 	// Update password if provided
 	if req.Password != nil {
+		if err := s.passwordPolicy.Validate(*req.Password, user); err != nil {
+			return passwordPolicyErrorResponse(c, err)
+		}
+
 		hashedPassword, err := utils.HashPassword(*req.Password)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
@@ -392,16 +457,35 @@ func (s *UserService) Update(c *fiber.Ctx) error {
 			})
 		}
 		
-		if err := s.userRepo.UpdatePassword(id, hashedPassword); err != nil {
+		// Password tidak masuk snapshot, jadi penggantiannya dicatat sebagai aksi terpisah
+		audit := s.auditLogger.Entry(c, AuditEvent{
+			Action:     models.AuditActionUserPasswordSet,
+			TargetType: models.AuditTargetUser,
+			TargetID:   id.String(),
+		})
+
+		if err := s.userRepo.UpdatePasswordWithAudit(id, hashedPassword, audit); err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error": "Failed to update password",
 				"details": err.Error(),
 			})
 		}
+
+		if err := s.passwordPolicy.Remember(id, hashedPassword); err != nil {
+			fmt.Printf("Warning: Failed to record password history for user %s: %v\n", id, err)
+		}
 	}
 
 	// Update other user data
-	if err := s.userRepo.Update(id, &req); err != nil {
+	audit := s.auditLogger.Entry(c, AuditEvent{
+		Action:     models.AuditActionUserUpdate,
+		TargetType: models.AuditTargetUser,
+		TargetID:   id.String(),
+		Before:     user,
+		After:      updatedUser(user, &req),
+	})
+
+	if err := s.userRepo.UpdateWithAudit(id, &req, audit); err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update user",
 			"details": err.Error(),
@@ -448,19 +532,147 @@ func (s *UserService) Delete(c *fiber.Ctx) error {
 		})
 	}
 
+	deleted := *user
+	deleted.IsActive = false
+	audit := s.auditLogger.Entry(c, AuditEvent{
+		Action:     models.AuditActionUserDelete,
+		TargetType: models.AuditTargetUser,
+		TargetID:   id.String(),
+		Before:     user,
+		After:      deleted,
+	})
+
 	// Soft delete user
-	if err := s.userRepo.SoftDelete(id); err != nil {
+	if err := s.userRepo.SoftDeleteWithAudit(id, audit); err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to delete user",
 			"details": err.Error(),
 		})
 	}
 
+	// User nonaktif tidak boleh refresh token lagi
+	if _, err := s.sessionRepo.RevokeAllByUser(id, nil); err != nil {
+		fmt.Printf("Warning: Failed to revoke refresh tokens for user %s: %v\n", id, err)
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "User deleted successfully (soft delete)",
 	})
 }
 
+// RevokeSessions godoc
+// @Summary Revoke all sessions of a user
+// @Description Admin me-revoke semua refresh token milik user (paksa logout di semua device)
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User UUID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/sessions [delete]
+func (s *UserService) RevokeSessions(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to check user",
+			"details": err.Error(),
+		})
+	}
+	if user == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	revoked, err := s.sessionRepo.RevokeAllByUser(id, nil)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to revoke sessions",
+			"details": err.Error(),
+		})
+	}
+
+	s.auditLogger.Record(c, AuditEvent{
+		Action:     models.AuditActionUserSessionsRevoke,
+		TargetType: models.AuditTargetUser,
+		TargetID:   id.String(),
+		After:      fiber.Map{"revoked": revoked},
+	})
+
+	return c.JSON(fiber.Map{
+		"message": "User sessions revoked successfully",
+		"revoked": revoked,
+	})
+}
+
+// UnlockAccount godoc
+// @Summary Unlock user account
+// @Description Admin membuka kunci akun yang terkunci karena terlalu banyak login gagal
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User UUID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/unlock [post]
+func (s *UserService) UnlockAccount(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	actorID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to check user",
+			"details": err.Error(),
+		})
+	}
+	if user == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	if err := s.loginThrottle.Unlock(id, actorID, c.IP()); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to unlock account",
+			"details": err.Error(),
+		})
+	}
+
+	s.auditLogger.Record(c, AuditEvent{
+		Action:     models.AuditActionUserUnlock,
+		TargetType: models.AuditTargetUser,
+		TargetID:   id.String(),
+	})
+
+	return c.JSON(fiber.Map{
+		"message": "Account unlocked successfully",
+	})
+}
+
 // UpdateRole godoc
 // @Summary Update user role
 // @Description Mengubah role user
@@ -534,7 +746,15 @@ func (s *UserService) UpdateRole(c *fiber.Ctx) error {
 		RoleID: &roleIDStr,
 	}
 	
-	if err := s.userRepo.Update(id, updateReq); err != nil {
+	audit := s.auditLogger.Entry(c, AuditEvent{
+		Action:     models.AuditActionUserRoleUpdate,
+		TargetType: models.AuditTargetUser,
+		TargetID:   id.String(),
+		Before:     user,
+		After:      updatedUser(user, updateReq),
+	})
+
+	if err := s.userRepo.UpdateWithAudit(id, updateReq, audit); err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to update role",
 			"details": err.Error(),
@@ -546,6 +766,28 @@ func (s *UserService) UpdateRole(c *fiber.Ctx) error {
 	})
 }
 
+// updatedUser snapshot audit "after": user dengan field dari request diterapkan, sama seperti
+// UPDATE yang dijalankan repository
+func updatedUser(user *models.User, req *models.UpdateUserRequest) models.User {
+	updated := *user
+	if req.FullName != nil {
+		updated.FullName = *req.FullName
+	}
+	if req.Email != nil {
+		updated.Email = *req.Email
+	}
+	if req.IsActive != nil {
+		updated.IsActive = *req.IsActive
+	}
+	if req.RoleID != nil {
+		if roleID, err := uuid.Parse(*req.RoleID); err == nil {
+			updated.RoleID = roleID
+		}
+	}
+	updated.UpdatedAt = time.Now()
+	return updated
+}
+
 func (s *UserService) SearchByName(c *fiber.Ctx) error {
 	name := c.Query("name", "")
 	if name == "" {
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"strings"
+)
+
+// JWTConfig konfigurasi kunci penandatangan JWT, dibaca dari env:
+//
+//	JWT_ALGORITHM          HS256 | RS256 | EdDSA (default EdDSA)
+//	JWT_KEY_ID             kid untuk kunci aktif (default: diturunkan dari public key)
+//	JWT_PRIVATE_KEY_FILE   path PEM private key (RS256 / EdDSA), wajib kecuali JWT_EPHEMERAL_KEY
+//	JWT_EPHEMERAL_KEY      true: tanpa file kunci buat kunci sementara (hanya development)
+//	JWT_SECRET             shared secret (hanya HS256)
+//	JWT_VERIFICATION_KEYS  kunci lama yang masih diterima saat rotasi, format "kid=path.pem,kid2=path2.pem"
+type JWTConfig struct {
+	Algorithm        string
+	KeyID            string
+	PrivateKeyFile   string
+	EphemeralKey     bool
+	Secret           string
+	VerificationKeys map[string]string
+}
+
+func LoadJWTConfig() JWTConfig {
+	return JWTConfig{
+		Algorithm:        GetEnv("JWT_ALGORITHM", "EdDSA"),
+		KeyID:            GetEnv("JWT_KEY_ID", ""),
+		PrivateKeyFile:   GetEnv("JWT_PRIVATE_KEY_FILE", ""),
+		EphemeralKey:     getEnvBool("JWT_EPHEMERAL_KEY", false),
+		Secret:           GetEnv("JWT_SECRET", ""),
+		VerificationKeys: parseKeyList(GetEnv("JWT_VERIFICATION_KEYS", "")),
+	}
+}
+
+// parseKeyList mengubah "kid=path,kid2=path2" menjadi map kid -> path
+func parseKeyList(raw string) map[string]string {
+	keys := make(map[string]string)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, path, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		keys[strings.TrimSpace(kid)] = strings.TrimSpace(path)
+	}
+	return keys
+}
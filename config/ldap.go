@@ -0,0 +1,75 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// AuthProvidersConfig urutan backend pemeriksa password di /auth/login:
+//
+//	AUTH_PROVIDERS   dipisah koma, dicoba berurutan sampai ada yang berhasil: local, ldap (default local)
+type AuthProvidersConfig struct {
+	Providers []string
+}
+
+func LoadAuthProvidersConfig() AuthProvidersConfig {
+	providers := splitList(GetEnv("AUTH_PROVIDERS", "local"))
+	if len(providers) == 0 {
+		providers = []string{"local"}
+	}
+	return AuthProvidersConfig{Providers: providers}
+}
+
+// LDAPConfig login dengan bind ke LDAP / Active Directory, dibaca dari env:
+//
+//	LDAP_URL                    ldap://host:389 atau ldaps://host:636 (default ldap://localhost:389)
+//	LDAP_START_TLS              upgrade koneksi ldap:// dengan StartTLS (default false)
+//	LDAP_INSECURE_SKIP_VERIFY   lewati verifikasi sertifikat, hanya untuk development (default false)
+//	LDAP_USER_DN_TEMPLATES      template DN dipisah ';', %s diganti username (sudah di-escape), dicoba berurutan.
+//	                            mis. uid=%s,ou=lecturers,dc=kampus,dc=ac,dc=id;%s@kampus.ac.id (UPN Active Directory)
+//	LDAP_BASE_DN                base pencarian atribut user dengan LDAP_USER_FILTER; kosong = baca entry DN hasil bind
+//	LDAP_USER_FILTER            filter pencarian, %s = username (default (uid=%s), AD: (sAMAccountName=%s))
+//	LDAP_ATTR_FULL_NAME         atribut nama lengkap (default cn)
+//	LDAP_ATTR_EMAIL             atribut email (default mail)
+//	LDAP_ATTR_LECTURER_ID       atribut NIP untuk profil dosen akun baru (default employeeNumber)
+//	LDAP_ATTR_DEPARTMENT        atribut departemen untuk profil dosen akun baru (default departmentNumber)
+//	LDAP_AUTO_PROVISION         buat akun Dosen Wali untuk user LDAP yang belum terdaftar (default false)
+//	LDAP_TIMEOUT                timeout koneksi dan operasi (default 5s)
+type LDAPConfig struct {
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	UserDNTemplates    []string
+	BaseDN             string
+	UserFilter         string
+	AttrFullName       string
+	AttrEmail          string
+	AttrLecturerID     string
+	AttrDepartment     string
+	AutoProvision      bool
+	Timeout            time.Duration
+}
+
+func LoadLDAPConfig() LDAPConfig {
+	var templates []string
+	for _, template := range strings.Split(GetEnv("LDAP_USER_DN_TEMPLATES", ""), ";") {
+		if template = strings.TrimSpace(template); template != "" {
+			templates = append(templates, template)
+		}
+	}
+
+	return LDAPConfig{
+		URL:                GetEnv("LDAP_URL", "ldap://localhost:389"),
+		StartTLS:           getEnvBool("LDAP_START_TLS", false),
+		InsecureSkipVerify: getEnvBool("LDAP_INSECURE_SKIP_VERIFY", false),
+		UserDNTemplates:    templates,
+		BaseDN:             GetEnv("LDAP_BASE_DN", ""),
+		UserFilter:         GetEnv("LDAP_USER_FILTER", "(uid=%s)"),
+		AttrFullName:       GetEnv("LDAP_ATTR_FULL_NAME", "cn"),
+		AttrEmail:          GetEnv("LDAP_ATTR_EMAIL", "mail"),
+		AttrLecturerID:     GetEnv("LDAP_ATTR_LECTURER_ID", "employeeNumber"),
+		AttrDepartment:     GetEnv("LDAP_ATTR_DEPARTMENT", "departmentNumber"),
+		AutoProvision:      getEnvBool("LDAP_AUTO_PROVISION", false),
+		Timeout:            getEnvDuration("LDAP_TIMEOUT", 5*time.Second),
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import "time"
+
+// MailConfig pengiriman email, dibaca dari env:
+//
+//	MAIL_DRIVER          smtp | log (default log: email hanya dicetak ke stdout)
+//	MAIL_HOST            host SMTP (default localhost, cocok untuk MailHog / Mailpit)
+//	MAIL_PORT            port SMTP (default 1025)
+//	MAIL_USERNAME        user SMTP (kosong = tanpa AUTH)
+//	MAIL_PASSWORD        password SMTP
+//	MAIL_FROM            alamat pengirim
+type MailConfig struct {
+	Driver   string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func LoadMailConfig() MailConfig {
+	return MailConfig{
+		Driver:   GetEnv("MAIL_DRIVER", "log"),
+		Host:     GetEnv("MAIL_HOST", "localhost"),
+		Port:     GetEnv("MAIL_PORT", "1025"),
+		Username: GetEnv("MAIL_USERNAME", ""),
+		Password: GetEnv("MAIL_PASSWORD", ""),
+		From:     GetEnv("MAIL_FROM", "no-reply@achievement.local"),
+	}
+}
+
+// PasswordResetConfig token reset password:
+//
+//	PASSWORD_RESET_URL   URL halaman reset di frontend, token ditambahkan sebagai query ?token=
+//	PASSWORD_RESET_TTL   masa berlaku token (default 30m)
+type PasswordResetConfig struct {
+	URL string
+	TTL time.Duration
+}
+
+func LoadPasswordResetConfig() PasswordResetConfig {
+	return PasswordResetConfig{
+		URL: GetEnv("PASSWORD_RESET_URL", "http://localhost:5173/reset-password"),
+		TTL: getEnvDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+	}
+}
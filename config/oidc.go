@@ -0,0 +1,64 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// OIDCConfig login SSO lewat identity provider kampus (authorization code + PKCE), dibaca dari env:
+//
+//	OIDC_ISSUER_URL                issuer IdP; discovery di <issuer>/.well-known/openid-configuration.
+//	                               Kosong = SSO nonaktif. Boleh http untuk mock IdP lokal
+//	                               (mis. mock-oauth2-server: http://localhost:8080/default)
+//	OIDC_CLIENT_ID                 client id aplikasi di IdP
+//	OIDC_CLIENT_SECRET             client secret (kosong untuk public client, cukup PKCE)
+//	OIDC_REDIRECT_URL              URL callback yang didaftarkan di IdP (.../exam/api/auth/oidc/callback)
+//	OIDC_SCOPES                    scope dipisah spasi (default "openid email profile")
+//	OIDC_AUTO_PROVISION_DOMAINS    domain email dipisah koma yang otomatis dibuatkan akun Mahasiswa
+//	                               (kosong = hanya user yang sudah terdaftar)
+//	OIDC_STUDENT_ID_CLAIM          claim NIM untuk akun baru (default student_id, fallback bagian lokal email)
+//	OIDC_PROGRAM_STUDY_CLAIM       claim program studi untuk akun baru (default program_study)
+//	OIDC_ACADEMIC_YEAR_CLAIM       claim angkatan untuk akun baru (default academic_year)
+//	OIDC_STATE_TTL                 batas waktu menyelesaikan login di IdP (default 10m)
+type OIDCConfig struct {
+	IssuerURL            string
+	ClientID             string
+	ClientSecret         string
+	RedirectURL          string
+	Scopes               []string
+	AutoProvisionDomains []string
+	StudentIDClaim       string
+	ProgramStudyClaim    string
+	AcademicYearClaim    string
+	StateTTL             time.Duration
+}
+
+func LoadOIDCConfig() OIDCConfig {
+	return OIDCConfig{
+		IssuerURL:            strings.TrimSuffix(GetEnv("OIDC_ISSUER_URL", ""), "/"),
+		ClientID:             GetEnv("OIDC_CLIENT_ID", ""),
+		ClientSecret:         GetEnv("OIDC_CLIENT_SECRET", ""),
+		RedirectURL:          GetEnv("OIDC_REDIRECT_URL", "http://localhost:3000/exam/api/auth/oidc/callback"),
+		Scopes:               strings.Fields(GetEnv("OIDC_SCOPES", "openid email profile")),
+		AutoProvisionDomains: splitList(GetEnv("OIDC_AUTO_PROVISION_DOMAINS", "")),
+		StudentIDClaim:       GetEnv("OIDC_STUDENT_ID_CLAIM", "student_id"),
+		ProgramStudyClaim:    GetEnv("OIDC_PROGRAM_STUDY_CLAIM", "program_study"),
+		AcademicYearClaim:    GetEnv("OIDC_ACADEMIC_YEAR_CLAIM", "academic_year"),
+		StateTTL:             getEnvDuration("OIDC_STATE_TTL", 10*time.Minute),
+	}
+}
+
+// Enabled true jika issuer dan client id sudah diisi
+func (c OIDCConfig) Enabled() bool {
+	return c.IssuerURL != "" && c.ClientID != ""
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,31 @@
+package config
+
+import "time"
+
+// AchievementSyncConfig penyelesaian outbox Postgres → Mongo, dibaca dari env:
+//
+//	OUTBOX_POLL_INTERVAL   jeda antar putaran worker (default 10s)
+//	OUTBOX_BATCH_SIZE      jumlah baris yang diklaim per putaran (default 50)
+//	OUTBOX_MAX_ATTEMPTS    percobaan sebelum create dikompensasi / update & delete ditandai failed (default 5)
+//	OUTBOX_RETRY_BASE      jeda retry awal, berlipat dua tiap percobaan (default 5s)
+//	OUTBOX_RETRY_MAX       jeda retry maksimum (default 5m)
+//	OUTBOX_LEASE           lama baris dikunci request / worker yang sedang memprosesnya (default 1m)
+type AchievementSyncConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	RetryBase    time.Duration
+	RetryMax     time.Duration
+	Lease        time.Duration
+}
+
+func LoadAchievementSyncConfig() AchievementSyncConfig {
+	return AchievementSyncConfig{
+		PollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", 10*time.Second),
+		BatchSize:    getEnvInt("OUTBOX_BATCH_SIZE", 50),
+		MaxAttempts:  getEnvInt("OUTBOX_MAX_ATTEMPTS", 5),
+		RetryBase:    getEnvDuration("OUTBOX_RETRY_BASE", 5*time.Second),
+		RetryMax:     getEnvDuration("OUTBOX_RETRY_MAX", 5*time.Minute),
+		Lease:        getEnvDuration("OUTBOX_LEASE", time.Minute),
+	}
+}
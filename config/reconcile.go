@@ -0,0 +1,31 @@
+package config
+
+import "time"
+
+// ReconcileConfig rekonsiliasi achievement_references ↔ koleksi achievements, dibaca dari env:
+//
+//	RECONCILE_INTERVAL       jeda antar run terjadwal, 0 = job terjadwal nonaktif (default 24h)
+//	RECONCILE_REPAIR         job terjadwal ikut menyamakan studentId dokumen Mongo (default false)
+//	RECONCILE_QUARANTINE     job terjadwal ikut mengkarantina record tanpa pasangan (default false)
+//	RECONCILE_GRACE_PERIOD   record yang lebih muda dilewati karena mungkin masih diproses outbox (default 1h)
+//	RECONCILE_BATCH_SIZE     jumlah record per batch saat memindai kedua store (default 500)
+//	RECONCILE_MAX_ISSUES     issue yang disimpan per laporan, hitungan per jenis tetap lengkap (default 1000)
+type ReconcileConfig struct {
+	Interval    time.Duration
+	Repair      bool
+	Quarantine  bool
+	GracePeriod time.Duration
+	BatchSize   int
+	MaxIssues   int
+}
+
+func LoadReconcileConfig() ReconcileConfig {
+	return ReconcileConfig{
+		Interval:    getEnvDuration("RECONCILE_INTERVAL", 24*time.Hour),
+		Repair:      getEnvBool("RECONCILE_REPAIR", false),
+		Quarantine:  getEnvBool("RECONCILE_QUARANTINE", false),
+		GracePeriod: getEnvDuration("RECONCILE_GRACE_PERIOD", time.Hour),
+		BatchSize:   getEnvInt("RECONCILE_BATCH_SIZE", 500),
+		MaxIssues:   getEnvInt("RECONCILE_MAX_ISSUES", 1000),
+	}
+}
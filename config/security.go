@@ -0,0 +1,102 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+// LoginThrottleConfig batas percobaan login, dibaca dari env:
+//
+//	LOGIN_MAX_FAILURES        gagal berturut-turut per akun (jarak antar gagal < LOGIN_FAILURE_WINDOW) sebelum dikunci (default 5)
+//	LOGIN_LOCKOUT_DURATION    lama akun dikunci (default 15m)
+//	LOGIN_IP_MAX_FAILURES     gagal per IP dalam LOGIN_FAILURE_WINDOW sebelum IP diblokir (default 20)
+//	LOGIN_FAILURE_WINDOW      jendela waktu hitung gagal per IP dan per akun (default 15m)
+//	LOGIN_DELAY_AFTER         jumlah gagal sebelum jeda progresif mulai berlaku (default 2)
+//	LOGIN_DELAY_BASE          jeda awal, berlipat dua tiap kegagalan berikutnya (default 1s)
+//	LOGIN_DELAY_MAX           jeda maksimum (default 1m)
+type LoginThrottleConfig struct {
+	MaxFailures     int
+	LockoutDuration time.Duration
+	IPMaxFailures   int
+	FailureWindow   time.Duration
+	DelayAfter      int
+	DelayBase       time.Duration
+	DelayMax        time.Duration
+}
+
+func LoadLoginThrottleConfig() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		MaxFailures:     getEnvInt("LOGIN_MAX_FAILURES", 5),
+		LockoutDuration: getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		IPMaxFailures:   getEnvInt("LOGIN_IP_MAX_FAILURES", 20),
+		FailureWindow:   getEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		DelayAfter:      getEnvInt("LOGIN_DELAY_AFTER", 2),
+		DelayBase:       getEnvDuration("LOGIN_DELAY_BASE", time.Second),
+		DelayMax:        getEnvDuration("LOGIN_DELAY_MAX", time.Minute),
+	}
+}
+
+func getEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(GetEnv(key, "")); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(GetEnv(key, "")); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// PasswordPolicyConfig aturan password baru, dibaca dari env:
+//
+//	PASSWORD_MIN_LENGTH       panjang minimum (default 8)
+//	PASSWORD_REQUIRE_UPPER    wajib huruf besar (default true)
+//	PASSWORD_REQUIRE_LOWER    wajib huruf kecil (default true)
+//	PASSWORD_REQUIRE_DIGIT    wajib angka (default true)
+//	PASSWORD_REQUIRE_SYMBOL   wajib simbol (default false)
+//	PASSWORD_BLOCK_COMMON     tolak password yang ada di daftar password umum (default true)
+//	PASSWORD_HISTORY          jumlah password terakhir yang tidak boleh dipakai ulang, 0 = nonaktif (default 3)
+type PasswordPolicyConfig struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	BlockCommon   bool
+	HistorySize   int
+}
+
+func LoadPasswordPolicyConfig() PasswordPolicyConfig {
+	return PasswordPolicyConfig{
+		MinLength:     getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		RequireUpper:  getEnvBool("PASSWORD_REQUIRE_UPPER", true),
+		RequireLower:  getEnvBool("PASSWORD_REQUIRE_LOWER", true),
+		RequireDigit:  getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol: getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		BlockCommon:   getEnvBool("PASSWORD_BLOCK_COMMON", true),
+		HistorySize:   getEnvInt("PASSWORD_HISTORY", 3),
+	}
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if value, err := strconv.ParseBool(GetEnv(key, "")); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// ImpersonationConfig admin login sebagai user lain untuk membantu troubleshooting:
+//
+//	IMPERSONATION_TTL   masa berlaku token impersonation, tidak bisa di-refresh (default 15m)
+type ImpersonationConfig struct {
+	TTL time.Duration
+}
+
+func LoadImpersonationConfig() ImpersonationConfig {
+	return ImpersonationConfig{
+		TTL: getEnvDuration("IMPERSONATION_TTL", 15*time.Minute),
+	}
+}
@@ -1,29 +1,450 @@
 package database
 
 import (
-    "database/sql"
-    "log"
-    "os"
-    "path/filepath"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Penanda bagian di file migrasi. Bagian sebelum "Down" adalah up; file tanpa
+// penanda Down tidak bisa di-rollback.
+const (
+	migrateUpMarker   = "-- +migrate Up"
+	migrateDownMarker = "-- +migrate Down"
 )
 
-func Migrate(db *sql.DB, path string) {
-    files, err := filepath.Glob(path + "/*.sql")
-    if err != nil {
-        log.Fatal(err)
-    }
-
-    for _, file := range files {
-        sqlBytes, err := os.ReadFile(file) 
-        if err != nil {
-            log.Fatalf("Failed to read %s: %v", file, err)
-        }
-
-        _, err = db.Exec(string(sqlBytes))
-        if err != nil {
-            log.Fatalf("Failed to execute %s: %v", file, err)
-        }
-
-        log.Printf("Migrated: %s", file)
-    }
+// migrationLockID kunci advisory Postgres supaya dua proses migrate tidak berjalan bersamaan
+const migrationLockID = 725104331
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-zA-Z0-9_]+)\.sql$`)
+
+var ErrMigrationChanged = errors.New("applied migration has been modified")
+
+// Migration satu file NNN_nama.sql di direktori migrasi
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus status satu migrasi dibanding isi schema_migrations
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Modified: sudah dijalankan tapi isi up-nya berubah sejak itu
+	Modified bool
+	// Missing: tercatat di schema_migrations tapi file-nya sudah tidak ada
+	Missing bool
+}
+
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator menjalankan migrasi berversi; versi yang sudah dijalankan beserta
+// checksum-nya dicatat di schema_migrations dan tiap migrasi berjalan dalam satu transaksi.
+type Migrator struct {
+	db  *sql.DB
+	dir string
+}
+
+func NewMigrator(db *sql.DB, dir string) *Migrator {
+	return &Migrator{db: db, dir: dir}
+}
+
+// RunMigrations menjalankan perintah CLI -migrate: up (default), down [N], status, redo.
+// up dan status juga mencakup migrasi Mongo; down dan redo hanya untuk migrasi SQL.
+func RunMigrations(db *sql.DB, mongoDB *mongo.Database, dir string, args []string) error {
+	m := NewMigrator(db, dir)
+	ctx := context.Background()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := m.Up()
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied %d migration(s)", applied)
+
+		mongoApplied, err := MongoMigrateUp(ctx, mongoDB)
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied %d mongo migration(s)", mongoApplied)
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := m.Down(steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("Reverted %d migration(s)", reverted)
+		return nil
+
+	case "redo":
+		return m.Redo()
+
+	case "status":
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		log.Println("PostgreSQL:")
+		logMigrationStatus(statuses)
+
+		mongoStatuses, err := MongoMigrationStatus(ctx, mongoDB)
+		if err != nil {
+			return err
+		}
+		log.Println("MongoDB:")
+		logMigrationStatus(mongoStatuses)
+		return nil
+	}
+
+	return fmt.Errorf("unknown migrate command %q (use up, down [N], status or redo)", command)
+}
+
+func logMigrationStatus(statuses []MigrationStatus) {
+	for _, s := range statuses {
+		state := "pending"
+		switch {
+		case s.Missing:
+			state = "applied, file missing"
+		case s.Modified:
+			state = "applied, MODIFIED"
+		case s.AppliedAt != nil:
+			state = "applied " + s.AppliedAt.Format(time.RFC3339)
+		}
+		log.Printf("%03d %-45s %s", s.Version, s.Name, state)
+	}
+}
+
+// Load membaca dan mengurutkan semua file migrasi di direktori
+func (m *Migrator) Load() ([]Migration, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	seen := map[int64]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s (expected NNN_name.sql)", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		content, err := os.ReadFile(filepath.Join(m.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", entry.Name(), err)
+		}
+		up, down := splitMigration(string(content))
+		sum := sha256.Sum256([]byte(up))
+
+		migrations = append(migrations, Migration{
+			Version:  version,
+			Name:     match[2],
+			Up:       up,
+			Down:     down,
+			Checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func splitMigration(content string) (up, down string) {
+	up = content
+	if i := strings.Index(content, migrateDownMarker); i >= 0 {
+		up = content[:i]
+		down = content[i+len(migrateDownMarker):]
+	}
+	up = strings.Replace(up, migrateUpMarker, "", 1)
+	return strings.TrimSpace(up), strings.TrimSpace(down)
+}
+
+// Up menjalankan semua migrasi yang belum tercatat, berurutan dari versi terkecil
+func (m *Migrator) Up() (int, error) {
+	migrations, err := m.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = m.withLock(func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := verifyChecksums(migrations, applied); err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down membatalkan steps migrasi terakhir yang sudah dijalankan
+func (m *Migrator) Down(steps int) (int, error) {
+	migrations, err := m.Load()
+	if err != nil {
+		return 0, err
+	}
+	byVersion := map[int64]Migration{}
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	count := 0
+	err = m.withLock(func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for _, version := range versions {
+			if count == steps {
+				break
+			}
+			migration, ok := byVersion[version]
+			if !ok {
+				return fmt.Errorf("migration %03d_%s is applied but its file is missing", version, applied[version].Name)
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Redo membatalkan lalu menjalankan ulang migrasi terakhir
+func (m *Migrator) Redo() error {
+	migrations, err := m.Load()
+	if err != nil {
+		return err
+	}
+
+	return m.withLock(func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			migration := migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			return m.apply(ctx, conn, migration)
+		}
+		return errors.New("no applied migration to redo")
+	})
+}
+
+// Status semua migrasi (file dan yang tercatat) berurutan berdasarkan versi
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	migrations, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	err = m.withLock(func(ctx context.Context, conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if a, ok := applied[migration.Version]; ok {
+				appliedAt := a.AppliedAt
+				status.AppliedAt = &appliedAt
+				status.Modified = a.Checksum != migration.Checksum
+				delete(applied, migration.Version)
+			}
+			statuses = append(statuses, status)
+		}
+		for _, a := range applied {
+			appliedAt := a.AppliedAt
+			statuses = append(statuses, MigrationStatus{Version: a.Version, Name: a.Name, AppliedAt: &appliedAt, Missing: true})
+		}
+		return nil
+	})
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, err
+}
+
+// withLock memakai satu koneksi untuk semua langkah supaya advisory lock berlaku
+func (m *Migrator) withLock(fn func(ctx context.Context, conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	return fn(ctx, conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]appliedMigration{}
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied[a.Version] = a
+	}
+	return applied, rows.Err()
+}
+
+// verifyChecksums menolak menjalankan migrasi baru jika migrasi lama sudah diubah setelah dijalankan
+func verifyChecksums(migrations []Migration, applied map[int64]appliedMigration) error {
+	for _, migration := range migrations {
+		a, ok := applied[migration.Version]
+		if ok && a.Checksum != migration.Checksum {
+			return fmt.Errorf("%w: %03d_%s", ErrMigrationChanged, migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+		return fmt.Errorf("migrate up %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO schema_migrations (version, name, checksum, applied_at)
+		VALUES ($1, $2, $3, $4)
+	`, migration.Version, migration.Name, migration.Checksum, time.Now()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Printf("Migrated up: %03d_%s", migration.Version, migration.Name)
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("migration %03d_%s has no down section", migration.Version, migration.Name)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+		return fmt.Errorf("migrate down %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version=$1`, migration.Version); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Printf("Migrated down: %03d_%s", migration.Version, migration.Name)
+	return nil
 }
@@ -1,11 +0,0 @@
--- Drop tables (urutan FK harus diperhatikan)
-DROP TABLE IF EXISTS achievement_references CASCADE;
-DROP TABLE IF EXISTS students CASCADE;
-DROP TABLE IF EXISTS lecturers CASCADE;
-DROP TABLE IF EXISTS role_permissions CASCADE;
-DROP TABLE IF EXISTS permissions CASCADE;
-DROP TABLE IF EXISTS users CASCADE;
-DROP TABLE IF EXISTS roles CASCADE;
-
--- Drop enum type
-DROP TYPE IF EXISTS achievement_status;
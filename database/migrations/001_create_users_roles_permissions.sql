@@ -1,3 +1,4 @@
+-- +migrate Up
 -- 1. Roles
 CREATE TABLE IF NOT EXISTS roles (
     id UUID PRIMARY KEY,
@@ -44,7 +45,7 @@ VALUES
   (gen_random_uuid(), 'achievement:delete', 'achievement', 'delete', 'Hapus prestasi'),
   (gen_random_uuid(), 'achievement:verify', 'achievement', 'verify', 'Verifikasi prestasi'),
   (gen_random_uuid(), 'user:manage', 'user', 'manage', 'Manage user')
-ON CONFLICT (id) DO NOTHING;
+ON CONFLICT (name) DO NOTHING;
 
 -- 4. Role_Permissions
 CREATE TABLE IF NOT EXISTS role_permissions (
@@ -60,7 +61,8 @@ SELECT
     p.id AS permission_id
 FROM roles r
 CROSS JOIN permissions p
-WHERE r.name = 'Admin';
+WHERE r.name = 'Admin'
+ON CONFLICT DO NOTHING;
 
 INSERT INTO role_permissions (role_id, permission_id)
 SELECT 
@@ -68,7 +70,8 @@ SELECT
     p.id AS permission_id
 FROM roles r, permissions p
 WHERE r.name = 'Mahasiswa' 
-AND p.name IN ('achievement:create', 'achievement:read', 'achievement:update', 'achievement:delete');
+AND p.name IN ('achievement:create', 'achievement:read', 'achievement:update', 'achievement:delete')
+ON CONFLICT DO NOTHING;
 
 INSERT INTO role_permissions (role_id, permission_id)
 SELECT 
@@ -76,4 +79,11 @@ SELECT
     p.id AS permission_id
 FROM roles r, permissions p
 WHERE r.name = 'Dosen Wali' 
-AND p.name IN ('achievement:read', 'achievement:verify');
\ No newline at end of file
+AND p.name IN ('achievement:read', 'achievement:verify')
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS roles;
@@ -1,3 +1,4 @@
+-- +migrate Up
 -- 5. Lecturers
 CREATE TABLE IF NOT EXISTS lecturers (
     id UUID PRIMARY KEY,
@@ -17,3 +18,7 @@ CREATE TABLE IF NOT EXISTS students (
     advisor_id UUID REFERENCES lecturers(id),
     created_at TIMESTAMP DEFAULT NOW()
 );
+
+-- +migrate Down
+DROP TABLE IF EXISTS students;
+DROP TABLE IF EXISTS lecturers;
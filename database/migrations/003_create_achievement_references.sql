@@ -1,5 +1,12 @@
+-- +migrate Up
 -- 7. Achievement References
-CREATE TYPE achievement_status AS ENUM ('draft', 'submitted', 'verified', 'rejected', 'deleted');
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'achievement_status') THEN
+        CREATE TYPE achievement_status AS ENUM ('draft', 'submitted', 'verified', 'rejected', 'deleted');
+    END IF;
+END
+$$;
 
 CREATE TABLE IF NOT EXISTS achievement_references (
     id UUID PRIMARY KEY,
@@ -13,3 +20,7 @@ CREATE TABLE IF NOT EXISTS achievement_references (
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW()
 );
+
+-- +migrate Down
+DROP TABLE IF EXISTS achievement_references;
+DROP TYPE IF EXISTS achievement_status;
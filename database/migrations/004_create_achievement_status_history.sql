@@ -0,0 +1,17 @@
+-- +migrate Up
+-- 8. Achievement Status History
+CREATE TABLE IF NOT EXISTS achievement_status_history (
+    id UUID PRIMARY KEY,
+    achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    from_status achievement_status,
+    to_status achievement_status NOT NULL,
+    actor_id UUID REFERENCES users(id),
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_status_history_ref
+    ON achievement_status_history (achievement_ref_id, created_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS achievement_status_history;
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- 9. Revisi prestasi yang ditolak
+ALTER TABLE achievement_references
+    ADD COLUMN IF NOT EXISTS resubmission_count INT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE achievement_references DROP COLUMN IF EXISTS resubmission_count;
@@ -0,0 +1,21 @@
+-- +migrate Up
+-- 10. Refresh Tokens
+-- Setiap login membuat satu family; refresh merotasi token di dalam family yang sama
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id UUID NOT NULL,
+    parent_id UUID REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+    device_info VARCHAR(255),
+    ip_address VARCHAR(64),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens (family_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS refresh_tokens;
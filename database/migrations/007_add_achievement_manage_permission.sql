@@ -0,0 +1,18 @@
+-- +migrate Up
+-- 7. Permission akses global prestasi (pengganti pengecekan nama role "Admin")
+INSERT INTO permissions (id, name, resource, action, description)
+VALUES (gen_random_uuid(), 'achievement:manage', 'achievement', 'manage', 'Kelola prestasi semua mahasiswa')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'Admin'
+AND p.name = 'achievement:manage'
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE name = 'achievement:manage');
+
+DELETE FROM permissions WHERE name = 'achievement:manage';
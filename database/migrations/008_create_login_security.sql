@@ -0,0 +1,40 @@
+-- +migrate Up
+-- 8. Login attempts & account lockout
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id UUID PRIMARY KEY,
+    identifier VARCHAR(100) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    ip_address VARCHAR(64) NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts(ip_address, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user ON login_attempts(user_id, created_at);
+
+-- Status gagal berturut-turut per akun
+CREATE TABLE IF NOT EXISTS account_lockouts (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    failed_count INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP,
+    locked_until TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Riwayat lock / unlock akun
+CREATE TABLE IF NOT EXISTS account_lockout_events (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event VARCHAR(20) NOT NULL,
+    ip_address VARCHAR(64),
+    actor_id UUID REFERENCES users(id),
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_lockout_events_user ON account_lockout_events(user_id, created_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS account_lockout_events;
+DROP TABLE IF EXISTS account_lockouts;
+DROP TABLE IF EXISTS login_attempts;
@@ -0,0 +1,17 @@
+-- +migrate Up
+-- 10. Password reset tokens
+-- Hanya hash token yang disimpan; token sekali pakai dan punya masa berlaku
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    requested_ip VARCHAR(64),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user ON password_reset_tokens (user_id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS password_reset_tokens;
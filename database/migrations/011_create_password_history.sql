@@ -0,0 +1,14 @@
+-- +migrate Up
+-- 11. Password history
+-- Hash password yang pernah dipakai, untuk mencegah pemakaian ulang N password terakhir
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user ON password_history (user_id, created_at DESC);
+
+-- +migrate Down
+DROP TABLE IF EXISTS password_history;
@@ -0,0 +1,26 @@
+-- +migrate Up
+-- 13. Sessions
+-- Satu sesi per login; id sesi = family_id refresh token dan klaim "sid" di access token
+CREATE TABLE IF NOT EXISTS sessions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent VARCHAR(255),
+    ip_address VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions (user_id);
+
+-- Sesi untuk refresh token family yang sudah ada sebelum tabel ini dibuat
+INSERT INTO sessions (id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at)
+SELECT family_id, user_id, MAX(device_info), MAX(ip_address), MIN(created_at), MAX(created_at), MAX(expires_at)
+FROM refresh_tokens
+WHERE revoked_at IS NULL
+GROUP BY family_id, user_id
+ON CONFLICT (id) DO NOTHING;
+
+-- +migrate Down
+DROP TABLE IF EXISTS sessions;
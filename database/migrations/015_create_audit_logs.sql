@@ -0,0 +1,29 @@
+-- +migrate Up
+-- 15. Audit log & impersonation
+-- Tanpa FK ke users: jejak audit harus tetap ada walau user dihapus
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id UUID PRIMARY KEY,
+    actor_id UUID,
+    impersonator_id UUID,
+    action VARCHAR(100) NOT NULL,
+    target_type VARCHAR(50),
+    target_id VARCHAR(100),
+    reason TEXT,
+    session_id UUID,
+    method VARCHAR(10),
+    path TEXT,
+    status_code INT,
+    ip_address VARCHAR(64),
+    user_agent VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_impersonator ON audit_logs (impersonator_id, created_at);
+
+-- Sesi impersonation: sesi milik user target yang dibuat oleh admin
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS impersonator_id UUID REFERENCES users(id) ON DELETE CASCADE;
+
+-- +migrate Down
+ALTER TABLE sessions DROP COLUMN IF EXISTS impersonator_id;
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,28 @@
+-- +migrate Up
+-- 16. Audit log aksi admin & verifikasi
+-- Snapshot data sebelum/sesudah aksi (JSON dari model yang sama dengan respon API)
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS before_data JSONB;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS after_data JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target ON audit_logs (target_type, target_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action, created_at);
+
+-- Append-only: entri audit tidak boleh diubah atau dihapus lewat aplikasi
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_logs_append_only ON audit_logs;
+CREATE TRIGGER trg_audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS trg_audit_logs_append_only ON audit_logs;
+DROP FUNCTION IF EXISTS audit_logs_append_only();
+DROP INDEX IF EXISTS idx_audit_logs_action;
+DROP INDEX IF EXISTS idx_audit_logs_target;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS after_data;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS before_data;
@@ -0,0 +1,27 @@
+-- +migrate Up
+-- 17. Outbox sinkronisasi achievement_references (Postgres) dengan koleksi achievements (Mongo)
+-- Baris ditulis dalam transaksi yang sama dengan perubahan reference, lalu diselesaikan
+-- langsung oleh request atau oleh worker background setelah crash / Mongo gagal.
+CREATE TABLE IF NOT EXISTS achievement_outbox (
+    id UUID PRIMARY KEY,
+    operation VARCHAR(20) NOT NULL,
+    -- Tanpa FK: kompensasi create menghapus reference, baris outbox tetap jadi jejak
+    reference_id UUID NOT NULL,
+    mongo_achievement_id VARCHAR(24) NOT NULL,
+    payload JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_outbox_due
+    ON achievement_outbox (next_attempt_at) WHERE status IN ('pending', 'compensating');
+CREATE INDEX IF NOT EXISTS idx_achievement_outbox_reference
+    ON achievement_outbox (reference_id, created_at);
+
+-- +migrate Down
+DROP TABLE IF EXISTS achievement_outbox;
@@ -0,0 +1,22 @@
+-- +migrate Up
+-- 18. Laporan rekonsiliasi achievement_references (Postgres) dengan koleksi achievements (Mongo)
+CREATE TABLE IF NOT EXISTS achievement_reconciliation_reports (
+    id UUID PRIMARY KEY,
+    trigger VARCHAR(20) NOT NULL,
+    repair BOOLEAN NOT NULL DEFAULT FALSE,
+    quarantine BOOLEAN NOT NULL DEFAULT FALSE,
+    references_scanned INT NOT NULL DEFAULT 0,
+    documents_scanned INT NOT NULL DEFAULT 0,
+    issue_count INT NOT NULL DEFAULT 0,
+    counts JSONB NOT NULL DEFAULT '{}',
+    issues JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_reconciliation_reports_started
+    ON achievement_reconciliation_reports (started_at DESC);
+
+-- +migrate Down
+DROP TABLE IF EXISTS achievement_reconciliation_reports;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- 19. Index untuk pagination cursor (keyset) atas (kolom urutan, id)
+CREATE INDEX IF NOT EXISTS idx_users_active_created_id
+    ON users (created_at DESC, id DESC) WHERE is_active = true;
+CREATE INDEX IF NOT EXISTS idx_users_full_name_id ON users (full_name, id);
+CREATE INDEX IF NOT EXISTS idx_achievement_references_created_id
+    ON achievement_references (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_id ON audit_logs (created_at DESC, id DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_audit_logs_created_id;
+DROP INDEX IF EXISTS idx_achievement_references_created_id;
+DROP INDEX IF EXISTS idx_users_full_name_id;
+DROP INDEX IF EXISTS idx_users_active_created_id;
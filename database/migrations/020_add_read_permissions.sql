@@ -0,0 +1,22 @@
+-- +migrate Up
+-- 20. Permission baca mahasiswa, dosen dan laporan supaya scope API key ikut membatasi endpoint itu.
+-- Semua role yang ada diberi permission ini agar akses login tidak berubah; relasi
+-- pemilik / dosen wali tetap dicek di service.
+INSERT INTO permissions (id, name, resource, action, description)
+VALUES
+  (gen_random_uuid(), 'student:read', 'student', 'read', 'Baca data mahasiswa'),
+  (gen_random_uuid(), 'lecturer:read', 'lecturer', 'read', 'Baca data dosen dan mahasiswa bimbingan'),
+  (gen_random_uuid(), 'report:read', 'report', 'read', 'Baca statistik dan laporan prestasi')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE p.name IN ('student:read', 'lecturer:read', 'report:read')
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+DELETE FROM role_permissions
+WHERE permission_id IN (SELECT id FROM permissions WHERE name IN ('student:read', 'lecturer:read', 'report:read'));
+
+DELETE FROM permissions WHERE name IN ('student:read', 'lecturer:read', 'report:read');
@@ -0,0 +1,10 @@
+-- +migrate Up
+-- 21. Satu user paling banyak satu profil mahasiswa / dosen (GetByUserID sudah mengasumsikan ini).
+-- Pagination cursor mahasiswa & dosen mengurutkan (u.full_name, u.id) memakai idx_users_full_name_id,
+-- jadi u.id harus unik per baris.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_students_user_id_unique ON students (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_lecturers_user_id_unique ON lecturers (user_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_lecturers_user_id_unique;
+DROP INDEX IF EXISTS idx_students_user_id_unique;
@@ -0,0 +1,42 @@
+-- +migrate Up
+-- 22. Riwayat status untuk prestasi yang dibuat sebelum achievement_status_history ada,
+-- direkonstruksi dari kolom achievement_references. Pembuatan dan submit dicatat atas nama
+-- mahasiswa pemilik, verifikasi / penolakan atas nama verified_by. Reference yang sudah
+-- punya riwayat tidak disentuh.
+WITH legacy AS (
+    SELECT ar.id, ar.status, ar.submitted_at, ar.verified_at, ar.verified_by,
+           ar.rejection_note, ar.created_at, ar.updated_at, s.user_id AS owner_id
+    FROM achievement_references ar
+    LEFT JOIN students s ON s.id = ar.student_id
+    WHERE NOT EXISTS (
+        SELECT 1 FROM achievement_status_history h WHERE h.achievement_ref_id = ar.id
+    )
+)
+INSERT INTO achievement_status_history (id, achievement_ref_id, from_status, to_status, actor_id, note, created_at)
+SELECT gen_random_uuid(), id, NULL::achievement_status, 'draft'::achievement_status,
+       owner_id, 'Achievement created', created_at
+FROM legacy
+UNION ALL
+SELECT gen_random_uuid(), id, 'draft'::achievement_status, 'submitted'::achievement_status,
+       owner_id, 'Submitted for verification', submitted_at
+FROM legacy
+WHERE submitted_at IS NOT NULL
+UNION ALL
+SELECT gen_random_uuid(), id, 'submitted'::achievement_status, 'verified'::achievement_status,
+       verified_by, 'Achievement verified', COALESCE(verified_at, updated_at)
+FROM legacy
+WHERE status = 'verified'
+UNION ALL
+SELECT gen_random_uuid(), id, 'submitted'::achievement_status, 'rejected'::achievement_status,
+       verified_by, COALESCE(rejection_note, 'Achievement rejected'), updated_at
+FROM legacy
+WHERE status = 'rejected'
+UNION ALL
+SELECT gen_random_uuid(), id, 'draft'::achievement_status, 'deleted'::achievement_status,
+       NULL, 'Achievement deleted', updated_at
+FROM legacy
+WHERE status = 'deleted';
+
+-- +migrate Down
+-- Riwayat hasil backfill tidak bisa dibedakan dari riwayat asli, jadi dibiarkan
+SELECT 1;
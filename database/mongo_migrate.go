@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"achievement-backend/app/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoMigrationsCollection pencatat migrasi Mongo yang sudah dijalankan (padanan schema_migrations)
+const mongoMigrationsCollection = "schema_migrations"
+
+// MongoMigration langkah bootstrap Mongo. Up harus idempotent karena koleksi
+// bisa saja sudah dibuat implisit oleh insert sebelum migrasi ini ada.
+type MongoMigration struct {
+	Version int64
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+}
+
+// mongoMigrations dijalankan berurutan oleh -migrate up, setelah migrasi SQL
+var mongoMigrations = []MongoMigration{
+	{Version: 1, Name: "create_achievement_indexes", Up: createAchievementIndexes},
+	{Version: 2, Name: "add_achievement_validator", Up: addAchievementValidator},
+	{Version: 3, Name: "create_achievement_text_index", Up: createAchievementTextIndex},
+}
+
+type mongoAppliedMigration struct {
+	Version   int64     `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"appliedAt"`
+}
+
+// MongoMigrateUp menjalankan migrasi Mongo yang belum tercatat
+func MongoMigrateUp(ctx context.Context, db *mongo.Database) (int, error) {
+	applied, err := mongoApplied(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range mongoMigrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := migration.Up(ctx, db); err != nil {
+			return count, fmt.Errorf("mongo migrate up %03d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := db.Collection(mongoMigrationsCollection).InsertOne(ctx, mongoAppliedMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now(),
+		}); err != nil {
+			return count, err
+		}
+		log.Printf("Mongo migrated up: %03d_%s", migration.Version, migration.Name)
+		count++
+	}
+	return count, nil
+}
+
+// MongoMigrationStatus status migrasi Mongo, format sama dengan migrasi SQL
+func MongoMigrationStatus(ctx context.Context, db *mongo.Database) ([]MigrationStatus, error) {
+	applied, err := mongoApplied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(mongoMigrations))
+	for _, migration := range mongoMigrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if a, ok := applied[migration.Version]; ok {
+			appliedAt := a.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func mongoApplied(ctx context.Context, db *mongo.Database) (map[int64]mongoAppliedMigration, error) {
+	cursor, err := db.Collection(mongoMigrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []mongoAppliedMigration
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]mongoAppliedMigration, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// createAchievementIndexes index untuk filter studentId (daftar & statistik), agregasi per
+// achievementType, urutan/rentang createdAt dan pencarian tags
+func createAchievementIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("achievements").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "studentId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("idx_student_created"),
+		},
+		{
+			Keys:    bson.D{{Key: "studentId", Value: 1}, {Key: "achievementType", Value: 1}},
+			Options: options.Index().SetName("idx_student_type"),
+		},
+		{
+			Keys:    bson.D{{Key: "achievementType", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("idx_type_created"),
+		},
+		{
+			Keys:    bson.D{{Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("idx_created"),
+		},
+		{
+			Keys:    bson.D{{Key: "tags", Value: 1}},
+			Options: options.Index().SetName("idx_tags"),
+		},
+	})
+	return err
+}
+
+// createAchievementTextIndex index full-text untuk parameter q di GET /achievements.
+// Bahasa "none": teks kebanyakan bahasa Indonesia, stemming/stop word bahasa Inggris justru merusak hasil.
+func createAchievementTextIndex(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("achievements").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "title", Value: "text"},
+			{Key: "description", Value: "text"},
+			{Key: "tags", Value: "text"},
+			{Key: "details.competitionName", Value: "text"},
+			{Key: "details.publicationTitle", Value: "text"},
+			{Key: "details.organizationName", Value: "text"},
+			{Key: "details.position", Value: "text"},
+			{Key: "details.publisher", Value: "text"},
+			{Key: "details.certificationName", Value: "text"},
+			{Key: "details.issuedBy", Value: "text"},
+			{Key: "details.organizer", Value: "text"},
+			{Key: "details.location", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("idx_text").
+			SetDefaultLanguage("none").
+			SetWeights(bson.D{
+				{Key: "title", Value: 10},
+				{Key: "tags", Value: 5},
+				{Key: "details.competitionName", Value: 5},
+				{Key: "details.publicationTitle", Value: 5},
+				{Key: "description", Value: 2},
+			}),
+	})
+	return err
+}
+
+// addAchievementValidator memasang $jsonSchema sesuai models.Achievement.
+// validationLevel moderate: dokumen lama yang tidak valid masih bisa di-update.
+func addAchievementValidator(ctx context.Context, db *mongo.Database) error {
+	validator := bson.M{"$jsonSchema": achievementJSONSchema()}
+
+	names, err := db.ListCollectionNames(ctx, bson.M{"name": "achievements"})
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return db.CreateCollection(ctx, "achievements", options.CreateCollection().
+			SetValidator(validator).
+			SetValidationLevel("moderate").
+			SetValidationAction("error"))
+	}
+
+	return db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: "achievements"},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "moderate"},
+		{Key: "validationAction", Value: "error"},
+	}).Err()
+}
+
+// achievementJSONSchema slice nil di Go disimpan sebagai null, jadi attachments/tags boleh null
+func achievementJSONSchema() bson.M {
+	return bson.M{
+		"bsonType": "object",
+		"required": bson.A{"studentId", "achievementType", "title", "createdAt", "updatedAt"},
+		"properties": bson.M{
+			"studentId": bson.M{
+				"bsonType":    "string",
+				"pattern":     "^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$",
+				"description": "UUID mahasiswa (students.id)",
+			},
+			"achievementType": bson.M{
+				"enum": models.AchievementTypes,
+			},
+			"title": bson.M{
+				"bsonType":  "string",
+				"minLength": 1,
+			},
+			"description": bson.M{"bsonType": "string"},
+			"details":     bson.M{"bsonType": "object"},
+			"attachments": bson.M{
+				"bsonType": bson.A{"array", "null"},
+				"items": bson.M{
+					"bsonType": "object",
+					"required": bson.A{"fileName", "fileUrl"},
+					"properties": bson.M{
+						"fileName":   bson.M{"bsonType": "string"},
+						"fileUrl":    bson.M{"bsonType": "string"},
+						"fileType":   bson.M{"bsonType": "string"},
+						"fileSize":   bson.M{"bsonType": bson.A{"int", "long"}},
+						"uploadedAt": bson.M{"bsonType": "date"},
+					},
+				},
+			},
+			"tags": bson.M{
+				"bsonType": bson.A{"array", "null"},
+				"items":    bson.M{"bsonType": "string"},
+			},
+			"points":    bson.M{"bsonType": bson.A{"int", "long"}},
+			"createdAt": bson.M{"bsonType": "date"},
+			"updatedAt": bson.M{"bsonType": "date"},
+		},
+	}
+}
@@ -0,0 +1,36 @@
+-- HANYA untuk development: menghapus seluruh schema termasuk riwayat migrasi.
+-- Tidak dijalankan oleh migrator; jalankan manual, mis. psql -f database/scripts/drop_all.sql
+-- Untuk membatalkan migrasi secara bertahap gunakan: go run . -migrate down N
+
+-- Drop tables (urutan FK harus diperhatikan)
+DROP TABLE IF EXISTS schema_migrations CASCADE;
+DROP TABLE IF EXISTS achievement_reconciliation_reports CASCADE;
+DROP TABLE IF EXISTS achievement_outbox CASCADE;
+DROP TABLE IF EXISTS audit_logs CASCADE;
+DROP TABLE IF EXISTS oidc_login_states CASCADE;
+DROP TABLE IF EXISTS user_identities CASCADE;
+DROP TABLE IF EXISTS sessions CASCADE;
+DROP TABLE IF EXISTS api_key_permissions CASCADE;
+DROP TABLE IF EXISTS api_keys CASCADE;
+DROP TABLE IF EXISTS password_history CASCADE;
+DROP TABLE IF EXISTS password_reset_tokens CASCADE;
+DROP TABLE IF EXISTS user_recovery_codes CASCADE;
+DROP TABLE IF EXISTS user_totp CASCADE;
+DROP TABLE IF EXISTS account_lockout_events CASCADE;
+DROP TABLE IF EXISTS account_lockouts CASCADE;
+DROP TABLE IF EXISTS login_attempts CASCADE;
+DROP TABLE IF EXISTS refresh_tokens CASCADE;
+DROP TABLE IF EXISTS achievement_status_history CASCADE;
+DROP TABLE IF EXISTS achievement_references CASCADE;
+DROP TABLE IF EXISTS students CASCADE;
+DROP TABLE IF EXISTS lecturers CASCADE;
+DROP TABLE IF EXISTS role_permissions CASCADE;
+DROP TABLE IF EXISTS permissions CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
+DROP TABLE IF EXISTS roles CASCADE;
+
+-- Drop enum type
+DROP TYPE IF EXISTS achievement_status;
+
+-- Drop function
+DROP FUNCTION IF EXISTS audit_logs_append_only();
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,9 +12,12 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 
+	"achievement-backend/app/repository"
+	"achievement-backend/app/service"
 	"achievement-backend/config"
 	"achievement-backend/database"
 	"achievement-backend/route"
+	"achievement-backend/utils"
 )
 
 // @title Achievement Management Backend API
@@ -33,13 +39,19 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
 	if err := config.LoadConfig(); err != nil {
 		log.Println("Warning: .env file not found")
 	}
 
-	// Flag migrate
-	migrateFlag := flag.Bool("migrate", false, "Run database migrations")
+	// Flag migrate: -migrate [up | down N | status | redo]
+	migrateFlag := flag.Bool("migrate", false, "Run database migrations (up, down N, status, redo)")
+	// Flag reconcile: -reconcile [report | repair | quarantine]
+	reconcileFlag := flag.Bool("reconcile", false, "Reconcile achievement references with Mongo documents (report, repair, quarantine)")
 	flag.Parse()
 
 	database.ConnectDB()
@@ -47,11 +59,22 @@ func main() {
 
 	if *migrateFlag {
 		log.Println("Running migrations...")
-		database.Migrate(database.PgDB, "./database/migrations")
+		if err := database.RunMigrations(database.PgDB, database.MongoDB, "./database/migrations", flag.Args()); err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
 		log.Println("Migrations completed")
 		return
 	}
 
+	if *reconcileFlag {
+		runReconcile(flag.Args())
+		return
+	}
+
+	if err := utils.InitJWT(config.LoadJWTConfig()); err != nil {
+		log.Fatal("Error loading JWT keys: ", err)
+	}
+
 	app := fiber.New(config.FiberConfig())
 	app.Use(recover.New())
 	app.Use(cors.New())
@@ -63,4 +86,31 @@ func main() {
 	port := config.GetEnv("APP_PORT", "3000")
 	log.Printf("🚀 Server running on port %s", port)
 	log.Fatal(app.Listen(":" + port))
-}
\ No newline at end of file
+}
+// runReconcile satu kali rekonsiliasi dari command line; laporan dicetak sebagai JSON
+// dan juga disimpan di achievement_reconciliation_reports
+func runReconcile(args []string) {
+	opts, err := service.ParseReconcileArgs(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	reconciler := service.NewAchievementReconciler(
+		repository.NewAchievementRepository(database.MongoDB),
+		repository.NewAchievementReferenceRepository(database.PgDB),
+		repository.NewReconciliationReportRepository(database.PgDB),
+		config.LoadReconcileConfig(),
+	)
+
+	report, err := reconciler.Reconcile(context.Background(), opts)
+	if report == nil {
+		log.Fatal("Reconciliation failed: ", err)
+	}
+	output, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(output))
+	if err != nil {
+		log.Fatal("Reconciliation failed: ", err)
+	}
+	log.Printf("Reconciliation %s: %d reference(s), %d document(s), %d issue(s)",
+		report.ID, report.ReferencesScanned, report.DocumentsScanned, report.IssueCount)
+}
@@ -1,8 +1,11 @@
 package middleware
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
+	"achievement-backend/app/models"
 	"achievement-backend/app/repository"
 	"achievement-backend/utils"
 
@@ -10,8 +13,25 @@ import (
 	"github.com/google/uuid"
 )
 
-func RequireAuth(userRepo repository.UserRepository) fiber.Handler {
+// Cara request diautentikasi, disimpan di c.Locals("auth_method")
+const (
+	AuthMethodJWT    = "jwt"
+	AuthMethodAPIKey = "api_key"
+)
+
+// RequireAuth menerima Bearer JWT atau API key (header X-API-Key, atau Bearer ak_...)
+func RequireAuth(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	apiKeyRepo repository.APIKeyRepository,
+	sessionRepo repository.SessionRepository,
+	auditLogRepo repository.AuditLogRepository,
+) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if apiKey := apiKeyFromRequest(c); apiKey != "" {
+			return authenticateAPIKey(c, apiKey, userRepo, roleRepo, apiKeyRepo)
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -43,6 +63,28 @@ func RequireAuth(userRepo repository.UserRepository) fiber.Handler {
 			})
 		}
 
+		sessionID, err := uuid.Parse(claims.SessionID)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "invalid session in token",
+			})
+		}
+
+		// Access token ikut mati begitu sesinya di-revoke, tidak menunggu expired
+		now := time.Now()
+		session, err := sessionRepo.GetByID(sessionID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "failed to check session",
+				"error":   err.Error(),
+			})
+		}
+		if session == nil || session.UserID != userID || !session.IsActive(now) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "session has been revoked",
+			})
+		}
+
 		user, err := userRepo.GetByID(userID)
 		if err != nil || user == nil || !user.IsActive {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -50,91 +92,175 @@ func RequireAuth(userRepo repository.UserRepository) fiber.Handler {
 			})
 		}
 
+		impersonator, err := resolveImpersonator(claims, session, userRepo, roleRepo)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"message": "invalid impersonation session",
+				"error":   err.Error(),
+			})
+		}
+
+		// Permission diambil dari role user saat ini (bukan dari token),
+		// jadi perubahan role / role_permissions langsung berlaku
+		permissions, err := roleRepo.GetPermissionNamesByRoleID(user.RoleID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "failed to resolve permissions",
+				"error":   err.Error(),
+			})
+		}
+
+		if err := sessionRepo.Touch(session.ID, now); err != nil {
+			fmt.Printf("Warning: Failed to update last_seen_at for session %s: %v\n", session.ID, err)
+		}
+
 		c.Locals("user_id", user.ID)
 		c.Locals("user", user)
 		c.Locals("role_id", user.RoleID)
-		c.Locals("permissions", claims.Permissions) 
+		c.Locals("permissions", permissions) 
+		c.Locals("auth_method", AuthMethodJWT)
+		c.Locals("session_id", session.ID)
 
-		return c.Next()
+		if impersonator == nil {
+			return c.Next()
+		}
+
+		// Selama impersonation setiap request dicatat dengan identitas admin dan user target
+		c.Locals("impersonator", impersonator)
+		err = c.Next()
+		recordImpersonatedRequest(c, auditLogRepo, user, impersonator, session, err)
+		return err
 	}
 }
 
-func RequirePermission(permission string) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		permissions, ok := c.Locals("permissions").([]string)
-		if !ok {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"message": "no permissions found",
-			})
-		}
+func apiKeyFromRequest(c *fiber.Ctx) string {
+	if key := c.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if token, found := strings.CutPrefix(c.Get("Authorization"), "Bearer "); found && strings.HasPrefix(token, models.APIKeyPrefix) {
+		return token
+	}
+	return ""
+}
 
-		hasPermission := false
-		for _, p := range permissions {
-			if p == permission {
-				hasPermission = true
-				break
-			}
-		}
+func authenticateAPIKey(
+	c *fiber.Ctx,
+	rawKey string,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	apiKeyRepo repository.APIKeyRepository,
+) error {
+	prefix, ok := utils.APIKeyLookupPrefix(rawKey)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "invalid api key",
+		})
+	}
 
-		if !hasPermission {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"message": "insufficient permissions",
-				"required": permission,
-			})
-		}
+	key, err := apiKeyRepo.GetByPrefix(prefix)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to check api key",
+			"error":   err.Error(),
+		})
+	}
 
-		return c.Next()
+	now := time.Now()
+	if key == nil || !utils.VerifyTokenHash(rawKey, key.KeyHash) || !key.IsActive(now) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "invalid, expired or revoked api key",
+		})
+	}
+
+	user, err := userRepo.GetByID(key.UserID)
+	if err != nil || user == nil || !user.IsActive {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "user not found or inactive",
+		})
+	}
+
+	rolePermissions, err := roleRepo.GetPermissionNamesByRoleID(user.RoleID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed to resolve permissions",
+			"error":   err.Error(),
+		})
 	}
+
+	if err := apiKeyRepo.TouchLastUsed(key.ID, now); err != nil {
+		fmt.Printf("Warning: Failed to update last_used_at for api key %s: %v\n", key.Prefix, err)
+	}
+
+	c.Locals("user_id", user.ID)
+	c.Locals("user", user)
+	c.Locals("role_id", user.RoleID)
+	// Key hanya memakai permission yang masih dimiliki role user saat ini
+	c.Locals("permissions", intersectPermissions(key.Permissions, rolePermissions))
+	c.Locals("auth_method", AuthMethodAPIKey)
+	c.Locals("api_key_id", key.ID)
+
+	return c.Next()
 }
 
-func AdminOnly(roleRepo repository.RoleRepository) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		roleID, ok := c.Locals("role_id").(uuid.UUID)
-		if !ok {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			})
+func hasPermission(permissions []string, permission string) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
 		}
+	}
+	return false
+}
 
-		role, err := roleRepo.GetByID(roleID)
-		if err != nil || role == nil {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"message": "role not found",
-			})
+func intersectPermissions(scope, granted []string) []string {
+	result := []string{}
+	for _, p := range scope {
+		for _, g := range granted {
+			if p == g {
+				result = append(result, p)
+				break
+			}
 		}
+	}
+	return result
+}
 
-		if role.Name != "Admin" {
+// RejectAPIKey untuk endpoint yang hanya boleh dipakai dari sesi login
+// (kelola API key, ganti password, 2FA, dll)
+func RejectAPIKey() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if method, _ := c.Locals("auth_method").(string); method == AuthMethodAPIKey {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"message": "admin access required",
+				"message": "this endpoint cannot be used with an api key",
 			})
 		}
-
 		return c.Next()
 	}
 }
 
-func RequireRole(roleName string, roleRepo repository.RoleRepository) fiber.Handler {
+func RequirePermission(permission string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		roleID, ok := c.Locals("role_id").(uuid.UUID)
+		permissions, ok := c.Locals("permissions").([]string)
 		if !ok {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "no permissions found",
 			})
 		}
 
-		role, err := roleRepo.GetByID(roleID)
-		if err != nil || role == nil {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"message": "role not found",
-			})
+		hasPermission := false
+		for _, p := range permissions {
+			if p == permission {
+				hasPermission = true
+				break
+			}
 		}
 
-		if role.Name != roleName {
+		if !hasPermission {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"message": roleName + " access required",
-				"required_role": roleName,
-				"user_role": role.Name,
+				"message": "insufficient permissions",
+				"required": permission,
 			})
 		}
 
 		return c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// resolveImpersonator nil untuk sesi biasa. Klaim "act" harus cocok dengan sesi di database,
+// dan admin-nya harus masih aktif serta masih boleh mengelola user.
+func resolveImpersonator(
+	claims *models.JWTClaims,
+	session *models.Session,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+) (*models.User, error) {
+	if claims.Act == nil && session.ImpersonatorID == nil {
+		return nil, nil
+	}
+	if claims.Act == nil || session.ImpersonatorID == nil || claims.Act.Subject != session.ImpersonatorID.String() {
+		return nil, errors.New("actor claim does not match session")
+	}
+
+	impersonator, err := userRepo.GetByID(*session.ImpersonatorID)
+	if err != nil {
+		return nil, err
+	}
+	if impersonator == nil || !impersonator.IsActive {
+		return nil, errors.New("impersonator not found or inactive")
+	}
+
+	permissions, err := roleRepo.GetPermissionNamesByRoleID(impersonator.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasPermission(permissions, models.PermissionUserManage) {
+		return nil, errors.New("impersonator can no longer manage users")
+	}
+
+	return impersonator, nil
+}
+
+func recordImpersonatedRequest(
+	c *fiber.Ctx,
+	auditLogRepo repository.AuditLogRepository,
+	user, impersonator *models.User,
+	session *models.Session,
+	handlerErr error,
+) {
+	status := c.Response().StatusCode()
+	var fiberErr *fiber.Error
+	if errors.As(handlerErr, &fiberErr) {
+		status = fiberErr.Code
+	} else if handlerErr != nil {
+		status = fiber.StatusInternalServerError
+	}
+
+	userAgent := c.Get("User-Agent")
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	entry := &models.AuditLog{
+		ID:             uuid.New(),
+		ActorID:        &user.ID,
+		ImpersonatorID: &impersonator.ID,
+		Action:         models.AuditActionImpersonatedRequest,
+		SessionID:      &session.ID,
+		Method:         c.Method(),
+		Path:           c.OriginalURL(),
+		StatusCode:     status,
+		IPAddress:      c.IP(),
+		UserAgent:      userAgent,
+		CreatedAt:      time.Now(),
+	}
+	if err := auditLogRepo.Create(entry); err != nil {
+		fmt.Printf("Warning: Failed to write audit log for impersonated request %s %s: %v\n", entry.Method, entry.Path, err)
+	}
+}
+
+// RejectImpersonation untuk endpoint yang mengubah kredensial user (password, 2FA, API key):
+// admin yang sedang impersonate tidak boleh memakainya atas nama user
+func RejectImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if impersonator, _ := c.Locals("impersonator").(*models.User); impersonator != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"message": "this endpoint cannot be used while impersonating",
+			})
+		}
+		return c.Next()
+	}
+}
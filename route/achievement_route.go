@@ -11,10 +11,13 @@ import (
 
 func setupAchievementRoutes(
 	router fiber.Router,
+	requireAuth fiber.Handler,
 	userRepo repository.UserRepository,
 	roleRepo repository.RoleRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
+	auditLogger *service.AuditLogger,
+	achievementSync *service.AchievementSync,
 ) {
 	mongoDB := database.GetMongoDB()
 	
@@ -28,11 +31,13 @@ func setupAchievementRoutes(
 		lecturerRepo,
 		userRepo,
 		roleRepo,
+		auditLogger,
+		achievementSync,
 	)
 
 	achievementRoutes := router.Group("/achievements")
 	
-	protectedRoutes := achievementRoutes.Group("", middleware.RequireAuth(userRepo))
+	protectedRoutes := achievementRoutes.Group("", requireAuth)
 	
 	protectedRoutes.Get("/", middleware.RequirePermission("achievement:read"), achievementService.GetAchievementsByRole) 
 	protectedRoutes.Get("/:id", middleware.RequirePermission("achievement:read"), achievementService.GetAchievementByID) 
@@ -41,6 +46,7 @@ func setupAchievementRoutes(
 	protectedRoutes.Put("/:id", middleware.RequirePermission("achievement:update"), achievementService.UpdateAchievement) 
 	protectedRoutes.Delete("/:id", middleware.RequirePermission("achievement:delete"), achievementService.DeleteAchievement) 
 	protectedRoutes.Post("/:id/submit", middleware.RequirePermission("achievement:update"), achievementService.SubmitAchievement)
+	protectedRoutes.Post("/:id/revise", middleware.RequirePermission("achievement:update"), achievementService.ReviseAchievement)
 	
 	protectedRoutes.Post("/:id/verify", middleware.RequirePermission("achievement:verify"), achievementService.VerifyAchievement) 
 	protectedRoutes.Post("/:id/reject", middleware.RequirePermission("achievement:verify"), achievementService.RejectAchievement)
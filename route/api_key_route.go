@@ -0,0 +1,23 @@
+package route
+
+import (
+	"achievement-backend/app/repository"
+	"achievement-backend/app/service"
+	"achievement-backend/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func setupAPIKeyRoutes(
+	router fiber.Router,
+	requireAuth fiber.Handler,
+	apiKeyRepo repository.APIKeyRepository,
+) {
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+
+	// API key tidak boleh dipakai untuk membuat / mencabut API key, begitu juga sesi impersonation
+	apiKeyRoutes := router.Group("/api-keys", requireAuth, middleware.RejectAPIKey(), middleware.RejectImpersonation())
+	apiKeyRoutes.Get("/", apiKeyService.GetMyAPIKeys)
+	apiKeyRoutes.Post("/", apiKeyService.CreateAPIKey)
+	apiKeyRoutes.Delete("/:id", apiKeyService.RevokeAPIKey)
+}
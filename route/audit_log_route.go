@@ -0,0 +1,21 @@
+package route
+
+import (
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/app/service"
+	"achievement-backend/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func setupAuditLogRoutes(
+	router fiber.Router,
+	requireAuth fiber.Handler,
+	auditLogRepo repository.AuditLogRepository,
+) {
+	auditLogService := service.NewAuditLogService(auditLogRepo)
+
+	auditLogRoutes := router.Group("/audit-logs", requireAuth, middleware.RequirePermission(models.PermissionUserManage))
+	auditLogRoutes.Get("/", auditLogService.GetAll)
+}
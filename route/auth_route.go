@@ -1,26 +1,76 @@
 package route
 
 import (
+	"log"
+
+	"achievement-backend/config"
 	"achievement-backend/middleware"
 	"achievement-backend/app/repository"
 	"achievement-backend/app/service"
+	"achievement-backend/utils"
 	
 	"github.com/gofiber/fiber/v2"
 )
 
 func setupAuthRoutes(
 	router fiber.Router,
+	requireAuth fiber.Handler,
 	userRepo repository.UserRepository,
 	roleRepo repository.RoleRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	sessionRepo repository.SessionRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	passwordResetRepo repository.PasswordResetRepository,
+	oidcRepo repository.OIDCRepository,
+	loginThrottle *service.LoginThrottle,
+	passwordPolicy *service.PasswordPolicy,
+	mailer utils.Mailer,
+	impersonationService *service.ImpersonationService,
 ) {
-	authService := service.NewAuthService(userRepo, roleRepo, studentRepo, lecturerRepo)
+	authProviders, err := service.NewAuthProviders(config.LoadAuthProvidersConfig(), config.LoadLDAPConfig(), userRepo, roleRepo, lecturerRepo, oidcRepo)
+	if err != nil {
+		log.Fatalf("Failed to configure auth providers: %v", err)
+	}
+
+	authService := service.NewAuthService(userRepo, roleRepo, studentRepo, lecturerRepo, refreshTokenRepo, sessionRepo, twoFactorRepo, loginThrottle, passwordPolicy, authProviders)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userRepo, roleRepo, authService, loginThrottle, config.GetEnv("TOTP_ISSUER", "Achievement System"))
+	passwordResetService := service.NewPasswordResetService(userRepo, passwordResetRepo, sessionRepo, passwordPolicy, mailer, config.LoadPasswordResetConfig())
+	sessionService := service.NewSessionService(sessionRepo)
+	oidcConfig := config.LoadOIDCConfig()
+	oidcService := service.NewOIDCService(oidcRepo, userRepo, roleRepo, studentRepo, authService, utils.NewOIDCClient(oidcConfig), oidcConfig)
 	
 	authRoutes := router.Group("/auth")
 	
 	authRoutes.Post("/login", authService.Login)
 	authRoutes.Post("/refresh", authService.RefreshToken)
 	authRoutes.Post("/logout", authService.Logout)
-	authRoutes.Get("/profile", middleware.RequireAuth(userRepo),authService.Profile,)
+	authRoutes.Post("/logout-all", requireAuth, middleware.RejectAPIKey(), middleware.RejectImpersonation(), authService.LogoutAll)
+	authRoutes.Get("/profile", requireAuth,authService.Profile,)
+	authRoutes.Post("/change-password", requireAuth, middleware.RejectAPIKey(), middleware.RejectImpersonation(), authService.ChangePassword)
+	authRoutes.Post("/forgot-password", passwordResetService.ForgotPassword)
+	authRoutes.Post("/reset-password", passwordResetService.ResetPassword)
+	authRoutes.Post("/impersonation/stop", requireAuth, impersonationService.Stop)
+
+	// SSO lewat IdP kampus (authorization code + PKCE)
+	authRoutes.Get("/oidc/login", oidcService.Login)
+	authRoutes.Get("/oidc/callback", oidcService.Callback)
+
+	// Langkah kedua login (pakai challenge token, belum punya token akses)
+	authRoutes.Post("/2fa/verify", twoFactorService.Verify)
+	authRoutes.Post("/2fa/enroll", twoFactorService.Enroll)
+	authRoutes.Post("/2fa/enroll/confirm", twoFactorService.EnrollConfirm)
+
+	twoFactorRoutes := authRoutes.Group("/2fa", requireAuth, middleware.RejectAPIKey(), middleware.RejectImpersonation())
+	twoFactorRoutes.Get("/", twoFactorService.Status)
+	twoFactorRoutes.Post("/setup", twoFactorService.Setup)
+	twoFactorRoutes.Post("/enable", twoFactorService.Enable)
+	twoFactorRoutes.Post("/disable", twoFactorService.Disable)
+	twoFactorRoutes.Post("/recovery-codes", twoFactorService.RegenerateRecoveryCodes)
+
+	sessionRoutes := authRoutes.Group("/sessions", requireAuth, middleware.RejectAPIKey(), middleware.RejectImpersonation())
+	sessionRoutes.Get("/", sessionService.GetMySessions)
+	sessionRoutes.Delete("/", sessionService.RevokeOtherSessions)
+	sessionRoutes.Delete("/:id", sessionService.RevokeSession)
 }
\ No newline at end of file
@@ -0,0 +1,22 @@
+package route
+
+import (
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/app/service"
+	"achievement-backend/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func setupReconciliationRoutes(
+	router fiber.Router,
+	requireAuth fiber.Handler,
+	reportRepo repository.ReconciliationReportRepository,
+) {
+	reconciliationService := service.NewReconciliationService(reportRepo)
+
+	reportRoutes := router.Group("/reconciliation-reports", requireAuth, middleware.RequirePermission(models.PermissionAchievementManage))
+	reportRoutes.Get("/", reconciliationService.GetAll)
+	reportRoutes.Get("/:id", reconciliationService.GetByID)
+}
@@ -2,15 +2,17 @@
 package route
 
 import (
-	"achievement-backend/middleware"
+	"achievement-backend/app/models"
 	"achievement-backend/app/repository"
 	"achievement-backend/app/service"
+	"achievement-backend/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func SetupReportRoutes(
 	router fiber.Router,
+	requireAuth fiber.Handler,
 	userRepo repository.UserRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
@@ -24,6 +26,6 @@ func SetupReportRoutes(
 		lecturerRepo,
 		roleRepo,
 	)
-	router.Get("/reports/statistics", middleware.RequireAuth(userRepo), reportService.GetStatistics,)
-	router.Get("/reports/student/:id", middleware.RequireAuth(userRepo),reportService.GetStudentReport,)
+	router.Get("/reports/statistics", requireAuth, middleware.RequirePermission(models.PermissionReportRead), reportService.GetStatistics,)
+	router.Get("/reports/student/:id", requireAuth, middleware.RequirePermission(models.PermissionReportRead), reportService.GetStudentReport,)
 }
\ No newline at end of file
@@ -0,0 +1,37 @@
+package route
+
+import (
+	"achievement-backend/app/models"
+	"achievement-backend/app/repository"
+	"achievement-backend/app/service"
+	"achievement-backend/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func setupRoleRoutes(
+	router fiber.Router,
+	requireAuth fiber.Handler,
+	roleRepo repository.RoleRepository,
+	auditLogger *service.AuditLogger,
+) {
+	roleService := service.NewRoleService(roleRepo, auditLogger)
+	manage := []fiber.Handler{
+		requireAuth,
+		middleware.RequirePermission(models.PermissionUserManage),
+	}
+
+	roleRoutes := router.Group("/roles", manage...)
+	roleRoutes.Get("/", roleService.GetAllRoles)
+	roleRoutes.Get("/:id", roleService.GetRoleByID)
+	roleRoutes.Post("/", roleService.CreateRole)
+	roleRoutes.Delete("/:id", roleService.DeleteRole)
+	roleRoutes.Put("/:id/two-factor", roleService.UpdateTwoFactorRequirement)
+	roleRoutes.Post("/:id/permissions/:permissionId", roleService.AttachPermission)
+	roleRoutes.Delete("/:id/permissions/:permissionId", roleService.DetachPermission)
+
+	permissionRoutes := router.Group("/permissions", manage...)
+	permissionRoutes.Get("/", roleService.GetAllPermissions)
+	permissionRoutes.Post("/", roleService.CreatePermission)
+	permissionRoutes.Delete("/:id", roleService.DeletePermission)
+}
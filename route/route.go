@@ -1,9 +1,15 @@
 package route
 
 import (
+    "context"
+    "log"
+
+    "achievement-backend/config"
     "achievement-backend/database"
     "achievement-backend/app/repository"
     "achievement-backend/app/service"
+    "achievement-backend/middleware"
+    "achievement-backend/utils"
 
     "github.com/gofiber/fiber/v2"
     "github.com/gofiber/swagger"
@@ -16,21 +22,60 @@ func SetupRoutes(app *fiber.App) {
     db := database.PgDB
     
     userRepo := repository.NewUserRepository(db)
-    roleRepo := repository.NewRoleRepository(db)
+    roleRepo := repository.NewCachedRoleRepository(repository.NewRoleRepository(db))
     studentRepo := repository.NewStudentRepository(db)
     lecturerRepo := repository.NewLecturerRepository(db)
+    refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+    sessionRepo := repository.NewSessionRepository(db)
+    loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+    twoFactorRepo := repository.NewTwoFactorRepository(db)
+    passwordResetRepo := repository.NewPasswordResetRepository(db)
+    passwordHistoryRepo := repository.NewPasswordHistoryRepository(db)
+    apiKeyRepo := repository.NewAPIKeyRepository(db)
+    oidcRepo := repository.NewOIDCRepository(db)
+    auditLogRepo := repository.NewAuditLogRepository(db)
 		achievementRepo := repository.NewAchievementRepository(database.GetMongoDB())
 		achievementRefRepo := repository.NewAchievementReferenceRepository(db)
+		achievementOutboxRepo := repository.NewAchievementOutboxRepository(db)
+		reconciliationReportRepo := repository.NewReconciliationReportRepository(db)
 		reportRepo := repository.NewReportRepository()
     
-    userService := service.NewUserService(userRepo, roleRepo, studentRepo, lecturerRepo)
+    loginThrottle := service.NewLoginThrottle(loginAttemptRepo, config.LoadLoginThrottleConfig())
+    passwordPolicy := service.NewPasswordPolicy(passwordHistoryRepo, config.LoadPasswordPolicyConfig())
+
+    mailer, err := utils.NewMailer(config.LoadMailConfig())
+    if err != nil {
+        log.Fatalf("Failed to configure mailer: %v", err)
+    }
+
+    // Satu middleware auth untuk semua route: Bearer JWT atau API key
+    requireAuth := middleware.RequireAuth(userRepo, roleRepo, apiKeyRepo, sessionRepo, auditLogRepo)
+
+    auditLogger := service.NewAuditLogger(auditLogRepo)
+    userService := service.NewUserService(userRepo, roleRepo, studentRepo, lecturerRepo, sessionRepo, loginThrottle, passwordPolicy, auditLogger)
+    impersonationService := service.NewImpersonationService(userRepo, roleRepo, sessionRepo, auditLogger, config.LoadImpersonationConfig())
+
+    // Worker outbox: menyelesaikan / mengkompensasi operasi Mongo yang tertunda
+    achievementSync := service.NewAchievementSync(achievementOutboxRepo, achievementRepo, config.LoadAchievementSyncConfig())
+    go achievementSync.Run(context.Background())
+
+    // Rekonsiliasi terjadwal (RECONCILE_INTERVAL=0 untuk menonaktifkan)
+    reconciler := service.NewAchievementReconciler(achievementRepo, achievementRefRepo, reconciliationReportRepo, config.LoadReconcileConfig())
+    go reconciler.Schedule(context.Background())
+
+    setupWellKnownRoutes(app)
+
     examAPI := app.Group("/exam/api")
     
-    setupAuthRoutes(examAPI, userRepo, roleRepo,studentRepo, lecturerRepo)
-    setupUserRoutes(examAPI, userService, userRepo, roleRepo)
-		setupAchievementRoutes(examAPI,userRepo,roleRepo,studentRepo,lecturerRepo,)
-		setupStudentLecturerRoutes(examAPI,userRepo,studentRepo,lecturerRepo,achievementRepo, achievementRefRepo, roleRepo)
-		SetupReportRoutes(examAPI, userRepo, studentRepo, lecturerRepo,reportRepo, roleRepo)
+    setupAuthRoutes(examAPI, requireAuth, userRepo, roleRepo,studentRepo, lecturerRepo, refreshTokenRepo, sessionRepo, twoFactorRepo, passwordResetRepo, oidcRepo, loginThrottle, passwordPolicy, mailer, impersonationService)
+    setupUserRoutes(examAPI, userService, impersonationService, requireAuth)
+    setupRoleRoutes(examAPI, requireAuth, roleRepo, auditLogger)
+    setupAPIKeyRoutes(examAPI, requireAuth, apiKeyRepo)
+    setupAuditLogRoutes(examAPI, requireAuth, auditLogRepo)
+    setupReconciliationRoutes(examAPI, requireAuth, reconciliationReportRepo)
+		setupAchievementRoutes(examAPI,requireAuth,userRepo,roleRepo,studentRepo,lecturerRepo,auditLogger,achievementSync)
+		setupStudentLecturerRoutes(examAPI,requireAuth,userRepo,studentRepo,lecturerRepo,achievementRepo, achievementRefRepo, roleRepo, auditLogger)
+		SetupReportRoutes(examAPI, requireAuth, userRepo, studentRepo, lecturerRepo,reportRepo, roleRepo)
     
     examAPI.Get("/health", func(c *fiber.Ctx) error {
         return c.JSON(fiber.Map{
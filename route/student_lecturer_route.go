@@ -1,6 +1,7 @@
 package route
 
 import (
+	"achievement-backend/app/models"
 	"achievement-backend/middleware"
 	"achievement-backend/app/repository"
 	"achievement-backend/app/service"
@@ -10,13 +11,14 @@ import (
 
 func setupStudentLecturerRoutes(
 	router fiber.Router,
+	requireAuth fiber.Handler,
 	userRepo repository.UserRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
 	achievementRepo repository.AchievementRepository,
 	achievementRefRepo repository.AchievementReferenceRepository,
 	roleRepo repository.RoleRepository,
-
+	auditLogger *service.AuditLogger,
 ) {
 	studentLecturerService := service.NewStudentLecturerService(
 		studentRepo,
@@ -25,21 +27,22 @@ func setupStudentLecturerRoutes(
 		roleRepo,
 		achievementRepo,
 		achievementRefRepo,
+		auditLogger,
 	)
 
 	studentRoutes := router.Group("/students")
-	studentProtected := studentRoutes.Group("", middleware.RequireAuth(userRepo))
+	studentProtected := studentRoutes.Group("", requireAuth)
 	
-	studentProtected.Get("/", studentLecturerService.GetAllStudents)
-	studentProtected.Get("/:id", studentLecturerService.GetStudentByID)
+	studentProtected.Get("/", middleware.RequirePermission(models.PermissionStudentRead), studentLecturerService.GetAllStudents)
+	studentProtected.Get("/:id", middleware.RequirePermission(models.PermissionStudentRead), studentLecturerService.GetStudentByID)
 	studentProtected.Get("/:id/achievements", middleware.RequirePermission("achievement:read"), studentLecturerService.GetStudentAchievements)
 	
-	// PUT /students/:id/advisor - hanya Admin
-	studentProtected.Put("/:id/advisor", studentLecturerService.UpdateStudentAdvisor)
+	// PUT /students/:id/advisor - hanya pengelola user (Admin)
+	studentProtected.Put("/:id/advisor", middleware.RequirePermission(models.PermissionUserManage), studentLecturerService.UpdateStudentAdvisor)
 
 	lecturerRoutes := router.Group("/lecturers")
-	lecturerProtected := lecturerRoutes.Group("", middleware.RequireAuth(userRepo))
-	lecturerProtected.Get("/", studentLecturerService.GetAllLecturers)
+	lecturerProtected := lecturerRoutes.Group("", requireAuth)
+	lecturerProtected.Get("/", middleware.RequirePermission(models.PermissionLecturerRead), studentLecturerService.GetAllLecturers)
 	// GET /lecturers/:id/advisees - Admin atau Dosen Wali itu sendiri
-	lecturerProtected.Get("/:id/advisees",  studentLecturerService.GetLecturerAdvisees)
+	lecturerProtected.Get("/:id/advisees", middleware.RequirePermission(models.PermissionLecturerRead), studentLecturerService.GetLecturerAdvisees)
 }
\ No newline at end of file
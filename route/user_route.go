@@ -1,7 +1,7 @@
 package route
 
 import (
-	"achievement-backend/app/repository"
+	"achievement-backend/app/models"
 	"achievement-backend/app/service"
 	"achievement-backend/middleware"
 
@@ -11,12 +11,12 @@ import (
 func setupUserRoutes(
 	router fiber.Router, 
 	userService *service.UserService,
-	userRepo repository.UserRepository,
-	roleRepo repository.RoleRepository,
+	impersonationService *service.ImpersonationService,
+	requireAuth fiber.Handler,
 ) {
 	userRoutes := router.Group("/users")
 	
-	protectedUserRoutes := userRoutes.Group("",middleware.RequireAuth(userRepo),middleware.AdminOnly(roleRepo),)
+	protectedUserRoutes := userRoutes.Group("",requireAuth,middleware.RequirePermission(models.PermissionUserManage),)
 	userRoutes.Get("/", userService.GetAll)
 	userRoutes.Get("/:id", userService.GetByID)
 	// admin
@@ -24,4 +24,7 @@ func setupUserRoutes(
 	protectedUserRoutes.Put("/:id", userService.Update)
 	protectedUserRoutes.Delete("/:id", userService.Delete)
 	protectedUserRoutes.Put("/:id/role", userService.UpdateRole)
+	protectedUserRoutes.Delete("/:id/sessions", userService.RevokeSessions)
+	protectedUserRoutes.Post("/:id/unlock", userService.UnlockAccount)
+	protectedUserRoutes.Post("/:id/impersonate", middleware.RejectAPIKey(), impersonationService.Start)
 }
\ No newline at end of file
@@ -0,0 +1,14 @@
+package route
+
+import (
+	"achievement-backend/app/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func setupWellKnownRoutes(app *fiber.App) {
+	keyService := service.NewKeyService()
+
+	wellKnown := app.Group("/.well-known")
+	wellKnown.Get("/jwks.json", keyService.JWKS)
+}
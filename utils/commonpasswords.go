@@ -0,0 +1,27 @@
+package utils
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// Daftar password paling umum (bocoran publik + variasi lokal), satu per baris, huruf kecil
+//
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var commonPasswords = func() map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Split(commonPasswordList, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			set[line] = struct{}{}
+		}
+	}
+	return set
+}()
+
+// IsCommonPassword true jika password (tanpa membedakan huruf besar/kecil) ada di daftar umum
+func IsCommonPassword(password string) bool {
+	_, found := commonPasswords[strings.ToLower(password)]
+	return found
+}
@@ -4,79 +4,139 @@ import (
 	"time"
 
 	"achievement-backend/app/models"
-	"fmt"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte("your-secret-key-change-this") 
-
-func GenerateToken(user *models.User, permissions []string) (string, error) {
+// GenerateToken tidak menyimpan permission di token; permission di-resolve per request oleh RequireAuth.
+// sessionID dicek RequireAuth sehingga token ikut mati saat sesinya di-revoke.
+func GenerateToken(user *models.User, sessionID uuid.UUID) (string, error) {
 	claims := models.JWTClaims{
-		UserID:      user.ID.String(),
-		Email:       user.Email,
-		RoleID:      user.RoleID.String(),
-		Permissions: permissions,
+		TokenType: models.TokenTypeAccess,
+		UserID:    user.ID.String(),
+		Email:     user.Email,
+		RoleID:    user.RoleID.String(),
+		SessionID: sessionID.String(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(10 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "achievement-system",
+			Issuer:    models.TokenIssuer,
 			Subject:   user.ID.String(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return signClaims(claims)
+}
+
+// GenerateImpersonationToken access token atas nama target dengan klaim "act" berisi admin asli.
+// Tidak ada refresh token: setelah expiresAt admin harus memulai impersonation lagi.
+func GenerateImpersonationToken(target, impersonator *models.User, sessionID uuid.UUID, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	claims := models.JWTClaims{
+		TokenType: models.TokenTypeAccess,
+		UserID:    target.ID.String(),
+		Email:     target.Email,
+		RoleID:    target.RoleID.String(),
+		SessionID: sessionID.String(),
+		Act: &models.ActorClaim{
+			Subject:  impersonator.ID.String(),
+			Username: impersonator.Username,
+		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    models.TokenIssuer,
+			Subject:   target.ID.String(),
+		},
+	}
+
+	return signClaims(claims)
 }
 
-func GenerateRefreshToken(userID uuid.UUID) (string, error) {
-	claims := jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		Subject:   userID.String(),
+// RefreshTokenTTL masa berlaku refresh token, juga dipakai untuk expires_at di database
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+func GenerateRefreshToken(userID uuid.UUID, tokenID uuid.UUID, expiresAt time.Time) (string, error) {
+	claims := models.RefreshTokenClaims{
+		TokenType: models.TokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID.String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    models.TokenIssuer,
+			Subject:   userID.String(),
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return signClaims(claims)
 }
 
+// ValidateToken hanya menerima access token; refresh dan challenge token ditolak
 func ValidateToken(tokenString string) (*models.JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, keyFunc, jwt.WithIssuer(models.TokenIssuer))
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*models.JWTClaims); ok && token.Valid {
+	if claims, ok := token.Claims.(*models.JWTClaims); ok && token.Valid && claims.TokenType == models.TokenTypeAccess {
 		return claims, nil
 	}
 
-	return nil, jwt.ErrSignatureInvalid
+	return nil, jwt.ErrTokenInvalidClaims
 }
 
+// ValidateRefreshToken hanya menerima refresh token
 func ValidateRefreshToken(tokenString string) (*jwt.RegisteredClaims, error) {
-	token, err := jwt.ParseWithClaims(
-			tokenString, 
-			&jwt.RegisteredClaims{},  
-			func(token *jwt.Token) (interface{}, error) {
-					if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-							return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-					}
-					return jwtSecret, nil
-			},
-	)
-	
+	token, err := jwt.ParseWithClaims(tokenString, &models.RefreshTokenClaims{}, keyFunc, jwt.WithIssuer(models.TokenIssuer))
 	if err != nil {
-			return nil, err
+		return nil, err
 	}
-	
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
-			return claims, nil
+
+	if claims, ok := token.Claims.(*models.RefreshTokenClaims); ok && token.Valid && claims.TokenType == models.TokenTypeRefresh {
+		return &claims.RegisteredClaims, nil
 	}
-	
-	return nil, jwt.ErrSignatureInvalid
-}
\ No newline at end of file
+
+	return nil, jwt.ErrTokenInvalidClaims
+}
+
+// TwoFactorChallengeTTL masa berlaku challenge token antara langkah password dan kode 2FA
+const TwoFactorChallengeTTL = 5 * time.Minute
+
+// GenerateTwoFactorChallenge token pendek yang hanya bisa ditukar di endpoint 2FA sesuai purpose
+func GenerateTwoFactorChallenge(userID uuid.UUID, purpose string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(TwoFactorChallengeTTL)
+	claims := models.TwoFactorChallengeClaims{
+		TokenType: models.TokenTypeTwoFactorChallenge,
+		Purpose:   purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    models.TokenIssuer,
+			Subject:   userID.String(),
+		},
+	}
+
+	signed, err := signClaims(claims)
+	return signed, expiresAt, err
+}
+
+// ValidateTwoFactorChallenge menolak token dengan purpose berbeda (termasuk access/refresh token)
+func ValidateTwoFactorChallenge(tokenString, purpose string) (uuid.UUID, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &models.TwoFactorChallengeClaims{}, keyFunc, jwt.WithIssuer(models.TokenIssuer))
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	claims, ok := token.Claims.(*models.TwoFactorChallengeClaims)
+	if !ok || !token.Valid || claims.TokenType != models.TokenTypeTwoFactorChallenge || claims.Purpose != purpose {
+		return uuid.Nil, jwt.ErrTokenInvalidClaims
+	}
+
+	return uuid.Parse(claims.Subject)
+}
@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+
+	"achievement-backend/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtKey satu kunci dalam keyring. Untuk HS256 signKey == verifyKey == secret.
+type jwtKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+type jwtKeyring struct {
+	signing *jwtKey
+	// semua kunci yang diterima saat verifikasi (termasuk kunci aktif), per kid
+	verification map[string]*jwtKey
+}
+
+var keyring *jwtKeyring
+
+var ErrJWTNotConfigured = errors.New("jwt keys not initialised")
+
+// InitJWT memuat kunci aktif dan kunci verifikasi dari config. Dipanggil sekali saat startup.
+func InitJWT(cfg config.JWTConfig) error {
+	signing, err := loadSigningKey(cfg)
+	if err != nil {
+		return err
+	}
+
+	ring := &jwtKeyring{
+		signing:      signing,
+		verification: map[string]*jwtKey{signing.kid: signing},
+	}
+
+	for kid, path := range cfg.VerificationKeys {
+		if kid == signing.kid {
+			continue
+		}
+		key, err := loadVerificationKey(kid, path)
+		if err != nil {
+			return err
+		}
+		ring.verification[kid] = key
+	}
+
+	keyring = ring
+	log.Printf("JWT signing key loaded (alg=%s, kid=%s, verification keys=%d)", signing.method.Alg(), signing.kid, len(ring.verification))
+	return nil
+}
+
+func loadSigningKey(cfg config.JWTConfig) (*jwtKey, error) {
+	switch cfg.Algorithm {
+	case "HS256":
+		if cfg.Secret == "" {
+			return nil, errors.New("JWT_SECRET is required for HS256")
+		}
+		kid := cfg.KeyID
+		if kid == "" {
+			kid = "hs256"
+		}
+		secret := []byte(cfg.Secret)
+		return &jwtKey{kid: kid, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+
+	case "RS256", "EdDSA":
+		var private crypto.Signer
+		if cfg.PrivateKeyFile == "" {
+			if !cfg.EphemeralKey {
+				return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE is required for %s (set JWT_EPHEMERAL_KEY=true for development only)", cfg.Algorithm)
+			}
+			// Mode development: kunci sementara, token tidak valid lagi setelah restart
+			log.Printf("Warning: JWT_PRIVATE_KEY_FILE not set, generating ephemeral %s key", cfg.Algorithm)
+			generated, err := generateKey(cfg.Algorithm)
+			if err != nil {
+				return nil, err
+			}
+			private = generated
+		} else {
+			parsed, err := readPrivateKey(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			private = parsed
+		}
+
+		key, err := newAsymmetricKey(cfg.KeyID, private.Public())
+		if err != nil {
+			return nil, err
+		}
+		if key.method.Alg() != cfg.Algorithm {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE holds a %s key but JWT_ALGORITHM is %s", key.method.Alg(), cfg.Algorithm)
+		}
+		key.signKey = private
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q", cfg.Algorithm)
+}
+
+func loadVerificationKey(kid, path string) (*jwtKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read verification key %s: %w", kid, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("verification key %s: no PEM data", kid)
+	}
+
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		// boleh juga private key, public key diambil darinya
+		private, privErr := parsePrivateKeyBlock(block)
+		if privErr != nil {
+			return nil, fmt.Errorf("verification key %s: %w", kid, err)
+		}
+		public = private.Public()
+	}
+
+	return newAsymmetricKey(kid, public)
+}
+
+func newAsymmetricKey(kid string, public crypto.PublicKey) (*jwtKey, error) {
+	var method jwt.SigningMethod
+	var der []byte
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+		der = x509.MarshalPKCS1PublicKey(pub)
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+		der = pub
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+
+	if kid == "" {
+		sum := sha256.Sum256(der)
+		kid = hex.EncodeToString(sum[:8])
+	}
+
+	return &jwtKey{kid: kid, method: method, verifyKey: public}, nil
+}
+
+func readPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWT private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("JWT private key: no PEM data")
+	}
+	return parsePrivateKeyBlock(block)
+}
+
+func parsePrivateKeyBlock(block *pem.Block) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+func generateKey(algorithm string) (crypto.Signer, error) {
+	if algorithm == "RS256" {
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	return private, err
+}
+
+// signClaims menandatangani claims dengan kunci aktif dan mengisi header kid
+func signClaims(claims jwt.Claims) (string, error) {
+	if keyring == nil {
+		return "", ErrJWTNotConfigured
+	}
+	token := jwt.NewWithClaims(keyring.signing.method, claims)
+	token.Header["kid"] = keyring.signing.kid
+	return token.SignedString(keyring.signing.signKey)
+}
+
+// keyFunc memilih kunci verifikasi berdasarkan kid dan memastikan alg sesuai kunci tersebut
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	if keyring == nil {
+		return nil, ErrJWTNotConfigured
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := keyring.verification[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+// JWK representasi public key sesuai RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// PublicJWKs mengembalikan semua public key verifikasi. Kunci HS256 tidak pernah dipublikasikan.
+func PublicJWKs() []JWK {
+	keys := []JWK{}
+	if keyring == nil {
+		return keys
+	}
+
+	for _, key := range keyring.verification {
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "RSA",
+				Kid: key.kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "OKP",
+				Kid: key.kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Kid < keys[j].Kid })
+	return keys
+}
@@ -0,0 +1,162 @@
+package utils
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"achievement-backend/config"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var ErrLDAPInvalidCredentials = errors.New("ldap: invalid credentials")
+
+// LDAPConn operasi LDAP yang dipakai saat login. *ldap.Conn memenuhi interface ini;
+// fake in-process cukup mengimplementasikannya untuk pengujian tanpa server OpenLDAP.
+type LDAPConn interface {
+	Bind(username, password string) error
+	Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
+	Close() error
+}
+
+type LDAPDialer func(cfg config.LDAPConfig) (LDAPConn, error)
+
+// LDAPEntry atribut user dari directory yang dipetakan lewat LDAP_ATTR_*
+type LDAPEntry struct {
+	// DN hasil bind; entry hasil LDAP_USER_FILTER harus sama dengan DN ini
+	DN         string
+	Username   string
+	FullName   string
+	Email      string
+	LecturerID string
+	Department string
+}
+
+// LDAPAuthenticator memeriksa password dengan bind sebagai user itu sendiri (tanpa service account)
+type LDAPAuthenticator struct {
+	cfg  config.LDAPConfig
+	dial LDAPDialer
+}
+
+// NewLDAPAuthenticator dial nil = koneksi sungguhan lewat DialLDAP
+func NewLDAPAuthenticator(cfg config.LDAPConfig, dial LDAPDialer) *LDAPAuthenticator {
+	if dial == nil {
+		dial = DialLDAP
+	}
+	return &LDAPAuthenticator{cfg: cfg, dial: dial}
+}
+
+func DialLDAP(cfg config.LDAPConfig) (LDAPConn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if u, err := url.Parse(cfg.URL); err == nil {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	conn, err := ldap.DialURL(cfg.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: cfg.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(cfg.Timeout)
+
+	if cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// Authenticate bind dengan tiap LDAP_USER_DN_TEMPLATES sampai berhasil lalu membaca atribut user.
+// ErrLDAPInvalidCredentials jika tidak ada template yang cocok dengan password ini.
+func (a *LDAPAuthenticator) Authenticate(username, password string) (*LDAPEntry, error) {
+	// Password kosong = unauthenticated bind yang di banyak server dianggap sukses
+	if username == "" || password == "" {
+		return nil, ErrLDAPInvalidCredentials
+	}
+	if len(a.cfg.UserDNTemplates) == 0 {
+		return nil, errors.New("ldap: LDAP_USER_DN_TEMPLATES is not configured")
+	}
+
+	conn, err := a.dial(a.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ldap: %w", err)
+	}
+	defer conn.Close()
+
+	boundDN := ""
+	escaped := ldap.EscapeDN(username)
+	for _, template := range a.cfg.UserDNTemplates {
+		dn := strings.ReplaceAll(template, "%s", escaped)
+		err := conn.Bind(dn, password)
+		if err == nil {
+			boundDN = dn
+			break
+		}
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) || ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			continue
+		}
+		return nil, fmt.Errorf("ldap bind: %w", err)
+	}
+	if boundDN == "" {
+		return nil, ErrLDAPInvalidCredentials
+	}
+
+	return a.lookup(conn, boundDN, username)
+}
+
+// lookup dengan LDAP_BASE_DN mencari lewat LDAP_USER_FILTER, tanpa itu membaca entry DN hasil bind
+func (a *LDAPAuthenticator) lookup(conn LDAPConn, boundDN, username string) (*LDAPEntry, error) {
+	baseDN, scope, filter := boundDN, ldap.ScopeBaseObject, "(objectClass=*)"
+	if a.cfg.BaseDN != "" {
+		baseDN = a.cfg.BaseDN
+		scope = ldap.ScopeWholeSubtree
+		filter = strings.ReplaceAll(a.cfg.UserFilter, "%s", ldap.EscapeFilter(username))
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		baseDN, scope, ldap.NeverDerefAliases, 2, int(a.cfg.Timeout.Seconds()), false,
+		filter,
+		[]string{a.cfg.AttrFullName, a.cfg.AttrEmail, a.cfg.AttrLecturerID, a.cfg.AttrDepartment},
+		nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("ldap search: %w", err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, fmt.Errorf("ldap search: expected 1 entry for %q, got %d", username, len(result.Entries))
+	}
+
+	entry := result.Entries[0]
+	// Filter bisa menemukan entry lain dari yang di-bind; atribut entry lain tidak boleh dipakai
+	if !sameDN(entry.DN, boundDN) {
+		return nil, fmt.Errorf("ldap search: entry %q does not match bound DN %q", entry.DN, boundDN)
+	}
+	return &LDAPEntry{
+		DN:         boundDN,
+		Username:   username,
+		FullName:   entry.GetAttributeValue(a.cfg.AttrFullName),
+		Email:      entry.GetAttributeValue(a.cfg.AttrEmail),
+		LecturerID: entry.GetAttributeValue(a.cfg.AttrLecturerID),
+		Department: entry.GetAttributeValue(a.cfg.AttrDepartment),
+	}, nil
+}
+
+func sameDN(a, b string) bool {
+	parsedA, err := ldap.ParseDN(a)
+	if err != nil {
+		return false
+	}
+	parsedB, err := ldap.ParseDN(b)
+	if err != nil {
+		return false
+	}
+	return parsedA.EqualFold(parsedB)
+}
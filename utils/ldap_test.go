@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"achievement-backend/config"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// fakeLDAP directory in-process: passwords per DN, entries dikembalikan apa adanya oleh Search
+type fakeLDAP struct {
+	passwords map[string]string
+	entries   []*ldap.Entry
+	dials     int
+	binds     []string
+	searches  []*ldap.SearchRequest
+}
+
+func (f *fakeLDAP) dial(config.LDAPConfig) (LDAPConn, error) {
+	f.dials++
+	return f, nil
+}
+
+func (f *fakeLDAP) Bind(username, password string) error {
+	f.binds = append(f.binds, username)
+	expected, ok := f.passwords[username]
+	if !ok {
+		return ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))
+	}
+	if password != expected {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	return nil
+}
+
+func (f *fakeLDAP) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	f.searches = append(f.searches, req)
+	return &ldap.SearchResult{Entries: f.entries}, nil
+}
+
+func (f *fakeLDAP) Close() error {
+	return nil
+}
+
+func testLDAPConfig() config.LDAPConfig {
+	return config.LDAPConfig{
+		UserDNTemplates: []string{
+			"uid=%s,ou=staff,dc=kampus,dc=ac,dc=id",
+			"uid=%s,ou=dosen,dc=kampus,dc=ac,dc=id",
+		},
+		UserFilter:     "(uid=%s)",
+		AttrFullName:   "cn",
+		AttrEmail:      "mail",
+		AttrLecturerID: "employeeNumber",
+		AttrDepartment: "ou",
+		Timeout:        time.Second,
+	}
+}
+
+func dosenEntry(dn string) *ldap.Entry {
+	return ldap.NewEntry(dn, map[string][]string{
+		"cn":             {"Dr. Sari"},
+		"mail":           {"sari@kampus.ac.id"},
+		"employeeNumber": {"198001"},
+		"ou":             {"Informatika"},
+	})
+}
+
+func TestLDAPAuthenticateFallsThroughTemplates(t *testing.T) {
+	const dn = "uid=sari,ou=dosen,dc=kampus,dc=ac,dc=id"
+	fake := &fakeLDAP{
+		passwords: map[string]string{dn: "rahasia"},
+		entries:   []*ldap.Entry{dosenEntry(dn)},
+	}
+
+	entry, err := NewLDAPAuthenticator(testLDAPConfig(), fake.dial).Authenticate("sari", "rahasia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.binds) != 2 {
+		t.Errorf("binds = %v, want staff template then dosen template", fake.binds)
+	}
+	if entry.DN != dn || entry.Email != "sari@kampus.ac.id" || entry.FullName != "Dr. Sari" || entry.LecturerID != "198001" {
+		t.Errorf("entry = %+v", entry)
+	}
+	// Tanpa LDAP_BASE_DN atribut dibaca dari entry DN hasil bind
+	if len(fake.searches) != 1 || fake.searches[0].BaseDN != dn || fake.searches[0].Scope != ldap.ScopeBaseObject {
+		t.Errorf("search = %+v, want base-object search on bound DN", fake.searches)
+	}
+}
+
+func TestLDAPAuthenticateWrongPassword(t *testing.T) {
+	const dn = "uid=sari,ou=dosen,dc=kampus,dc=ac,dc=id"
+	fake := &fakeLDAP{passwords: map[string]string{dn: "rahasia"}}
+
+	_, err := NewLDAPAuthenticator(testLDAPConfig(), fake.dial).Authenticate("sari", "salah")
+	if !errors.Is(err, ErrLDAPInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrLDAPInvalidCredentials", err)
+	}
+	if len(fake.binds) != 2 {
+		t.Errorf("binds = %v, want every template tried", fake.binds)
+	}
+}
+
+func TestLDAPAuthenticateRejectsEmptyPassword(t *testing.T) {
+	fake := &fakeLDAP{passwords: map[string]string{"uid=sari,ou=dosen,dc=kampus,dc=ac,dc=id": ""}}
+	auth := NewLDAPAuthenticator(testLDAPConfig(), fake.dial)
+
+	for _, creds := range [][2]string{{"sari", ""}, {"", "rahasia"}} {
+		if _, err := auth.Authenticate(creds[0], creds[1]); !errors.Is(err, ErrLDAPInvalidCredentials) {
+			t.Errorf("Authenticate(%q, %q) err = %v, want ErrLDAPInvalidCredentials", creds[0], creds[1], err)
+		}
+	}
+	// Unauthenticated bind tidak pernah dikirim ke server
+	if fake.dials != 0 || len(fake.binds) != 0 {
+		t.Errorf("dials = %d, binds = %v, want none", fake.dials, fake.binds)
+	}
+}
+
+func TestLDAPAuthenticateEscapesUsername(t *testing.T) {
+	fake := &fakeLDAP{passwords: map[string]string{}}
+
+	_, err := NewLDAPAuthenticator(testLDAPConfig(), fake.dial).Authenticate("sari,ou=admin", "rahasia")
+	if !errors.Is(err, ErrLDAPInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrLDAPInvalidCredentials", err)
+	}
+	for _, dn := range fake.binds {
+		if !strings.HasPrefix(dn, `uid=sari\,ou=admin,`) {
+			t.Errorf("bind DN %q was not escaped", dn)
+		}
+	}
+}
+
+func TestLDAPAuthenticateSearchMustMatchBoundDN(t *testing.T) {
+	const dn = "uid=sari,ou=dosen,dc=kampus,dc=ac,dc=id"
+	cfg := testLDAPConfig()
+	cfg.BaseDN = "dc=kampus,dc=ac,dc=id"
+
+	t.Run("same entry", func(t *testing.T) {
+		fake := &fakeLDAP{
+			passwords: map[string]string{dn: "rahasia"},
+			entries:   []*ldap.Entry{dosenEntry("UID=sari, OU=dosen, DC=kampus, DC=ac, DC=id")},
+		}
+		entry, err := NewLDAPAuthenticator(cfg, fake.dial).Authenticate("sari", "rahasia")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.DN != dn {
+			t.Errorf("DN = %q, want bound DN %q", entry.DN, dn)
+		}
+		if fake.searches[0].Filter != "(uid=sari)" {
+			t.Errorf("filter = %q", fake.searches[0].Filter)
+		}
+	})
+
+	t.Run("different entry", func(t *testing.T) {
+		fake := &fakeLDAP{
+			passwords: map[string]string{dn: "rahasia"},
+			entries:   []*ldap.Entry{dosenEntry("uid=sari,ou=alumni,dc=kampus,dc=ac,dc=id")},
+		}
+		if _, err := NewLDAPAuthenticator(cfg, fake.dial).Authenticate("sari", "rahasia"); err == nil {
+			t.Fatal("entry other than the bound DN must be rejected")
+		}
+	})
+}
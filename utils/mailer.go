@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"achievement-backend/config"
+)
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer pengirim email. Implementasi dipilih lewat MAIL_DRIVER.
+type Mailer interface {
+	Send(msg MailMessage) error
+}
+
+func NewMailer(cfg config.MailConfig) (Mailer, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case "smtp":
+		return &smtpMailer{cfg: cfg}, nil
+	case "", "log":
+		return &logMailer{from: cfg.From}, nil
+	}
+	return nil, fmt.Errorf("unsupported MAIL_DRIVER %q", cfg.Driver)
+}
+
+// smtpMailer kirim lewat SMTP biasa; tanpa MAIL_USERNAME tidak melakukan AUTH
+// sehingga bisa langsung diarahkan ke SMTP sink lokal (MailHog, Mailpit)
+type smtpMailer struct {
+	cfg config.MailConfig
+}
+
+func (m *smtpMailer) Send(msg MailMessage) error {
+	addr := net.JoinHostPort(m.cfg.Host, m.cfg.Port)
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	return smtp.SendMail(addr, auth, m.cfg.From, []string{msg.To}, buildMail(m.cfg.From, msg))
+}
+
+// logMailer untuk development tanpa SMTP: isi email dicetak ke log
+type logMailer struct {
+	from string
+}
+
+func (m *logMailer) Send(msg MailMessage) error {
+	log.Printf("mail (log driver) from=%s to=%s subject=%q\n%s", m.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+func buildMail(from string, msg MailMessage) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,421 @@
+package utils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"achievement-backend/app/models"
+	"achievement-backend/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksRefreshInterval jarak minimum refetch JWKS saat menemukan kid yang belum dikenal
+const jwksRefreshInterval = time.Minute
+
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	TokenType   string `json:"token_type"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// OIDCClient relying party untuk authorization code + PKCE. Discovery dan JWKS
+// diambil saat pertama dipakai, jadi aplikasi tetap bisa start walau IdP belum siap.
+type OIDCClient struct {
+	cfg        config.OIDCConfig
+	httpClient *http.Client
+
+	mu            sync.Mutex
+	metadata      *oidcMetadata
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+func NewOIDCClient(cfg config.OIDCConfig) *OIDCClient {
+	return &OIDCClient{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// GeneratePKCEVerifier code verifier acak (43 karakter base64url, RFC 7636)
+func GeneratePKCEVerifier() (string, error) {
+	return GenerateOpaqueToken(32)
+}
+
+// PKCEChallenge code challenge metode S256
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL URL halaman login IdP untuk state, nonce dan code verifier ini
+func (o *OIDCClient) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	meta, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {o.cfg.ClientID},
+		"redirect_uri":          {o.cfg.RedirectURL},
+		"scope":                 {strings.Join(o.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {PKCEChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return meta.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Authenticate menukar authorization code, memverifikasi ID token (signature, issuer,
+// audience, masa berlaku, nonce) lalu melengkapi email dari userinfo jika tidak ada di ID token
+func (o *OIDCClient) Authenticate(ctx context.Context, code, codeVerifier, nonce string) (*models.OIDCProfile, error) {
+	meta, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := o.exchange(ctx, meta, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+
+	profile, err := o.verifyIDToken(ctx, meta, tokens.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.Email == "" && meta.UserinfoEndpoint != "" && tokens.AccessToken != "" {
+		if err := o.fillFromUserInfo(ctx, meta, tokens.AccessToken, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	return profile, nil
+}
+
+func (o *OIDCClient) discover(ctx context.Context) (*oidcMetadata, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.metadata != nil {
+		return o.metadata, nil
+	}
+
+	var meta oidcMetadata
+	if err := o.getJSON(ctx, o.cfg.IssuerURL+"/.well-known/openid-configuration", "", &meta); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if strings.TrimSuffix(meta.Issuer, "/") != o.cfg.IssuerURL {
+		return nil, fmt.Errorf("oidc discovery: issuer mismatch (got %q, want %q)", meta.Issuer, o.cfg.IssuerURL)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: missing authorization, token or jwks endpoint")
+	}
+
+	o.metadata = &meta
+	return o.metadata, nil
+}
+
+func (o *OIDCClient) exchange(ctx context.Context, meta *oidcMetadata, code, codeVerifier string) (*oidcTokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {o.cfg.RedirectURL},
+		"client_id":     {o.cfg.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if o.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.cfg.ClientID), url.QueryEscape(o.cfg.ClientSecret))
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc token exchange: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("oidc token exchange: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &oauthErr)
+		if oauthErr.Error != "" {
+			return nil, fmt.Errorf("oidc token exchange: %s: %s", oauthErr.Error, oauthErr.ErrorDescription)
+		}
+		return nil, fmt.Errorf("oidc token exchange: unexpected status %d", resp.StatusCode)
+	}
+
+	var tokens oidcTokenResponse
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return nil, fmt.Errorf("oidc token exchange: %w", err)
+	}
+	return &tokens, nil
+}
+
+func (o *OIDCClient) verifyIDToken(ctx context.Context, meta *oidcMetadata, rawIDToken, nonce string) (*models.OIDCProfile, error) {
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(o.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+
+	_, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return o.verificationKey(ctx, meta, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("oidc id token: %w", err)
+	}
+
+	tokenNonce, _ := claims["nonce"].(string)
+	if subtle.ConstantTimeCompare([]byte(tokenNonce), []byte(nonce)) != 1 {
+		return nil, errors.New("oidc id token: nonce mismatch")
+	}
+
+	// Token untuk beberapa audience harus diterbitkan untuk client ini (azp)
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != o.cfg.ClientID {
+			return nil, errors.New("oidc id token: authorized party mismatch")
+		}
+	}
+
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return nil, errors.New("oidc id token: missing subject")
+	}
+
+	profile := &models.OIDCProfile{
+		Issuer:  meta.Issuer,
+		Subject: subject,
+		Claims:  claims,
+	}
+	applyProfileClaims(profile, claims)
+	return profile, nil
+}
+
+func (o *OIDCClient) fillFromUserInfo(ctx context.Context, meta *oidcMetadata, accessToken string, profile *models.OIDCProfile) error {
+	info := map[string]interface{}{}
+	if err := o.getJSON(ctx, meta.UserinfoEndpoint, accessToken, &info); err != nil {
+		return fmt.Errorf("oidc userinfo: %w", err)
+	}
+
+	// userinfo wajib untuk subject yang sama (OIDC Core 5.3.2)
+	if sub, _ := info["sub"].(string); sub != profile.Subject {
+		return errors.New("oidc userinfo: subject mismatch")
+	}
+
+	for k, v := range info {
+		if _, exists := profile.Claims[k]; !exists {
+			profile.Claims[k] = v
+		}
+	}
+	applyProfileClaims(profile, profile.Claims)
+	return nil
+}
+
+func applyProfileClaims(profile *models.OIDCProfile, claims map[string]interface{}) {
+	profile.Email, _ = claims["email"].(string)
+	profile.Name, _ = claims["name"].(string)
+	profile.PreferredUsername, _ = claims["preferred_username"].(string)
+
+	// Beberapa IdP mengirim email_verified sebagai string
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		profile.EmailVerified = v
+	case string:
+		profile.EmailVerified = strings.EqualFold(v, "true")
+	}
+}
+
+func (o *OIDCClient) verificationKey(ctx context.Context, meta *oidcMetadata, kid string) (interface{}, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if key := o.lookupKey(kid); key != nil {
+		return key, nil
+	}
+
+	// kid baru (rotasi kunci di IdP): ambil ulang JWKS, dibatasi supaya token palsu tidak membanjiri IdP
+	if time.Since(o.keysFetchedAt) < jwksRefreshInterval && o.keys != nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	keys, err := o.fetchJWKS(ctx, meta.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	o.keys = keys
+	o.keysFetchedAt = time.Now()
+
+	if key := o.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey tanpa kid hanya diterima jika JWKS berisi tepat satu kunci
+func (o *OIDCClient) lookupKey(kid string) interface{} {
+	if kid != "" {
+		return o.keys[kid]
+	}
+	if len(o.keys) == 1 {
+		for _, key := range o.keys {
+			return key
+		}
+	}
+	return nil
+}
+
+func (o *OIDCClient) fetchJWKS(ctx context.Context, jwksURI string) (map[string]interface{}, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := o.getJSON(ctx, jwksURI, "", &set); err != nil {
+		return nil, fmt.Errorf("oidc jwks: %w", err)
+	}
+
+	keys := make(map[string]interface{})
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJSONWebKey(jwk)
+		if err != nil {
+			// Kunci dengan tipe yang tidak didukung dilewati, bukan menggagalkan seluruh set
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("oidc jwks: no usable signing keys")
+	}
+	return keys, nil
+}
+
+func parseJSONWebKey(jwk jsonWebKey) (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+}
+
+func (o *OIDCClient) getJSON(ctx context.Context, endpoint, bearer string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
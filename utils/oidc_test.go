@@ -0,0 +1,332 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"achievement-backend/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testClientID = "achievement"
+
+// mockIdP IdP OIDC minimal: discovery, JWKS, authorize (dicatat manual lewat authorize),
+// token endpoint yang memeriksa PKCE S256 dan userinfo
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+	// mutate mengubah claim ID token sebelum ditandatangani
+	mutate func(claims jwt.MapClaims)
+}
+
+type mockAuthorization struct {
+	challenge string
+	nonce     string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key, codes: map[string]mockAuthorization{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"userinfo_endpoint":      idp.server.URL + "/userinfo",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "k1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"sub": "user-1", "email": "budi@kampus.ac.id"})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// authorize mensimulasikan user login di IdP: code diterbitkan untuk challenge dan nonce dari URL
+func (idp *mockIdP) authorize(t *testing.T, authURL string) string {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("code_challenge_method = %q, want S256", query.Get("code_challenge_method"))
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	code := "code-" + query.Get("state")
+	idp.codes[code] = mockAuthorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	return code
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	idp.mu.Lock()
+	auth, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	mutate := idp.mutate
+	idp.mu.Unlock()
+
+	if !ok || PKCEChallenge(r.PostForm.Get("code_verifier")) != auth.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            testClientID,
+		"sub":            "user-1",
+		"email":          "budi@kampus.ac.id",
+		"email_verified": true,
+		"nonce":          auth.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+	if mutate != nil {
+		mutate(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "k1"
+	idToken, err := token.SignedString(idp.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": "at", "id_token": idToken, "token_type": "Bearer"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newTestOIDCClient(idp *mockIdP) *OIDCClient {
+	return NewOIDCClient(config.OIDCConfig{
+		IssuerURL:   idp.server.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+	})
+}
+
+// login menjalankan alur lengkap: URL login, authorize di IdP, lalu tukar code dengan verifier
+func login(t *testing.T, idp *mockIdP, client *OIDCClient, exchangeVerifier, exchangeNonce string) error {
+	t.Helper()
+	ctx := context.Background()
+	verifier, err := GeneratePKCEVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := idp.authorize(t, authURL)
+
+	if exchangeVerifier == "" {
+		exchangeVerifier = verifier
+	}
+	if exchangeNonce == "" {
+		exchangeNonce = "nonce-1"
+	}
+	_, err = client.Authenticate(ctx, code, exchangeVerifier, exchangeNonce)
+	return err
+}
+
+func TestOIDCAuthCodeURL(t *testing.T) {
+	idp := newMockIdP(t)
+	client := newTestOIDCClient(idp)
+
+	authURL, err := client.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        PKCEChallenge("verifier-1"),
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+	if strings.Contains(authURL, "verifier-1") {
+		t.Error("code verifier must not be sent to the authorization endpoint")
+	}
+}
+
+func TestOIDCAuthenticate(t *testing.T) {
+	idp := newMockIdP(t)
+	client := newTestOIDCClient(idp)
+	ctx := context.Background()
+
+	verifier, err := GeneratePKCEVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := client.Authenticate(ctx, idp.authorize(t, authURL), verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if profile.Issuer != idp.server.URL || profile.Subject != "user-1" {
+		t.Errorf("identity = %s/%s, want %s/user-1", profile.Issuer, profile.Subject, idp.server.URL)
+	}
+	if profile.Email != "budi@kampus.ac.id" || !profile.EmailVerified {
+		t.Errorf("email = %q verified=%v", profile.Email, profile.EmailVerified)
+	}
+}
+
+func TestOIDCAuthenticateRejectsWrongPKCEVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	err := login(t, idp, newTestOIDCClient(idp), "another-verifier", "")
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("err = %v, want invalid_grant from token endpoint", err)
+	}
+}
+
+func TestOIDCAuthenticateRejectsNonceMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+	err := login(t, idp, newTestOIDCClient(idp), "", "nonce-from-another-login")
+	if err == nil || !strings.Contains(err.Error(), "nonce mismatch") {
+		t.Fatalf("err = %v, want nonce mismatch", err)
+	}
+}
+
+func TestOIDCAuthenticateIDTokenClaims(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(jwt.MapClaims)
+		wantErr string
+	}{
+		{
+			name:   "multiple audiences with azp for this client",
+			mutate: func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "other"}; c["azp"] = testClientID },
+		},
+		{
+			name:    "multiple audiences without azp",
+			mutate:  func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "other"} },
+			wantErr: "authorized party mismatch",
+		},
+		{
+			name:    "multiple audiences with azp for another client",
+			mutate:  func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "other"}; c["azp"] = "other" },
+			wantErr: "authorized party mismatch",
+		},
+		{
+			name:    "audience for another client",
+			mutate:  func(c jwt.MapClaims) { c["aud"] = "other" },
+			wantErr: "aud",
+		},
+		{
+			name:    "issuer mismatch",
+			mutate:  func(c jwt.MapClaims) { c["iss"] = "https://evil.example" },
+			wantErr: "iss",
+		},
+		{
+			name:    "expired",
+			mutate:  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+			wantErr: "expired",
+		},
+		{
+			name:    "missing nonce",
+			mutate:  func(c jwt.MapClaims) { delete(c, "nonce") },
+			wantErr: "nonce mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			idp.mutate = tt.mutate
+			err := login(t, idp, newTestOIDCClient(idp), "", "")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want success", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOIDCAuthenticateFillsEmailFromUserInfo(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.mutate = func(c jwt.MapClaims) { delete(c, "email") }
+	client := newTestOIDCClient(idp)
+	ctx := context.Background()
+
+	authURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, err := client.Authenticate(ctx, idp.authorize(t, authURL), "verifier-1", "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Email != "budi@kampus.ac.id" {
+		t.Errorf("email = %q, want value from userinfo", profile.Email)
+	}
+}
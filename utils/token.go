@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"achievement-backend/app/models"
+)
+
+// GenerateOpaqueToken token acak (base64url) untuk link sekali pakai; simpan hanya HashToken-nya
+func GenerateOpaqueToken(size int) (string, error) {
+	raw := make([]byte, size)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// HashToken sha256 hex; token sudah acak penuh sehingga tidak perlu bcrypt
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey membuat key "ak_<prefix>_<secret>"; prefix (8 hex) disimpan plaintext untuk lookup
+func GenerateAPIKey() (key string, prefix string, err error) {
+	raw := make([]byte, 4)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	prefix = hex.EncodeToString(raw)
+
+	secret, err := GenerateOpaqueToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return models.APIKeyPrefix + prefix + "_" + secret, prefix, nil
+}
+
+// APIKeyLookupPrefix mengambil prefix dari key mentah
+func APIKeyLookupPrefix(key string) (string, bool) {
+	rest, found := strings.CutPrefix(key, models.APIKeyPrefix)
+	if !found || len(rest) < 10 || rest[8] != '_' {
+		return "", false
+	}
+	return rest[:8], true
+}
+
+// VerifyTokenHash membandingkan token dengan hash tersimpan dalam waktu konstan
+func VerifyTokenHash(token, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashToken(token)), []byte(hash)) == 1
+}